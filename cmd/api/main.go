@@ -2,11 +2,14 @@ package main
 
 import (
 	"log"
-	"strings"
+	"os"
+	_ "time/tzdata" // event time zones; the runtime image has no zoneinfo
 
 	"github.com/MKolega/AirsoftHubCroatia/handlers"
 	"github.com/MKolega/AirsoftHubCroatia/internal/config"
 	"github.com/MKolega/AirsoftHubCroatia/internal/db"
+	"github.com/MKolega/AirsoftHubCroatia/internal/storage"
+	"github.com/MKolega/AirsoftHubCroatia/types"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 )
@@ -14,24 +17,40 @@ import (
 func main() {
 	_ = godotenv.Load()
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "storage-gc" {
+		os.Exit(runStorageGC(os.Args[2:]))
+	}
+
 	if err := db.Init(); err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
-	if err := db.CreateEventsTable(); err != nil {
-		log.Fatalf("Failed to create events table: %v", err)
-	}
 	cfg := config.Load()
-	if strings.TrimSpace(config.GetEnv("AUTH_JWT_SECRET", "")) == "" {
-		log.Fatalf("AUTH_JWT_SECRET is required")
+	if err := handlers.ValidateAuthConfig(); err != nil {
+		log.Fatalf("Invalid auth configuration: %v", err)
 	}
 	router := gin.Default()
 
 	router.GET("/", handlers.HomeHandler)
+	router.GET("/.well-known/jwks.json", handlers.JWKSHandler)
 
-	router.GET("/events", handlers.EventsHandler)
-	router.POST("/events", handlers.LimitRequestBody(7<<20), handlers.CreateEventHandler)
-	router.PUT("/events/:id", handlers.LimitRequestBody(7<<20), handlers.UpdateEventHandler)
-	router.DELETE("/events/:id", handlers.DeleteEventHandler)
+	// Uploads stored on local disk are served by the API itself.
+	if store, err := storage.Default(); err != nil {
+		log.Printf("Upload storage unavailable: %v", err)
+	} else if local, ok := store.(*storage.LocalStorage); ok {
+		router.StaticFS("/uploads", storage.PublicFS(gin.Dir(local.Dir, false)))
+	}
+
+	// Legacy root-level event routes obey maintenance like their /api twins.
+	legacy := router.Group("/", handlers.MaintenanceGate())
+	{
+		legacy.GET("/events", handlers.EventsHandler)
+		legacy.POST("/events", handlers.RateLimit("event_create"), handlers.LimitRequestBody(7<<20), handlers.CreateEventHandler)
+		legacy.PUT("/events/:id", handlers.LimitRequestBody(7<<20), handlers.UpdateEventHandler)
+		legacy.DELETE("/events/:id", handlers.DeleteEventHandler)
+	}
 
 	//API Routes
 	api := router.Group("/api")
@@ -40,20 +59,92 @@ func main() {
 		api.Use(handlers.MaintenanceGate())
 
 		api.GET("/events", handlers.EventsHandler)
+		api.GET("/events/nearby", handlers.NearbyEventsHandler)
+		api.GET("/events/search", handlers.SearchEventsHandler)
+		api.GET("/events.ics", handlers.EventsICSHandler)
+		api.GET("/calendar/:file", handlers.UserCalendarICSHandler)
 		api.GET("/my-events", handlers.MyEventsHandler)
-		api.POST("/events", handlers.LimitRequestBody(7<<20), handlers.CreateEventHandler)
+		api.POST("/events", handlers.RateLimit("event_create"), handlers.LimitRequestBody(7<<20), handlers.CreateEventHandler)
+		api.GET("/events/:id", handlers.EventDetailHandler)
 		api.PUT("/events/:id", handlers.LimitRequestBody(7<<20), handlers.UpdateEventHandler)
 		api.DELETE("/events/:id", handlers.DeleteEventHandler)
-		api.POST("/events/:id/save", handlers.SaveEventHandler)
-		api.DELETE("/events/:id/save", handlers.UnsaveEventHandler)
+		api.POST("/events/:id/media", handlers.RateLimit("uploads"), handlers.LimitRequestBody(12<<20), handlers.UploadEventMediaHandler)
+		api.PUT("/events/:id/media", handlers.ReorderEventMediaHandler)
+		api.PUT("/events/:id/media/:mediaId", handlers.UpdateEventMediaHandler)
+		api.DELETE("/events/:id/media/:mediaId", handlers.DeleteEventMediaHandler)
+		api.POST("/uploads", handlers.RateLimit("uploads"), handlers.CreateUploadHandler)
+		api.PUT("/uploads/:id/content", handlers.PutUploadContentHandler)
+		api.POST("/uploads/:id/finalize", handlers.FinalizeUploadHandler)
+		api.POST("/events/:id/save", handlers.RateLimit("saves"), handlers.SaveEventHandler)
+		api.DELETE("/events/:id/save", handlers.RateLimit("saves"), handlers.UnsaveEventHandler)
 		api.GET("/saved-events", handlers.SavedEventsHandler)
-		api.POST("/auth/register", handlers.AuthRateLimit(), handlers.RegisterHandler)
-		api.POST("/auth/login", handlers.AuthRateLimit(), handlers.LoginHandler)
+		api.GET("/me/quota", handlers.MyQuotaHandler)
+		api.GET("/me/clubs", handlers.MyClubsHandler)
+		api.GET("/events/:id/registration", handlers.RegistrationHandler)
+		api.POST("/events/:id/registration", handlers.RegisterForEventHandler)
+		api.DELETE("/events/:id/registration", handlers.UnregisterFromEventHandler)
+		api.GET("/events/:id/roster", handlers.EventRosterHandler)
+		api.GET("/events/:id/factions", handlers.EventFactionsHandler)
+		api.POST("/events/:id/factions", handlers.CreateEventFactionHandler)
+		api.PUT("/events/:id/factions/:factionId", handlers.UpdateEventFactionHandler)
+		api.DELETE("/events/:id/factions/:factionId", handlers.DeleteEventFactionHandler)
+		api.GET("/events/:id/factions/balance", handlers.FactionBalanceHandler)
+		api.POST("/events/:id/factions/balance", handlers.ApplyFactionBalanceHandler)
+		api.GET("/fields", handlers.FieldsHandler)
+		api.POST("/fields", handlers.CreateFieldHandler)
+		api.GET("/fields/:id", handlers.FieldDetailHandler)
+		api.PUT("/fields/:id", handlers.UpdateFieldHandler)
+		api.DELETE("/fields/:id", handlers.DeleteFieldHandler)
+		api.POST("/fields/:id/photos", handlers.RateLimit("uploads"), handlers.LimitRequestBody(7<<20), handlers.UploadFieldPhotoHandler)
+		api.DELETE("/fields/:id/photos/:photo", handlers.DeleteFieldPhotoHandler)
+		api.GET("/clubs", handlers.ClubsHandler)
+		api.POST("/clubs", handlers.RateLimit("clubs"), handlers.CreateClubHandler)
+		api.GET("/clubs/:slug", handlers.ClubDetailHandler)
+		api.PUT("/clubs/:slug", handlers.UpdateClubHandler)
+		api.DELETE("/clubs/:slug", handlers.DeleteClubHandler)
+		api.POST("/clubs/:slug/logo", handlers.RateLimit("uploads"), handlers.LimitRequestBody(7<<20), handlers.UploadClubLogoHandler)
+		api.POST("/clubs/:slug/join", handlers.RateLimit("clubs"), handlers.JoinClubHandler)
+		api.POST("/clubs/:slug/invites", handlers.RateLimit("clubs"), handlers.InviteClubMemberHandler)
+		api.POST("/clubs/:slug/members/:userId/accept", handlers.AcceptClubMemberHandler)
+		api.PUT("/clubs/:slug/members/:userId", handlers.UpdateClubMemberHandler)
+		api.DELETE("/clubs/:slug/members/:userId", handlers.RemoveClubMemberHandler)
+		api.POST("/auth/register", handlers.RateLimit("auth"), handlers.RegisterHandler)
+		api.POST("/auth/login", handlers.RateLimit("auth"), handlers.LoginHandler)
+		api.POST("/auth/refresh", handlers.RateLimit("auth"), handlers.RefreshHandler)
+		api.POST("/auth/logout", handlers.LogoutHandler)
+		api.POST("/auth/verify-email", handlers.RateLimit("auth"), handlers.VerifyEmailHandler)
+		api.POST("/auth/verify-email/resend", handlers.RateLimit("auth"), handlers.ResendVerificationHandler)
+		api.POST("/auth/forgot-password", handlers.RateLimit("auth"), handlers.ForgotPasswordHandler)
+		api.POST("/auth/reset-password", handlers.RateLimit("auth"), handlers.ResetPasswordHandler)
 		api.GET("/auth/me", handlers.MeHandler)
 		api.PUT("/auth/me", handlers.UpdateMeHandler)
-		api.GET("/admin/review-events", handlers.AdminPendingReviewEventsHandler)
-		api.POST("/admin/review-events/:id/approve", handlers.AdminApproveEventHandler)
-		api.POST("/admin/review-events/:id/reject", handlers.AdminRejectEventHandler)
+		api.GET("/auth/me/calendar", handlers.MyCalendarHandler)
+		api.POST("/auth/me/calendar/reset", handlers.ResetMyCalendarHandler)
+		api.GET("/auth/sessions", handlers.SessionsHandler)
+		api.DELETE("/auth/sessions", handlers.RevokeOtherSessionsHandler)
+		api.DELETE("/auth/sessions/:id", handlers.RevokeSessionHandler)
+
+		admin := api.Group("/admin", handlers.RateLimit("admin"))
+		{
+			admin.GET("/review-events", handlers.RequirePermission(types.PermReviewEvents), handlers.AdminPendingReviewEventsHandler)
+			admin.POST("/review-events/:id/approve", handlers.RequirePermission(types.PermReviewEvents), handlers.AdminApproveEventHandler)
+			admin.POST("/review-events/:id/reject", handlers.RequirePermission(types.PermReviewEvents), handlers.AdminRejectEventHandler)
+			admin.GET("/review-media", handlers.RequirePermission(types.PermReviewEvents), handlers.AdminPendingMediaHandler)
+			admin.POST("/review-media/:mediaId/approve", handlers.RequirePermission(types.PermReviewEvents), handlers.AdminApproveMediaHandler)
+			admin.POST("/review-media/:mediaId/reject", handlers.RequirePermission(types.PermReviewEvents), handlers.AdminRejectMediaHandler)
+			admin.GET("/review-fields", handlers.RequirePermission(types.PermManageFields), handlers.AdminPendingFieldsHandler)
+			admin.POST("/review-fields/:id/approve", handlers.RequirePermission(types.PermManageFields), handlers.AdminApproveFieldHandler)
+			admin.POST("/review-fields/:id/reject", handlers.RequirePermission(types.PermManageFields), handlers.AdminRejectFieldHandler)
+			admin.GET("/events/:id/history", handlers.RequirePermission(types.PermViewEventHistory), handlers.AdminEventHistoryHandler)
+			admin.POST("/events/:id/history/:revision/restore", handlers.RequirePermission(types.PermRestoreEvents), handlers.AdminRestoreEventRevisionHandler)
+			admin.GET("/maintenance", handlers.RequirePermission(types.PermManageMaintenance), handlers.AdminMaintenanceHandler)
+			admin.PUT("/maintenance", handlers.RequirePermission(types.PermManageMaintenance), handlers.UpdateMaintenanceHandler)
+			admin.GET("/roles", handlers.RequirePermission(types.PermManageRoles), handlers.AdminRolesHandler)
+			admin.GET("/users", handlers.RequirePermission(types.PermManageRoles), handlers.AdminUsersHandler)
+			admin.POST("/users/:id/roles", handlers.RequirePermission(types.PermManageRoles), handlers.AdminGrantRoleHandler)
+			admin.DELETE("/users/:id/roles/:role", handlers.RequirePermission(types.PermManageRoles), handlers.AdminRevokeRoleHandler)
+			admin.POST("/users/:id/unlock", handlers.RequirePermission(types.PermManageUsers), handlers.AdminUnlockUserHandler)
+		}
 	}
 
 	if err := router.Run(cfg.Address); err != nil {
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/MKolega/AirsoftHubCroatia/internal/db"
+)
+
+const migrateUsage = `usage: api migrate <command>
+
+commands:
+  up              apply all pending migrations
+  down [-force]   roll back the last applied migration; -force is required
+                  to roll back the baseline, which drops all data
+  status          list migrations and whether they are applied`
+
+// runMigrate implements the `migrate` subcommand and returns the exit code.
+func runMigrate(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+	flags := flag.NewFlagSet("migrate "+args[0], flag.ContinueOnError)
+	flags.Usage = func() { fmt.Fprintln(os.Stderr, migrateUsage) }
+	force := false
+	if args[0] == "down" {
+		flags.BoolVar(&force, "force", false, "allow rolling back the baseline migration")
+	}
+	if err := flags.Parse(args[1:]); err != nil || flags.NArg() > 0 {
+		if err == nil {
+			fmt.Fprintln(os.Stderr, migrateUsage)
+		}
+		return 2
+	}
+
+	if err := db.Connect(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to connect to database: %v\n", err)
+		return 1
+	}
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		group, err := db.MigrateUp(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Migration failed: %v\n", err)
+			return 1
+		}
+		if group.IsZero() {
+			fmt.Println("No pending migrations")
+			return 0
+		}
+		fmt.Printf("Migrated to %s\n", group)
+	case "down":
+		migration, err := db.MigrateDown(ctx, force)
+		if errors.Is(err, db.ErrBaselineRollback) {
+			fmt.Fprintf(os.Stderr, "Rollback failed: %v; pass -force to run it anyway\n", err)
+			return 1
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Rollback failed: %v\n", err)
+			return 1
+		}
+		if migration == nil {
+			fmt.Println("Nothing to roll back")
+			return 0
+		}
+		fmt.Printf("Rolled back %s\n", migration)
+	case "status":
+		known, unknown, err := db.MigrationStatus(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to read migration status: %v\n", err)
+			return 1
+		}
+		for _, m := range known {
+			state := "pending"
+			if m.IsApplied() {
+				state = fmt.Sprintf("applied (group %d, %s)", m.GroupID, m.MigratedAt.Format("2006-01-02 15:04:05"))
+			}
+			fmt.Printf("%-40s %s\n", m.String(), state)
+		}
+		for _, m := range unknown {
+			fmt.Printf("%-40s applied, unknown to this binary\n", m.Name)
+		}
+		if len(unknown) > 0 {
+			fmt.Fprintln(os.Stderr, "Database schema is newer than this binary")
+			return 1
+		}
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+	return 0
+}
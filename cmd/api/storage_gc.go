@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/MKolega/AirsoftHubCroatia/internal/db"
+	"github.com/MKolega/AirsoftHubCroatia/internal/storage"
+	"github.com/MKolega/AirsoftHubCroatia/types"
+)
+
+const storageGCUsage = `usage: api storage-gc [-dry-run] [-grace 24h]
+
+Reconciles upload storage against the stored_objects table:
+  - objects no event, field or club uses (replaced, deleted or never attached) are deleted
+  - untracked objects still referenced by an event are adopted
+  - tracked objects missing from storage are marked deleted
+Objects younger than -grace are left alone so in-flight uploads survive.
+Raw direct uploads are deleted once they are older than 2h (or -grace, if shorter).`
+
+// gcPrefixes are the storage prefixes whose objects the API owns.
+var gcPrefixes = []string{storage.ThumbnailPrefix, storage.MediaPrefix, storage.FieldPhotoPrefix, storage.ClubLogoPrefix, storage.IncomingPrefix}
+
+// incomingGrace is how long raw direct uploads are kept. Finalize is refused
+// an hour after the upload started, so older files are never used.
+const incomingGrace = 2 * time.Hour
+
+type gcAction struct {
+	verb   string
+	key    string
+	reason string
+}
+
+// runStorageGC implements the `storage-gc` subcommand and returns the exit code.
+func runStorageGC(args []string) int {
+	flags := flag.NewFlagSet("storage-gc", flag.ContinueOnError)
+	flags.Usage = func() { fmt.Fprintln(os.Stderr, storageGCUsage) }
+	dryRun := flags.Bool("dry-run", false, "report what would change without deleting anything")
+	grace := flags.Duration("grace", 24*time.Hour, "minimum age of objects to delete")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if err := db.Connect(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to connect to database: %v\n", err)
+		return 1
+	}
+	store, err := storage.Default()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to open storage: %v\n", err)
+		return 1
+	}
+	ctx := context.Background()
+
+	rows, err := db.ListStoredObjects()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load stored objects: %v\n", err)
+		return 1
+	}
+	tracked := make(map[string]types.StoredObject, len(rows))
+	for _, row := range rows {
+		tracked[row.Key] = row
+	}
+	referenced, err := db.ThumbnailURLIndex()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load event thumbnails: %v\n", err)
+		return 1
+	}
+
+	cutoff := time.Now().Add(-*grace)
+	seen := make(map[string]bool)
+	var actions []gcAction
+	var toDelete []string
+	var adopt []types.StoredObject
+	attach := make(map[int][]string)
+
+	for _, prefix := range gcPrefixes {
+		prefixCutoff := cutoff
+		if prefix == storage.IncomingPrefix {
+			prefixCutoff = time.Now().Add(-min(*grace, incomingGrace))
+		}
+		err := store.List(ctx, prefix, func(o storage.Object) error {
+			seen[o.Key] = true
+			row, ok := tracked[o.Key]
+			eventID, inUse := referenced[o.URL]
+			switch {
+			case inUse:
+				if !ok {
+					adopt = append(adopt, types.StoredObject{Key: o.Key, URL: o.URL, Kind: db.ObjectKindThumbnail, SizeBytes: o.Size})
+				} else if !row.DeletedAt.IsZero() || row.EventID == eventID {
+					return nil
+				}
+				attach[eventID] = append(attach[eventID], o.Key)
+				actions = append(actions, gcAction{"adopt", o.Key, fmt.Sprintf("used by event %d", eventID)})
+			case ok && row.DeletedAt.IsZero() && (row.EventID != 0 || row.FieldID != 0 || row.ClubID != 0):
+				// Attached to an event, field or club.
+			case o.ModifiedAt.After(prefixCutoff) || (ok && row.CreatedAt.After(prefixCutoff)):
+				// Possibly an upload whose request is still running.
+			default:
+				reason := "untracked"
+				if ok && row.DeletedAt.IsZero() {
+					reason = "not attached to an event, field or club"
+				} else if ok {
+					reason = "marked deleted"
+				}
+				toDelete = append(toDelete, o.Key)
+				actions = append(actions, gcAction{"delete", o.Key, reason})
+			}
+			return nil
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to list %s: %v\n", prefix, err)
+			return 1
+		}
+	}
+
+	var missing []string
+	for _, row := range rows {
+		if row.DeletedAt.IsZero() && !seen[row.Key] && hasGCPrefix(row.Key) {
+			missing = append(missing, row.Key)
+			actions = append(actions, gcAction{"forget", row.Key, "missing from storage"})
+		}
+	}
+
+	for _, a := range actions {
+		fmt.Printf("%-6s %s (%s)\n", a.verb, a.key, a.reason)
+	}
+	fmt.Printf("%d to delete, %d to adopt, %d missing\n", len(toDelete), len(adopt), len(missing))
+	if *dryRun {
+		fmt.Println("Dry run: nothing changed")
+		return 0
+	}
+
+	failed := 0
+	deleted := make([]string, 0, len(toDelete))
+	for _, key := range toDelete {
+		if err := store.Delete(ctx, key); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to delete %s: %v\n", key, err)
+			failed++
+			continue
+		}
+		deleted = append(deleted, key)
+	}
+	if err := db.MarkStoredObjectsDeleted(append(deleted, missing...)); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to mark objects deleted: %v\n", err)
+		return 1
+	}
+	if err := db.TrackStoredObjects(adopt); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to adopt objects: %v\n", err)
+		return 1
+	}
+	for eventID, keys := range attach {
+		if err := db.AttachStoredObjects(eventID, keys); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to attach objects to event %d: %v\n", eventID, err)
+			return 1
+		}
+	}
+	fmt.Printf("Deleted %d objects\n", len(deleted))
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+func hasGCPrefix(key string) bool {
+	for _, prefix := range gcPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
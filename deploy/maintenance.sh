@@ -5,16 +5,20 @@ usage() {
   cat <<'EOF'
 Usage:
   bash deploy/maintenance.sh status
-  bash deploy/maintenance.sh on
+  bash deploy/maintenance.sh on [message]
   bash deploy/maintenance.sh off
 
 What it does:
-  - Updates MAINTENANCE_MODE in .env (true/false)
-  - Recreates only the API container so the env change applies immediately
+  - Updates the maintenance_settings row in Postgres
+  - Running API containers pick the change up within ~10 seconds (no restart)
 
 Notes:
   - This affects API routes guarded by the maintenance gate.
   - /api/maintenance remains accessible.
+  - Scheduled windows, read-only mode and route allowlists are set through
+    PUT /api/admin/maintenance.
+  - MAINTENANCE_MODE=true in .env still forces maintenance on (emergency
+    switch; needs an API restart).
 EOF
 }
 
@@ -50,22 +54,27 @@ get_env_value() {
   return 1
 }
 
-set_env_value() {
-  local key="$1"
-  local value="$2" # should already be quoted/escaped as needed
-
-  if grep -qE "^${key}=" .env; then
-    # Replace any existing occurrences.
-    # (Keeping multiple copies of the same key in .env is ambiguous anyway.)
-    sed -i -E "s|^${key}=.*$|${key}=${value}|" .env
-  else
-    printf '\n%s=%s\n' "$key" "$value" >> .env
-  fi
+psql_exec() {
+  local user db
+  user="$(get_env_value POSTGRES_USER || echo myuser)"
+  db="$(get_env_value POSTGRES_DB || echo mydb)"
+  "${compose[@]}" exec -T postgres psql -v ON_ERROR_STOP=1 -U "$user" -d "$db" "$@"
 }
 
-restart_api() {
-  echo "==> Recreating API container"
-  "${compose[@]}" up -d --force-recreate --no-deps api
+set_maintenance() {
+  local enabled="$1"
+  local message="${2:-}"
+  psql_exec -v enabled="$enabled" -v message="$message" <<'SQL'
+INSERT INTO maintenance_settings (id, enabled, message, updated_by_email, updated_at)
+VALUES (1, :'enabled'::boolean, :'message', 'deploy/maintenance.sh', now())
+ON CONFLICT (id) DO UPDATE SET
+  enabled = EXCLUDED.enabled,
+  message = CASE WHEN EXCLUDED.message = '' THEN maintenance_settings.message ELSE EXCLUDED.message END,
+  starts_at = NULL,
+  ends_at = NULL,
+  updated_by_email = EXCLUDED.updated_by_email,
+  updated_at = now();
+SQL
 }
 
 maintenance_probe() {
@@ -82,27 +91,26 @@ maintenance_probe() {
 
 case "$cmd" in
   status)
-    current="$(get_env_value MAINTENANCE_MODE || echo '')"
-    if [[ -z "$current" ]]; then
-      echo "MAINTENANCE_MODE is not set in .env (defaults to false in app)."
-    else
-      echo "MAINTENANCE_MODE=$current"
+    forced="$(get_env_value MAINTENANCE_MODE || echo '')"
+    if [[ -n "$forced" ]]; then
+      echo "MAINTENANCE_MODE=$forced (env override)"
     fi
 
+    echo "==> Stored maintenance settings"
+    psql_exec -c "SELECT enabled, starts_at, ends_at, allow_reads, message, updated_by_email, updated_at FROM maintenance_settings"
+
     echo "==> API maintenance endpoint"
     maintenance_probe
     ;;
 
   on)
     echo "==> Enabling maintenance mode"
-    set_env_value MAINTENANCE_MODE '"true"'
-    restart_api
+    set_maintenance true "${2:-}"
     ;;
 
   off)
     echo "==> Disabling maintenance mode"
-    set_env_value MAINTENANCE_MODE '"false"'
-    restart_api
+    set_maintenance false
     ;;
 
   *)
@@ -27,6 +27,9 @@ import AdminCreateEvent from './components/CreateEvent';
 import EditEvent from './components/EditEvent';
 import AuthPage from './components/AuthPage';
 import MaintenancePage from './components/MaintenancePage';
+import FieldPage from './components/FieldPage';
+import ClubPage from './components/ClubPage';
+import AccountLinkPage from './components/AccountLinkPage';
 
 type EventForSidebar = {
   id: number;
@@ -125,12 +128,79 @@ type Route =
   | { page: 'event-detail'; eventId: number }
   | { page: 'create-event' }
   | { page: 'edit-event'; eventId: number }
+  | { page: 'field'; fieldId: number }
+  | { page: 'club'; slug: string }
+  | { page: 'verify-email' }
+  | { page: 'reset-password' }
   | { page: 'auth' };
 
+type AuthState = {
+  token: string | null;
+  email: string | null;
+  refreshToken: string | null;
+  // Epoch milliseconds when the access token expires.
+  expiresAt: number | null;
+};
+
+type SessionResponse = {
+  token?: string;
+  email?: string;
+  refresh_token?: string;
+  expires_in?: number;
+};
+
+// Access tokens are refreshed this long before they expire; failed refreshes
+// are retried after authRefreshRetryMs.
+const authRefreshMarginMs = 60_000;
+const authRefreshRetryMs = 30_000;
+
+function readStoredAuth(): AuthState {
+  const expiresAt = Number(window.localStorage.getItem('authExpiresAt'));
+  return {
+    token: window.localStorage.getItem('authToken'),
+    email: window.localStorage.getItem('authEmail'),
+    refreshToken: window.localStorage.getItem('authRefreshToken'),
+    expiresAt: Number.isFinite(expiresAt) && expiresAt > 0 ? expiresAt : null,
+  };
+}
+
+function authState(
+  token: string | null,
+  email: string | null,
+  refreshToken?: string | null,
+  expiresIn?: number,
+): AuthState {
+  return {
+    token,
+    email,
+    refreshToken: token ? refreshToken ?? null : null,
+    expiresAt: token && expiresIn ? Date.now() + expiresIn * 1000 : null,
+  };
+}
+
+function storeAuth(next: AuthState) {
+  const entries: Array<[string, string | null]> = [
+    ['authToken', next.token],
+    ['authEmail', next.email],
+    ['authRefreshToken', next.refreshToken],
+    ['authExpiresAt', next.expiresAt ? String(next.expiresAt) : null],
+  ];
+  for (const [key, value] of entries) {
+    if (value) window.localStorage.setItem(key, value);
+    else window.localStorage.removeItem(key);
+  }
+}
+
 function getRouteFromPath(pathname: string): Route {
   const editMatch = pathname.match(/^\/events\/(\d+)\/edit/);
   if (editMatch) return { page: 'edit-event', eventId: Number.parseInt(editMatch[1]!, 10) };
   if (pathname.startsWith('/auth')) return { page: 'auth' };
+  if (pathname.startsWith('/verify-email')) return { page: 'verify-email' };
+  if (pathname.startsWith('/reset-password')) return { page: 'reset-password' };
+  const fieldMatch = pathname.match(/^\/fields\/(\d+)\/?$/);
+  if (fieldMatch) return { page: 'field', fieldId: Number.parseInt(fieldMatch[1]!, 10) };
+  const clubMatch = pathname.match(/^\/clubs\/([a-z0-9-]+)\/?$/);
+  if (clubMatch) return { page: 'club', slug: clubMatch[1]! };
   if (pathname.startsWith('/events/create')) return { page: 'create-event' };
   const detailMatch = pathname.match(/^\/events\/(\d+)\/?$/);
   if (detailMatch) return { page: 'event-detail', eventId: Number.parseInt(detailMatch[1]!, 10) };
@@ -147,10 +217,7 @@ function App() {
   const [route, setRoute] = useState<Route>(() => getRouteFromPath(window.location.pathname));
   const [mobileSidebarOpen, setMobileSidebarOpen] = useState(false);
 
-  const [auth, setAuth] = useState<{ token: string | null; email: string | null }>(() => ({
-    token: window.localStorage.getItem('authToken'),
-    email: window.localStorage.getItem('authEmail'),
-  }));
+  const [auth, setAuth] = useState<AuthState>(readStoredAuth);
 
   const [mapFocus, setMapFocus] = useState<{ eventId: number; token: number } | null>(null);
 
@@ -179,9 +246,10 @@ function App() {
     const controller = new AbortController();
     fetch('/api/maintenance', { signal: controller.signal, headers: { Accept: 'application/json' } })
       .then(async res => {
-        const data = (await res.json().catch(() => ({}))) as { enabled?: boolean };
+        const data = (await res.json().catch(() => ({}))) as { enabled?: boolean; read_only?: boolean };
         if (!res.ok) throw new Error(`HTTP ${res.status}`);
-        setMaintenanceEnabled(Boolean(data?.enabled));
+        // Read-only maintenance keeps browsing open; only writes are rejected.
+        setMaintenanceEnabled(Boolean(data?.enabled) && !data?.read_only);
       })
       .catch(() => {
         if (!controller.signal.aborted) setMaintenanceEnabled(false);
@@ -198,6 +266,104 @@ function App() {
     toastTimerRef.current = window.setTimeout(() => setToast(null), 5000);
   };
 
+  const updateAuth = (token: string | null, email: string | null, refreshToken?: string | null, expiresIn?: number) => {
+    const next = authState(token, email, refreshToken, expiresIn);
+    storeAuth(next);
+    setAuth(next);
+  };
+
+  // signOut forgets the session right away and revokes it on the server in
+  // the background.
+  const signOut = () => {
+    const { token, refreshToken } = auth;
+    updateAuth(null, null);
+    if (!token && !refreshToken) return;
+    fetch('/api/auth/logout', {
+      method: 'POST',
+      keepalive: true,
+      headers: {
+        'Content-Type': 'application/json',
+        ...(token ? { Authorization: `Bearer ${token}` } : {}),
+      },
+      body: JSON.stringify(refreshToken ? { refresh_token: refreshToken } : {}),
+    }).catch(() => {});
+  };
+
+  // Tabs share one session; pick up tokens another tab refreshed or cleared.
+  useEffect(() => {
+    const onStorage = (e: StorageEvent) => {
+      if (e.key === null || e.key.startsWith('auth')) setAuth(readStoredAuth());
+    };
+    window.addEventListener('storage', onStorage);
+    return () => window.removeEventListener('storage', onStorage);
+  }, []);
+
+  // Swap the access token for a new one shortly before it expires. Refresh
+  // tokens are single-use, so each refresh stores the rotated one.
+  useEffect(() => {
+    const refreshToken = auth.refreshToken;
+    const expiresAt = auth.expiresAt;
+    if (!refreshToken) return;
+    let cancelled = false;
+    let timer: number | undefined;
+
+    const refresh = async () => {
+      try {
+        // Only one tab refreshes at a time; the others pick up its result.
+        const locks = navigator.locks;
+        if (locks) await locks.request('auth-refresh', exchange);
+        else await exchange();
+      } catch {
+        // Offline or the API is down; keep the session and try again.
+        if (!cancelled) timer = window.setTimeout(refresh, authRefreshRetryMs);
+      }
+    };
+
+    const exchange = async () => {
+      if (cancelled) return;
+      const current = readStoredAuth();
+      if (current.refreshToken !== refreshToken) {
+        // Another tab already rotated (or cleared) the session.
+        setAuth(current);
+        return;
+      }
+      const res = await fetch('/api/auth/refresh', {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json', Accept: 'application/json' },
+        body: JSON.stringify({ refresh_token: refreshToken }),
+      });
+      const data: SessionResponse = await res.json().catch(() => ({}));
+      if (cancelled) return;
+      if (res.ok && data.token) {
+        const next = authState(data.token, data.email ?? null, data.refresh_token, data.expires_in);
+        storeAuth(next);
+        setAuth(next);
+        return;
+      }
+      if (res.status === 401 || res.status === 403) {
+        // Another tab may have rotated the token first.
+        const stored = readStoredAuth();
+        if (stored.refreshToken && stored.refreshToken !== refreshToken) {
+          setAuth(stored);
+          return;
+        }
+        const cleared = authState(null, null);
+        storeAuth(cleared);
+        setAuth(cleared);
+        setToast({ type: 'error', message: 'Your session has expired. Please sign in again.' });
+        return;
+      }
+      if (!cancelled) timer = window.setTimeout(refresh, authRefreshRetryMs);
+    };
+
+    const delay = expiresAt ? Math.max(expiresAt - authRefreshMarginMs - Date.now(), 0) : 0;
+    timer = window.setTimeout(refresh, delay);
+    return () => {
+      cancelled = true;
+      window.clearTimeout(timer);
+    };
+  }, [auth.refreshToken, auth.expiresAt]);
+
   useEffect(() => {
     if (!auth.token) {
       queueMicrotask(() => {
@@ -351,13 +517,7 @@ function App() {
       return (
         <ErrorBoundary>
           <MaintenancePage
-            onAuthUpdate={(token, email) => {
-              if (token) window.localStorage.setItem('authToken', token);
-              else window.localStorage.removeItem('authToken');
-              if (email) window.localStorage.setItem('authEmail', email);
-              else window.localStorage.removeItem('authEmail');
-              setAuth({ token, email });
-            }}
+            onAuthUpdate={updateAuth}
           />
         </ErrorBoundary>
       );
@@ -376,13 +536,7 @@ function App() {
       return (
         <ErrorBoundary>
           <MaintenancePage
-            onAuthUpdate={(token, email) => {
-              if (token) window.localStorage.setItem('authToken', token);
-              else window.localStorage.removeItem('authToken');
-              if (email) window.localStorage.setItem('authEmail', email);
-              else window.localStorage.removeItem('authEmail');
-              setAuth({ token, email });
-            }}
+            onAuthUpdate={updateAuth}
           />
         </ErrorBoundary>
       );
@@ -667,19 +821,23 @@ function App() {
             {route.page === 'edit-event' && meIsAdmin && (
               <EditEvent eventId={route.eventId} authToken={auth.token} onDone={() => navigate('events')} />
             )}
+            {route.page === 'field' && (
+              <FieldPage fieldId={route.fieldId} authToken={auth.token} onOpenEvent={navigateEvent} />
+            )}
+            {route.page === 'club' && (
+              <ClubPage slug={route.slug} authToken={auth.token} onOpenEvent={navigateEvent} />
+            )}
+            {(route.page === 'verify-email' || route.page === 'reset-password') && (
+              <AccountLinkPage mode={route.page} onDone={() => navigate('auth')} />
+            )}
             {route.page === 'auth' && (
               <AuthPage
                 signedIn={isSignedIn}
                 signedInEmail={auth.email}
                 authToken={auth.token}
                 onOpenEvent={navigateEvent}
-                onAuthUpdate={(token, email) => {
-                  if (token) window.localStorage.setItem('authToken', token);
-                  else window.localStorage.removeItem('authToken');
-                  if (email) window.localStorage.setItem('authEmail', email);
-                  else window.localStorage.removeItem('authEmail');
-                  setAuth({ token, email });
-                }}
+                onAuthUpdate={updateAuth}
+                onSignOut={signOut}
                 onDone={() => navigate('map')}
               />
             )}
@@ -0,0 +1,166 @@
+import React, { useEffect, useRef, useState } from 'react';
+import './AccountLinkPage.css';
+
+type AccountLinkPageProps = {
+  mode: 'verify-email' | 'reset-password';
+  onDone?: () => void;
+};
+
+function getApiErrorMessage(value: unknown, fallback: string) {
+  if (value && typeof value === 'object') {
+    const err = (value as Record<string, unknown>).error;
+    if (typeof err === 'string' && err.trim() !== '') return err;
+  }
+  return fallback;
+}
+
+// AccountLinkPage handles the links mailed by the API: /verify-email?token=
+// confirms the address right away, /reset-password?token= asks for a new
+// password. /reset-password without a token mails a fresh reset link.
+const AccountLinkPage: React.FC<AccountLinkPageProps> = ({ mode, onDone }) => {
+  const token = new URLSearchParams(window.location.search).get('token')?.trim() ?? '';
+
+  const [status, setStatus] = useState<string | null>(null);
+  const [busy, setBusy] = useState(false);
+  const [done, setDone] = useState(false);
+  const [email, setEmail] = useState('');
+  const [password, setPassword] = useState('');
+  const [confirm, setConfirm] = useState('');
+  const verifySentRef = useRef(false);
+
+  useEffect(() => {
+    if (mode !== 'verify-email' || verifySentRef.current) return;
+    verifySentRef.current = true;
+    if (!token) {
+      setStatus('❌ Verification link is missing its token');
+      return;
+    }
+
+    setBusy(true);
+    fetch('/api/auth/verify-email', {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json', Accept: 'application/json' },
+      body: JSON.stringify({ token }),
+    })
+      .then(async res => {
+        const data = await res.json().catch(() => ({}));
+        if (!res.ok) throw new Error(getApiErrorMessage(data, `HTTP ${res.status}`));
+        setDone(true);
+        setStatus('✅ Your email address is confirmed.');
+      })
+      .catch(err => {
+        setStatus(`❌ ${err instanceof Error ? err.message : 'Failed to verify email'}`);
+      })
+      .finally(() => setBusy(false));
+  }, [mode, token]);
+
+  const resetPassword = async (e: React.FormEvent) => {
+    e.preventDefault();
+    setStatus(null);
+    if (password !== confirm) {
+      setStatus('❌ Passwords do not match');
+      return;
+    }
+
+    setBusy(true);
+    try {
+      const res = await fetch('/api/auth/reset-password', {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json', Accept: 'application/json' },
+        body: JSON.stringify({ token, password }),
+      });
+      if (!res.ok) {
+        const data = await res.json().catch(() => ({}));
+        throw new Error(getApiErrorMessage(data, `HTTP ${res.status}`));
+      }
+      setDone(true);
+      setStatus('✅ Password changed. You can sign in with the new password.');
+    } catch (err) {
+      setStatus(`❌ ${err instanceof Error ? err.message : 'Failed to reset password'}`);
+    } finally {
+      setBusy(false);
+    }
+  };
+
+  const requestReset = async (e: React.FormEvent) => {
+    e.preventDefault();
+    setStatus(null);
+    setBusy(true);
+    try {
+      const res = await fetch('/api/auth/forgot-password', {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json', Accept: 'application/json' },
+        body: JSON.stringify({ email: email.trim() }),
+      });
+      const data = await res.json().catch(() => ({}));
+      if (!res.ok) throw new Error(getApiErrorMessage(data, `HTTP ${res.status}`));
+      setDone(true);
+      setStatus('✅ If an account exists for this email, a reset link is on its way.');
+    } catch (err) {
+      setStatus(`❌ ${err instanceof Error ? err.message : 'Failed to start password reset'}`);
+    } finally {
+      setBusy(false);
+    }
+  };
+
+  return (
+    <div className="page accountLink">
+      <h2 className="accountLink__title">{mode === 'verify-email' ? 'Confirm email' : 'Reset password'}</h2>
+
+      {mode === 'verify-email' && busy ? <div className="accountLink__muted">Confirming…</div> : null}
+
+      {mode === 'reset-password' && token && !done ? (
+        <form onSubmit={resetPassword} className="accountLink__form">
+          <input
+            type="password"
+            placeholder="New password"
+            value={password}
+            onChange={e => setPassword(e.target.value)}
+            autoComplete="new-password"
+            minLength={6}
+            required
+          />
+          <input
+            type="password"
+            placeholder="Repeat new password"
+            value={confirm}
+            onChange={e => setConfirm(e.target.value)}
+            autoComplete="new-password"
+            minLength={6}
+            required
+          />
+          <button type="submit" disabled={busy}>
+            {busy ? 'Saving…' : 'Set new password'}
+          </button>
+        </form>
+      ) : null}
+
+      {mode === 'reset-password' && !token && !done ? (
+        <form onSubmit={requestReset} className="accountLink__form">
+          <div className="accountLink__muted">Enter your account email and we will mail you a reset link.</div>
+          <input
+            type="email"
+            placeholder="Email"
+            value={email}
+            onChange={e => setEmail(e.target.value)}
+            autoComplete="email"
+            required
+          />
+          <button type="submit" disabled={busy}>
+            {busy ? 'Sending…' : 'Send reset link'}
+          </button>
+        </form>
+      ) : null}
+
+      {status ? <div className="accountLink__status">{status}</div> : null}
+
+      {onDone && (done || mode === 'verify-email') && !busy ? (
+        <button type="button" className="accountLink__back" onClick={onDone}>
+          Go to account
+        </button>
+      ) : null}
+    </div>
+  );
+};
+
+export default AccountLinkPage;
@@ -9,13 +9,16 @@ type AuthPageProps = {
   signedInEmail: string | null;
   authToken: string | null;
   onOpenEvent?: (id: number) => void;
-  onAuthUpdate: (token: string | null, email: string | null) => void;
+  onAuthUpdate: (token: string | null, email: string | null, refreshToken?: string | null, expiresIn?: number) => void;
+  onSignOut: () => void;
   onDone?: () => void;
 };
 
 type AuthResponse = {
   token?: string;
   email?: string;
+  refresh_token?: string;
+  expires_in?: number;
   error?: string;
 };
 
@@ -23,7 +26,10 @@ type MeResponse = {
   email?: string;
   username?: string;
   airsoft_club?: string;
+  club?: { club_slug: string; club_name: string; role: string } | null;
   is_admin?: boolean;
+  permissions?: string[];
+  email_verified?: boolean;
   error?: string;
 };
 
@@ -50,6 +56,15 @@ type ReviewEvent = {
   lng: number;
 };
 
+type ReviewMedia = {
+  id: number;
+  event_id: number;
+  event_name?: string;
+  type: string;
+  caption: string;
+  url: string;
+};
+
 type SavedEvent = {
   id: number;
   name: string;
@@ -89,16 +104,23 @@ const AuthPage: React.FC<AuthPageProps> = ({
   authToken,
   onOpenEvent,
   onAuthUpdate,
+  onSignOut,
   onDone,
 }) => {
   const [mode, setMode] = useState<Mode>('login');
   const [email, setEmail] = useState('');
   const [password, setPassword] = useState('');
   const [username, setUsername] = useState('');
-  const [airsoftClub, setAirsoftClub] = useState('');
   const [status, setStatus] = useState<string | null>(null);
 
-  const [me, setMe] = useState<{ username: string; airsoftClub: string; isAdmin: boolean } | null>(null);
+  const [me, setMe] = useState<{
+    username: string;
+    airsoftClub: string;
+    clubSlug?: string;
+    isAdmin: boolean;
+    canReviewEvents: boolean;
+    emailVerified: boolean;
+  } | null>(null);
   const [meError, setMeError] = useState<string | null>(null);
 
   const [profileUsername, setProfileUsername] = useState('');
@@ -115,6 +137,9 @@ const AuthPage: React.FC<AuthPageProps> = ({
   const [rejectReasons, setRejectReasons] = useState<Record<number, string>>({});
   const [reviewPreviewEvent, setReviewPreviewEvent] = useState<EventForModal | null>(null);
 
+  const [reviewMedia, setReviewMedia] = useState<ReviewMedia[]>([]);
+  const [reviewMediaError, setReviewMediaError] = useState<string | null>(null);
+
   const [savedEvents, setSavedEvents] = useState<SavedEvent[]>([]);
   const [savedEventsError, setSavedEventsError] = useState<string | null>(null);
 
@@ -147,7 +172,14 @@ const AuthPage: React.FC<AuthPageProps> = ({
         if (!res.ok) throw new Error(data?.error || `HTTP ${res.status}`);
         const club = (data.airsoft_club ?? '').trim() || 'No Club/Freelancer';
         const uname = (data.username ?? '').trim();
-        setMe({ username: uname, airsoftClub: club, isAdmin: Boolean(data.is_admin) });
+        setMe({
+          username: uname,
+          airsoftClub: club,
+          clubSlug: data.club?.club_slug,
+          isAdmin: Boolean(data.is_admin),
+          canReviewEvents: Boolean(data.permissions?.includes('events:review')),
+          emailVerified: data.email_verified !== false,
+        });
 		setProfileUsername(uname);
 		setProfileClub(club);
       })
@@ -279,7 +311,7 @@ const AuthPage: React.FC<AuthPageProps> = ({
   }, [signedIn, authToken]);
 
   useEffect(() => {
-    if (!signedIn || !authToken || !me?.isAdmin) {
+    if (!signedIn || !authToken || !me?.canReviewEvents) {
       setReviewEvents([]);
       setReviewEventsError(null);
       setRejectReasons({});
@@ -310,7 +342,57 @@ const AuthPage: React.FC<AuthPageProps> = ({
       });
 
     return () => controller.abort();
-  }, [signedIn, authToken, me?.isAdmin]);
+  }, [signedIn, authToken, me?.canReviewEvents]);
+
+  useEffect(() => {
+    if (!signedIn || !authToken || !me?.canReviewEvents) {
+      setReviewMedia([]);
+      setReviewMediaError(null);
+      return;
+    }
+
+    const controller = new AbortController();
+    setReviewMediaError(null);
+
+    fetch('/api/admin/review-media', {
+      signal: controller.signal,
+      headers: {
+        Accept: 'application/json',
+        Authorization: `Bearer ${authToken}`,
+      },
+    })
+      .then(async res => {
+        const data = await res.json().catch(() => []);
+        if (!res.ok) {
+          const msg = getApiErrorMessage(data);
+          throw new Error(msg ?? `HTTP ${res.status}`);
+        }
+        setReviewMedia(Array.isArray(data) ? (data as ReviewMedia[]) : []);
+      })
+      .catch(err => {
+        if (!controller.signal.aborted) setReviewMediaError(err instanceof Error ? err.message : String(err));
+      });
+
+    return () => controller.abort();
+  }, [signedIn, authToken, me?.canReviewEvents]);
+
+  const reviewMediaItem = async (mediaId: number, action: 'approve' | 'reject') => {
+    if (!authToken) return;
+    setStatus(null);
+    try {
+      const res = await fetch(`/api/admin/review-media/${mediaId}/${action}`, {
+        method: 'POST',
+        headers: { Accept: 'application/json', Authorization: `Bearer ${authToken}` },
+      });
+      if (!res.ok) {
+        const data = await res.json().catch(() => ({}));
+        throw new Error(getApiErrorMessage(data) ?? `HTTP ${res.status}`);
+      }
+      setReviewMedia(prev => prev.filter(m => m.id !== mediaId));
+    } catch (err) {
+      setStatus(`❌ ${err instanceof Error ? err.message : 'Failed to review media'}`);
+    }
+  };
 
   const approveReviewEvent = async (eventId: number) => {
     if (!authToken) return;
@@ -395,7 +477,6 @@ const AuthPage: React.FC<AuthPageProps> = ({
         },
         body: JSON.stringify({
           username: profileUsername.trim(),
-          airsoftClub: profileClub.trim(),
         }),
       });
 
@@ -406,7 +487,14 @@ const AuthPage: React.FC<AuthPageProps> = ({
 
       const club = (data.airsoft_club ?? '').trim() || 'No Club/Freelancer';
       const uname = (data.username ?? '').trim();
-      setMe(prev => ({ username: uname, airsoftClub: club, isAdmin: prev?.isAdmin ?? false }));
+      setMe(prev => ({
+        username: uname,
+        airsoftClub: club,
+        clubSlug: data.club?.club_slug,
+        isAdmin: prev?.isAdmin ?? false,
+        canReviewEvents: prev?.canReviewEvents ?? false,
+        emailVerified: prev?.emailVerified ?? true,
+      }));
       setProfileUsername(uname);
       setProfileClub(club);
       setProfileStatus('✅ Profile updated');
@@ -417,6 +505,23 @@ const AuthPage: React.FC<AuthPageProps> = ({
     }
   };
 
+  const resendVerification = async () => {
+    setProfileStatus(null);
+    if (!authToken) return;
+
+    try {
+      const res = await fetch('/api/auth/verify-email/resend', {
+        method: 'POST',
+        headers: { Accept: 'application/json', Authorization: `Bearer ${authToken}` },
+      });
+      const data = await res.json().catch(() => ({}));
+      if (!res.ok) throw new Error(getApiErrorMessage(data) || `HTTP ${res.status}`);
+      setProfileStatus('✅ Verification email sent');
+    } catch (err) {
+      setProfileStatus(`❌ ${err instanceof Error ? err.message : 'Failed to send verification email'}`);
+    }
+  };
+
   const unsaveEvent = async (eventId: number) => {
     if (!authToken) return;
     try {
@@ -459,7 +564,6 @@ const AuthPage: React.FC<AuthPageProps> = ({
             email: email.trim(),
             password,
             username: username.trim(),
-            airsoftClub: airsoftClub.trim(),
           }
         : {
             email: email.trim(),
@@ -483,7 +587,7 @@ const AuthPage: React.FC<AuthPageProps> = ({
 
       if (!data.token) throw new Error('Missing token');
 
-      onAuthUpdate(data.token, data.email ?? email.trim());
+      onAuthUpdate(data.token, data.email ?? email.trim(), data.refresh_token, data.expires_in);
 
       setStatus(mode === 'register' ? '✅ Account created and signed in!' : '✅ Signed in!');
       onDone?.();
@@ -493,7 +597,7 @@ const AuthPage: React.FC<AuthPageProps> = ({
   };
 
   const signOut = () => {
-    onAuthUpdate(null, null);
+    onSignOut();
     setStatus('Signed out.');
   };
 
@@ -512,8 +616,24 @@ const AuthPage: React.FC<AuthPageProps> = ({
                   {me?.isAdmin ? <span className="eventCategoryBadge">Admin</span> : null}
                 </div>
                 <div>
-                  <strong>Airsoft Club:</strong> {me?.airsoftClub ? me.airsoftClub : 'No Club/Freelancer'}
+                  <strong>Airsoft Club:</strong>{' '}
+                  {me?.clubSlug ? (
+                    <a href={`/clubs/${me.clubSlug}`}>{me.airsoftClub}</a>
+                  ) : me?.airsoftClub ? (
+                    me.airsoftClub
+                  ) : (
+                    'No Club/Freelancer'
+                  )}
                 </div>
+                {me && !me.emailVerified ? (
+                  <div className="authPage__error">
+                    Confirm your email address to create events, fields and clubs.{' '}
+                    <button type="button" onClick={resendVerification}>
+                      Resend link
+                    </button>
+                  </div>
+                ) : null}
+                {profileStatus && !editProfileOpen ? <div>{profileStatus}</div> : null}
                 {meError ? <div className="authPage__error">Profile error: {meError}</div> : null}
               </div>
             </div>
@@ -562,7 +682,7 @@ const AuthPage: React.FC<AuthPageProps> = ({
             )}
           </div>
 
-          {me?.isAdmin ? (
+          {me?.canReviewEvents ? (
             <div className="authPage__section">
               <div className="authPage__sectionTitle">Events Pending Review</div>
 
@@ -613,6 +733,43 @@ const AuthPage: React.FC<AuthPageProps> = ({
             </div>
           ) : null}
 
+          {me?.canReviewEvents ? (
+            <div className="authPage__section">
+              <div className="authPage__sectionTitle">Media Pending Review</div>
+
+              {reviewMediaError ? (
+                <div className="authPage__error">Error: {reviewMediaError}</div>
+              ) : reviewMedia.length === 0 ? (
+                <div className="authPage__empty">No media pending review.</div>
+              ) : (
+                <div className="authPage__reviewList">
+                  {reviewMedia.map(m => (
+                    <div key={m.id} className="eventCard authPage__reviewCard">
+                      <div className="authPage__minWidth0">
+                        <a href={m.url} target="_blank" rel="noreferrer" className="authPage__eventName">
+                          {m.type === 'pdf' ? 'PDF' : 'Image'}
+                          {m.caption ? `: ${m.caption}` : ''}
+                        </a>
+                        <div className="authPage__eventMeta">
+                          <a href={`/events/${m.event_id}`}>{m.event_name ?? `Event ${m.event_id}`}</a>
+                        </div>
+                      </div>
+
+                      <div className="authPage__reviewActions">
+                        <button type="button" onClick={() => void reviewMediaItem(m.id, 'approve')}>
+                          Approve
+                        </button>
+                        <button type="button" onClick={() => void reviewMediaItem(m.id, 'reject')}>
+                          Reject
+                        </button>
+                      </div>
+                    </div>
+                  ))}
+                </div>
+              )}
+            </div>
+          ) : null}
+
           <div className="authPage__section">
             <div className="authPage__sectionTitle">Saved Events</div>
             {savedEventsError ? (
@@ -729,12 +886,6 @@ const AuthPage: React.FC<AuthPageProps> = ({
                   autoComplete="username"
                   required
                 />
-                <input
-                  name="airsoftClub"
-                  placeholder="Airsoft Club (optional)"
-                  value={airsoftClub}
-                  onChange={e => setAirsoftClub(e.target.value)}
-                />
               </>
             ) : null}
 
@@ -756,6 +907,7 @@ const AuthPage: React.FC<AuthPageProps> = ({
               required
             />
             <button type="submit">{mode === 'register' ? 'Create account' : 'Sign in'}</button>
+            {mode === 'login' ? <a href="/reset-password">Forgot password?</a> : null}
           </form>
         </>
       )}
@@ -792,12 +944,10 @@ const AuthPage: React.FC<AuthPageProps> = ({
               </label>
 
               <label className="authPage__field">
-                <span className="authPage__fieldHint">Airsoft Club</span>
-                <input
-                  value={profileClub}
-                  onChange={e => setProfileClub(e.target.value)}
-                  placeholder="No Club/Freelancer"
-                />
+                <span className="authPage__fieldHint">
+                  Airsoft Club{me?.clubSlug ? ' (leave your club on its page to change it)' : ' (join or create a club to change it)'}
+                </span>
+                <input value={profileClub} placeholder="No Club/Freelancer" disabled />
               </label>
 
               <div className="authPage__modalActions">
@@ -0,0 +1,234 @@
+import React, { useCallback, useEffect, useState } from 'react';
+import './FieldPage.css';
+import './ClubPage.css';
+
+type ClubEvent = {
+  id: number;
+  name: string;
+  date?: string;
+};
+
+type ClubMember = {
+  user_id: number;
+  username?: string;
+  role: 'owner' | 'officer' | 'member';
+  status: 'active' | 'invited' | 'requested';
+};
+
+type ClubDetail = {
+  id: number;
+  slug: string;
+  name: string;
+  description: string;
+  logo?: string;
+  member_count: number;
+  home_field?: { id: number; name: string };
+  members: ClubMember[];
+  pending?: ClubMember[];
+  membership?: ClubMember;
+  upcoming_events: ClubEvent[];
+  past_events: ClubEvent[];
+};
+
+type ClubPageProps = {
+  slug: string;
+  authToken?: string | null;
+  onOpenEvent: (eventId: number) => void;
+};
+
+function formatDateDDMMYYYY(value: string) {
+  const d = new Date(value);
+  if (!Number.isFinite(d.getTime())) return value;
+
+  const dd = String(d.getDate()).padStart(2, '0');
+  const mm = String(d.getMonth() + 1).padStart(2, '0');
+  const yyyy = String(d.getFullYear());
+  return `${dd}/${mm}/${yyyy}`;
+}
+
+const ClubPage: React.FC<ClubPageProps> = ({ slug, authToken, onOpenEvent }) => {
+  const [club, setClub] = useState<ClubDetail | null>(null);
+  const [error, setError] = useState<string | null>(null);
+  const [actionError, setActionError] = useState<string | null>(null);
+  const [inviteName, setInviteName] = useState('');
+
+  const load = useCallback(
+    (signal?: AbortSignal) =>
+      fetch(`/api/clubs/${encodeURIComponent(slug)}`, {
+        headers: authToken ? { Authorization: `Bearer ${authToken}` } : undefined,
+        signal,
+      })
+        .then(async res => {
+          const data = await res.json().catch(() => null);
+          if (!res.ok) throw new Error(data?.error ?? `Failed to load club (${res.status})`);
+          setClub(data as ClubDetail);
+          setError(null);
+        })
+        .catch((err: unknown) => {
+          if (signal?.aborted) return;
+          setError(err instanceof Error ? err.message : 'Failed to load club');
+        }),
+    [slug, authToken],
+  );
+
+  useEffect(() => {
+    const controller = new AbortController();
+    void load(controller.signal);
+    return () => controller.abort();
+  }, [load]);
+
+  const act = async (method: string, path: string, body?: unknown) => {
+    if (!authToken) return;
+    setActionError(null);
+    const res = await fetch(`/api/clubs/${encodeURIComponent(slug)}${path}`, {
+      method,
+      headers: {
+        Authorization: `Bearer ${authToken}`,
+        ...(body ? { 'Content-Type': 'application/json' } : {}),
+      },
+      body: body ? JSON.stringify(body) : undefined,
+    });
+    if (!res.ok) {
+      const data = await res.json().catch(() => null);
+      setActionError(data?.error ?? `Request failed (${res.status})`);
+      return;
+    }
+    await load();
+  };
+
+  if (error) return <div className="fieldPage fieldPage__error">{error}</div>;
+  if (!club) return <div className="fieldPage">Loading…</div>;
+
+  const membership = club.membership;
+  const renderEvents = (events: ClubEvent[], empty: string) =>
+    events.length === 0 ? (
+      <div className="fieldPage__muted">{empty}</div>
+    ) : (
+      <ul className="fieldPage__events">
+        {events.map(ev => (
+          <li key={ev.id}>
+            <button type="button" className="fieldPage__eventLink" onClick={() => onOpenEvent(ev.id)}>
+              {ev.name}
+            </button>
+            {ev.date ? <span className="fieldPage__muted"> • {formatDateDDMMYYYY(ev.date)}</span> : null}
+          </li>
+        ))}
+      </ul>
+    );
+
+  return (
+    <div className="fieldPage">
+      <div className="clubPage__header">
+        {club.logo ? <img className="clubPage__logo" src={club.logo} alt={`${club.name} logo`} /> : null}
+        <div>
+          <h2 className="fieldPage__title">{club.name}</h2>
+          <div className="fieldPage__meta">
+            <span>
+              {club.member_count} {club.member_count === 1 ? 'member' : 'members'}
+            </span>
+            {club.home_field ? (
+              <span>
+                Home field: <a href={`/fields/${club.home_field.id}`}>{club.home_field.name}</a>
+              </span>
+            ) : null}
+          </div>
+        </div>
+      </div>
+
+      {authToken ? (
+        <div className="clubPage__actions">
+          {!membership ? (
+            <button type="button" onClick={() => void act('POST', '/join')}>
+              Ask to join
+            </button>
+          ) : membership.status === 'invited' ? (
+            <>
+              <button type="button" onClick={() => void act('POST', `/members/${membership.user_id}/accept`)}>
+                Accept invite
+              </button>
+              <button type="button" onClick={() => void act('DELETE', `/members/${membership.user_id}`)}>
+                Decline
+              </button>
+            </>
+          ) : membership.status === 'requested' ? (
+            <>
+              <span className="fieldPage__muted">Join request sent.</span>
+              <button type="button" onClick={() => void act('DELETE', `/members/${membership.user_id}`)}>
+                Withdraw
+              </button>
+            </>
+          ) : (
+            <button type="button" onClick={() => void act('DELETE', `/members/${membership.user_id}`)}>
+              Leave club
+            </button>
+          )}
+          {actionError ? <span className="fieldPage__error">{actionError}</span> : null}
+        </div>
+      ) : null}
+
+      {club.description ? (
+        <section className="fieldPage__block">
+          <div className="fieldPage__text">{club.description}</div>
+        </section>
+      ) : null}
+
+      <section className="fieldPage__block">
+        <div className="fieldPage__blockTitle">Members</div>
+        <ul className="fieldPage__events">
+          {club.members.map(m => (
+            <li key={m.user_id}>
+              {m.username || `Player #${m.user_id}`}
+              {m.role !== 'member' ? <span className="eventCategoryBadge clubPage__role">{m.role}</span> : null}
+            </li>
+          ))}
+        </ul>
+      </section>
+
+      {club.pending ? (
+        <section className="fieldPage__block">
+          <div className="fieldPage__blockTitle">Invites and join requests</div>
+          {club.pending.length === 0 ? <div className="fieldPage__muted">Nothing pending.</div> : null}
+          <ul className="fieldPage__events">
+            {club.pending.map(m => (
+              <li key={m.user_id}>
+                {m.username || `Player #${m.user_id}`}{' '}
+                <span className="fieldPage__muted">({m.status === 'invited' ? 'invited' : 'wants to join'})</span>{' '}
+                {m.status === 'requested' ? (
+                  <button type="button" onClick={() => void act('POST', `/members/${m.user_id}/accept`)}>
+                    Accept
+                  </button>
+                ) : null}
+                <button type="button" onClick={() => void act('DELETE', `/members/${m.user_id}`)}>
+                  {m.status === 'requested' ? 'Decline' : 'Cancel'}
+                </button>
+              </li>
+            ))}
+          </ul>
+          <form
+            className="clubPage__invite"
+            onSubmit={e => {
+              e.preventDefault();
+              const username = inviteName.trim();
+              if (!username) return;
+              void act('POST', '/invites', { username }).then(() => setInviteName(''));
+            }}
+          >
+            <input value={inviteName} onChange={e => setInviteName(e.target.value)} placeholder="Username" />
+            <button type="submit">Invite</button>
+          </form>
+        </section>
+      ) : null}
+
+      <section className="fieldPage__block">
+        <div className="fieldPage__blockTitle">Upcoming events</div>
+        {renderEvents(club.upcoming_events, 'No upcoming events.')}
+      </section>
+      <section className="fieldPage__block">
+        <div className="fieldPage__blockTitle">Past events</div>
+        {renderEvents(club.past_events, 'No past events yet.')}
+      </section>
+    </div>
+  );
+};
+
+export default ClubPage;
@@ -32,9 +32,23 @@ type EventInput = {
   lng: number | null;
   category: string;
   facebookLink: string;
+  maxPlayers: string;
+  fieldId: string;
+  clubId: string;
   thumbnailFile: File | null;
 };
 
+type FieldOption = {
+  id: number;
+  name: string;
+};
+
+type MyClub = {
+  club_id: number;
+  club_name: string;
+  role: string;
+};
+
 type StringField = Exclude<keyof EventInput, 'lat' | 'lng' | 'thumbnailFile'>;
 
 // Fix default marker icon
@@ -46,6 +60,15 @@ L.Icon.Default.mergeOptions({
   shadowUrl: 'https://unpkg.com/leaflet@1.9.4/dist/images/marker-shadow.png',
 });
 
+type EventQuota = {
+  tier: string;
+  limit?: number;
+  used: number;
+  remaining: number;
+  unlimited: boolean;
+  resets_at: string;
+};
+
 type AdminCreateEventProps = {
   authToken?: string | null;
   onDone?: () => void;
@@ -63,10 +86,71 @@ const AdminCreateEvent: React.FC<AdminCreateEventProps> = ({ authToken, onDone,
     lng: null,
     category: 'Skirmish',
     facebookLink: '',
+    maxPlayers: '',
+    fieldId: '',
+    clubId: '',
     thumbnailFile: null,
   });
 
+  const [fields, setFields] = useState<FieldOption[]>([]);
+  useEffect(() => {
+    let cancelled = false;
+    fetch('/api/fields', { headers: { Accept: 'application/json' } })
+      .then(res => (res.ok ? res.json() : []))
+      .then((data: unknown) => {
+        if (!cancelled) setFields(Array.isArray(data) ? (data as FieldOption[]) : []);
+      })
+      .catch(() => {
+        if (!cancelled) setFields([]);
+      });
+    return () => {
+      cancelled = true;
+    };
+  }, []);
+
+  const [myClub, setMyClub] = useState<MyClub | null>(null);
+  useEffect(() => {
+    if (!authToken) {
+      setMyClub(null);
+      return;
+    }
+    let cancelled = false;
+    fetch('/api/auth/me', { headers: { Accept: 'application/json', Authorization: `Bearer ${authToken}` } })
+      .then(res => (res.ok ? res.json() : null))
+      .then((data: { club?: MyClub | null } | null) => {
+        if (!cancelled) setMyClub(data?.club ?? null);
+      })
+      .catch(() => {
+        if (!cancelled) setMyClub(null);
+      });
+    return () => {
+      cancelled = true;
+    };
+  }, [authToken]);
+  // Club owners and officers may publish events on behalf of their club.
+  const publishClub = myClub && (myClub.role === 'owner' || myClub.role === 'officer') ? myClub : null;
+
   const [status, setStatus] = useState<string | null>(null);
+  const [quota, setQuota] = useState<EventQuota | null>(null);
+
+  useEffect(() => {
+    if (!authToken) {
+      setQuota(null);
+      return;
+    }
+    let cancelled = false;
+    fetch('/api/me/quota', { headers: { Authorization: `Bearer ${authToken}` } })
+      .then((res) => (res.ok ? res.json() : null))
+      .then((data: EventQuota | null) => {
+        if (!cancelled) setQuota(data);
+      })
+      .catch(() => {
+        if (!cancelled) setQuota(null);
+      });
+    return () => {
+      cancelled = true;
+    };
+  }, [authToken, status]);
   const [loadingGeo, setLoadingGeo] = useState(false);
 
   const [localPreviewUrl, setLocalPreviewUrl] = useState<string | null>(null);
@@ -137,7 +221,7 @@ const AdminCreateEvent: React.FC<AdminCreateEventProps> = ({ authToken, onDone,
     e.preventDefault();
     setStatus(null);
 
-    if (form.lat == null || form.lng == null) {
+    if (form.fieldId === '' && (form.lat == null || form.lng == null)) {
       setStatus('❌ Please set a location on the map');
       return;
     }
@@ -149,10 +233,15 @@ const AdminCreateEvent: React.FC<AdminCreateEventProps> = ({ authToken, onDone,
       body.set('detailedDescription', form.detailedDescription);
       body.set('location', form.location);
       body.set('date', form.date);
-      body.set('lat', String(form.lat));
-      body.set('lng', String(form.lng));
+      if (form.lat != null && form.lng != null) {
+        body.set('lat', String(form.lat));
+        body.set('lng', String(form.lng));
+      }
       body.set('category', form.category);
       body.set('facebookLink', form.facebookLink);
+      body.set('maxPlayers', form.maxPlayers);
+      body.set('fieldId', form.fieldId);
+      body.set('clubId', form.clubId);
       if (form.thumbnailFile) body.set('thumbnail', form.thumbnailFile);
 
       const res = await fetch('/api/events', {
@@ -195,6 +284,9 @@ const AdminCreateEvent: React.FC<AdminCreateEventProps> = ({ authToken, onDone,
         lng: null,
         category: 'Skirmish',
         facebookLink: '',
+        maxPlayers: '',
+        fieldId: '',
+        clubId: '',
         thumbnailFile: null,
       });
 
@@ -216,7 +308,12 @@ const AdminCreateEvent: React.FC<AdminCreateEventProps> = ({ authToken, onDone,
       <div className="warningBox createEvent__warning">
         <strong>All created events will be sent for verification before publishing.</strong>
         <div className="createEvent__warningText">
-          Verification process can take up to 24 hours. Users are limited to creating 2 Events per day.
+          Verification process can take up to 24 hours.{' '}
+          {quota && !quota.unlimited
+            ? `You can submit ${quota.remaining} more of ${quota.limit} events today.`
+            : !quota
+              ? 'The number of events you can submit per day is limited.'
+              : null}
         </div>
       </div>
 
@@ -256,7 +353,31 @@ const AdminCreateEvent: React.FC<AdminCreateEventProps> = ({ authToken, onDone,
           />
         </label>
 
-        <input name="location" placeholder="Town / Address" value={form.location} onChange={onChange} required />
+        {publishClub ? (
+          <label className="createEvent__field">
+            <span>Organizer</span>
+            <select name="clubId" value={form.clubId} onChange={onChange}>
+              <option value="">Just me</option>
+              <option value={String(publishClub.club_id)}>{publishClub.club_name}</option>
+            </select>
+          </label>
+        ) : null}
+
+        <label className="createEvent__field">
+          <span>Field (optional, supplies the location)</span>
+          <select name="fieldId" value={form.fieldId} onChange={onChange}>
+            <option value="">No listed field</option>
+            {fields.map(f => (
+              <option key={f.id} value={String(f.id)}>
+                {f.name}
+              </option>
+            ))}
+          </select>
+        </label>
+
+        {form.fieldId === '' ? (
+          <input name="location" placeholder="Town / Address" value={form.location} onChange={onChange} required />
+        ) : null}
 
         <label className="createEvent__field">
           <span>Thumbnail (optional)</span>
@@ -277,28 +398,37 @@ const AdminCreateEvent: React.FC<AdminCreateEventProps> = ({ authToken, onDone,
           />
         </label>
 
-        <button type="button" onClick={locate} disabled={loadingGeo}>
-          {loadingGeo ? 'Locating…' : '📍 Locate on Map'}
-        </button>
-
-        <div className="createEvent__mapWrap">
-          <div className="createEvent__mapTip">
-            Tip: click on the map to place/move the event marker.
-          </div>
-          <MapContainer center={mapCenter} zoom={mapZoom} className="createEvent__map" scrollWheelZoom={false}>
-            <MapRecenter center={mapCenter} zoom={mapZoom} />
-            <MapClickSetter
-              onPick={(lat, lng) => {
-                setForm(prev => ({ ...prev, lat, lng }));
-              }}
-            />
-            <TileLayer url="https://{s}.tile.openstreetmap.org/{z}/{x}/{y}.png" />
-            {form.lat != null && form.lng != null ? <Marker position={[form.lat, form.lng]} /> : null}
-          </MapContainer>
-        </div>
+        {form.fieldId === '' ? (
+          <>
+            <button type="button" onClick={locate} disabled={loadingGeo}>
+              {loadingGeo ? 'Locating…' : '📍 Locate on Map'}
+            </button>
+
+            <div className="createEvent__mapWrap">
+              <div className="createEvent__mapTip">
+                Tip: click on the map to place/move the event marker.
+              </div>
+              <MapContainer center={mapCenter} zoom={mapZoom} className="createEvent__map" scrollWheelZoom={false}>
+                <MapRecenter center={mapCenter} zoom={mapZoom} />
+                <MapClickSetter
+                  onPick={(lat, lng) => {
+                    setForm(prev => ({ ...prev, lat, lng }));
+                  }}
+                />
+                <TileLayer url="https://{s}.tile.openstreetmap.org/{z}/{x}/{y}.png" />
+                {form.lat != null && form.lng != null ? <Marker position={[form.lat, form.lng]} /> : null}
+              </MapContainer>
+            </div>
+          </>
+        ) : null}
 
         <input name="date" type="date" value={form.date} onChange={onChange} />
-        <input name="facebookLink" placeholder="Facebook Event Link" value={form.facebookLink} onChange={onChange} />
+        <input type="url" name="facebookLink" placeholder="Facebook Event Link" value={form.facebookLink} onChange={onChange} />
+
+        <label className="createEvent__field">
+          <span>Max players (optional, leave empty for no limit)</span>
+          <input name="maxPlayers" type="number" min={0} step={1} value={form.maxPlayers} onChange={onChange} />
+        </label>
 
         <button type="submit">Create Event</button>
       </form>
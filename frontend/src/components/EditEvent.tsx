@@ -33,6 +33,9 @@ type EventApi = {
   lng: number;
   category?: string;
   facebook_link?: string;
+  max_players?: number;
+  field_id?: number;
+  club_id?: number;
   thumbnail?: string;
 };
 
@@ -51,10 +54,24 @@ type EventForm = {
   lng: number | null;
   category: string;
   facebookLink: string;
+  maxPlayers: string;
+  fieldId: string;
+  clubId: string;
   thumbnailFile: File | null;
   currentThumbnail?: string;
 };
 
+type FieldOption = {
+  id: number;
+  name: string;
+};
+
+type MyClub = {
+  club_id: number;
+  club_name: string;
+  role: string;
+};
+
 type StringField = Exclude<keyof EventForm, 'lat' | 'lng' | 'thumbnailFile' | 'currentThumbnail'>;
 
 // Fix default marker icon
@@ -86,10 +103,55 @@ const EditEvent: React.FC<EditEventProps> = ({ eventId, authToken, onDone }) =>
     lng: null,
     category: 'Skirmish',
     facebookLink: '',
+    maxPlayers: '',
+    fieldId: '',
+    clubId: '',
     thumbnailFile: null,
     currentThumbnail: undefined,
   });
 
+  const [fields, setFields] = useState<FieldOption[]>([]);
+  useEffect(() => {
+    let cancelled = false;
+    fetch('/api/fields', { headers: { Accept: 'application/json' } })
+      .then(res => (res.ok ? res.json() : []))
+      .then((data: unknown) => {
+        if (!cancelled) setFields(Array.isArray(data) ? (data as FieldOption[]) : []);
+      })
+      .catch(() => {
+        if (!cancelled) setFields([]);
+      });
+    return () => {
+      cancelled = true;
+    };
+  }, []);
+
+  const [myClub, setMyClub] = useState<MyClub | null>(null);
+  useEffect(() => {
+    if (!authToken) {
+      setMyClub(null);
+      return;
+    }
+    let cancelled = false;
+    fetch('/api/auth/me', { headers: { Accept: 'application/json', Authorization: `Bearer ${authToken}` } })
+      .then(res => (res.ok ? res.json() : null))
+      .then((data: { club?: MyClub | null } | null) => {
+        if (!cancelled) setMyClub(data?.club ?? null);
+      })
+      .catch(() => {
+        if (!cancelled) setMyClub(null);
+      });
+    return () => {
+      cancelled = true;
+    };
+  }, [authToken]);
+  // Club owners and officers may publish events on behalf of their club.
+  const publishClub = myClub && (myClub.role === 'owner' || myClub.role === 'officer') ? myClub : null;
+
+  // The club the event was loaded with; it stays selectable even for
+  // editors outside that club.
+  const [loadedClubId, setLoadedClubId] = useState('');
+
   const [status, setStatus] = useState<string | null>(null);
   const [loadingGeo, setLoadingGeo] = useState(false);
   const [deleting, setDeleting] = useState(false);
@@ -146,9 +208,13 @@ const EditEvent: React.FC<EditEventProps> = ({ eventId, authToken, onDone }) =>
           lng: Number.isFinite(found.lng) ? found.lng : null,
           category,
           facebookLink: found.facebook_link ?? '',
+          maxPlayers: found.max_players ? String(found.max_players) : '',
+          fieldId: found.field_id ? String(found.field_id) : '',
+          clubId: found.club_id ? String(found.club_id) : '',
           thumbnailFile: null,
           currentThumbnail: found.thumbnail,
         });
+        setLoadedClubId(found.club_id ? String(found.club_id) : '');
       })
       .catch(err => {
         if (!controller.signal.aborted) setLoadError(err instanceof Error ? err.message : String(err));
@@ -217,7 +283,7 @@ const EditEvent: React.FC<EditEventProps> = ({ eventId, authToken, onDone }) =>
     e.preventDefault();
     setStatus(null);
 
-    if (form.lat == null || form.lng == null) {
+    if (form.fieldId === '' && (form.lat == null || form.lng == null)) {
       setStatus('❌ Please set a location on the map');
       return;
     }
@@ -229,10 +295,15 @@ const EditEvent: React.FC<EditEventProps> = ({ eventId, authToken, onDone }) =>
       body.set('detailedDescription', form.detailedDescription);
       body.set('location', form.location);
       body.set('date', form.date);
-      body.set('lat', String(form.lat));
-      body.set('lng', String(form.lng));
+      if (form.lat != null && form.lng != null) {
+        body.set('lat', String(form.lat));
+        body.set('lng', String(form.lng));
+      }
       body.set('category', form.category);
       body.set('facebookLink', form.facebookLink);
+      body.set('maxPlayers', form.maxPlayers);
+      body.set('fieldId', form.fieldId);
+      body.set('clubId', form.clubId);
       if (form.thumbnailFile) body.set('thumbnail', form.thumbnailFile);
 
       const res = await fetch(`/api/events/${eventId}`, {
@@ -397,7 +468,34 @@ const EditEvent: React.FC<EditEventProps> = ({ eventId, authToken, onDone }) =>
           />
         </label>
 
-        <input name="location" placeholder="Town / Address" value={form.location} onChange={onChange} required />
+        {publishClub || loadedClubId !== '' ? (
+          <label className="editEvent__field">
+            <span>Organizer</span>
+            <select name="clubId" value={form.clubId} onChange={onChange}>
+              <option value="">Just the creator</option>
+              {loadedClubId !== '' && (!publishClub || String(publishClub.club_id) !== loadedClubId) ? (
+                <option value={loadedClubId}>Current club</option>
+              ) : null}
+              {publishClub ? <option value={String(publishClub.club_id)}>{publishClub.club_name}</option> : null}
+            </select>
+          </label>
+        ) : null}
+
+        <label className="editEvent__field">
+          <span>Field (optional, supplies the location)</span>
+          <select name="fieldId" value={form.fieldId} onChange={onChange}>
+            <option value="">No listed field</option>
+            {fields.map(f => (
+              <option key={f.id} value={String(f.id)}>
+                {f.name}
+              </option>
+            ))}
+          </select>
+        </label>
+
+        {form.fieldId === '' ? (
+          <input name="location" placeholder="Town / Address" value={form.location} onChange={onChange} required />
+        ) : null}
 
         <label className="editEvent__field">
           <span>Thumbnail (optional)</span>
@@ -418,28 +516,37 @@ const EditEvent: React.FC<EditEventProps> = ({ eventId, authToken, onDone }) =>
           />
         </label>
 
-        <button type="button" onClick={locate} disabled={loadingGeo}>
-          {loadingGeo ? 'Locating…' : '📍 Locate on Map'}
-        </button>
-
-        <div className="editEvent__mapWrap">
-          <div className="editEvent__mapTip">
-            Tip: click on the map to place/move the event marker.
-          </div>
-          <MapContainer center={mapCenter} zoom={mapZoom} className="editEvent__map" scrollWheelZoom={false}>
-            <MapRecenter center={mapCenter} zoom={mapZoom} />
-            <MapClickSetter
-              onPick={(lat, lng) => {
-                setForm(prev => ({ ...prev, lat, lng }));
-              }}
-            />
-            <TileLayer url="https://{s}.tile.openstreetmap.org/{z}/{x}/{y}.png" />
-            {form.lat != null && form.lng != null ? <Marker position={[form.lat, form.lng]} /> : null}
-          </MapContainer>
-        </div>
+        {form.fieldId === '' ? (
+          <>
+            <button type="button" onClick={locate} disabled={loadingGeo}>
+              {loadingGeo ? 'Locating…' : '📍 Locate on Map'}
+            </button>
+
+            <div className="editEvent__mapWrap">
+              <div className="editEvent__mapTip">
+                Tip: click on the map to place/move the event marker.
+              </div>
+              <MapContainer center={mapCenter} zoom={mapZoom} className="editEvent__map" scrollWheelZoom={false}>
+                <MapRecenter center={mapCenter} zoom={mapZoom} />
+                <MapClickSetter
+                  onPick={(lat, lng) => {
+                    setForm(prev => ({ ...prev, lat, lng }));
+                  }}
+                />
+                <TileLayer url="https://{s}.tile.openstreetmap.org/{z}/{x}/{y}.png" />
+                {form.lat != null && form.lng != null ? <Marker position={[form.lat, form.lng]} /> : null}
+              </MapContainer>
+            </div>
+          </>
+        ) : null}
 
         <input name="date" type="date" value={form.date} onChange={onChange} />
-        <input name="facebookLink" placeholder="Facebook Event Link" value={form.facebookLink} onChange={onChange} />
+        <input type="url" name="facebookLink" placeholder="Facebook Event Link" value={form.facebookLink} onChange={onChange} />
+
+        <label className="editEvent__field">
+          <span>Max players (optional, leave empty for no limit)</span>
+          <input name="maxPlayers" type="number" min={0} step={1} value={form.maxPlayers} onChange={onChange} />
+        </label>
 
         <button type="submit">Save Changes</button>
       </form>
@@ -1,4 +1,4 @@
-import React, { useMemo } from 'react';
+import React, { useEffect, useMemo, useState } from 'react';
 import { MapContainer, Marker, Popup, TileLayer } from 'react-leaflet';
 import './EventDetailsModal.css';
 
@@ -20,12 +20,33 @@ export type EventForModal = {
   description?: string;
   detailed_description?: string;
   thumbnail?: string;
+  thumbnail_variants?: Record<string, string>;
+  thumbnail_blurhash?: string;
+  thumbnail_color?: string;
   category?: string;
   facebook_link?: string;
   lat: number;
   lng: number;
 };
 
+export type EventMedia = {
+  id: number;
+  type: 'image' | 'pdf';
+  caption: string;
+  url: string;
+};
+
+export type EventField = {
+  id: number;
+  name: string;
+  address?: string;
+};
+
+export type EventClub = {
+  slug: string;
+  name: string;
+};
+
 type EventDetailsModalProps = {
   event: EventForModal;
   onClose: () => void;
@@ -33,6 +54,33 @@ type EventDetailsModalProps = {
 
 const EventDetailsModal: React.FC<EventDetailsModalProps> = ({ event, onClose }) => {
   const mapCenter = useMemo<[number, number]>(() => [event.lat, event.lng], [event.lat, event.lng]);
+  const [media, setMedia] = useState<EventMedia[]>([]);
+  const [field, setField] = useState<EventField | null>(null);
+  const [club, setClub] = useState<EventClub | null>(null);
+
+  useEffect(() => {
+    let cancelled = false;
+    fetch(`/api/events/${event.id}`)
+      .then(res => (res.ok ? res.json() : null))
+      .then((data: { media?: EventMedia[]; field?: EventField; club?: EventClub } | null) => {
+        if (cancelled) return;
+        setMedia(data?.media ?? []);
+        setField(data?.field ?? null);
+        setClub(data?.club ?? null);
+      })
+      .catch(() => {
+        if (cancelled) return;
+        setMedia([]);
+        setField(null);
+        setClub(null);
+      });
+    return () => {
+      cancelled = true;
+    };
+  }, [event.id]);
+
+  const images = media.filter(m => m.type === 'image');
+  const attachments = media.filter(m => m.type === 'pdf');
 
   return (
     <div
@@ -62,6 +110,17 @@ const EventDetailsModal: React.FC<EventDetailsModalProps> = ({ event, onClose })
 
         <div className="eventDetailsModal__content">
           <div className="eventDetailsModal__left">
+            {club ? (
+              <div className="eventDetailsModal__block">
+                Organized by <a href={`/clubs/${club.slug}`}>{club.name}</a>
+              </div>
+            ) : null}
+            {field ? (
+              <div className="eventDetailsModal__block">
+                Field: <a href={`/fields/${field.id}`}>{field.name}</a>
+                {field.address ? ` • ${field.address}` : null}
+              </div>
+            ) : null}
             {event.facebook_link ? (
               <div className="eventDetailsModal__block">
                 Event page:{' '}
@@ -76,6 +135,32 @@ const EventDetailsModal: React.FC<EventDetailsModalProps> = ({ event, onClose })
                 <div className="eventDetailsModal__detailsText">{event.detailed_description}</div>
               </div>
             ) : null}
+            {images.length > 0 ? (
+              <div className="eventDetailsModal__block">
+                <div className="eventDetailsModal__blockTitle">Gallery</div>
+                <div className="eventDetailsModal__gallery">
+                  {images.map(m => (
+                    <a key={m.id} href={m.url} target="_blank" rel="noreferrer" title={m.caption || undefined}>
+                      <img src={m.url} alt={m.caption || `${event.name} photo`} loading="lazy" />
+                    </a>
+                  ))}
+                </div>
+              </div>
+            ) : null}
+            {attachments.length > 0 ? (
+              <div className="eventDetailsModal__block">
+                <div className="eventDetailsModal__blockTitle">Attachments</div>
+                <ul className="eventDetailsModal__attachments">
+                  {attachments.map(m => (
+                    <li key={m.id}>
+                      <a href={m.url} target="_blank" rel="noreferrer">
+                        {m.caption || 'Attachment (PDF)'}
+                      </a>
+                    </li>
+                  ))}
+                </ul>
+              </div>
+            ) : null}
           </div>
 
           <div className="eventDetailsModal__right">
@@ -85,6 +170,7 @@ const EventDetailsModal: React.FC<EventDetailsModalProps> = ({ event, onClose })
                   src={event.thumbnail}
                   alt={`${event.name} thumbnail`}
                   className="eventDetailsModal__mediaImg"
+                  style={event.thumbnail_color ? { backgroundColor: event.thumbnail_color } : undefined}
                   loading="lazy"
                 />
               ) : (
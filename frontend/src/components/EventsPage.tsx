@@ -80,6 +80,9 @@ interface Event {
   description?: string;
   detailed_description?: string;
   thumbnail?: string;
+  thumbnail_variants?: Record<string, string>;
+  thumbnail_blurhash?: string;
+  thumbnail_color?: string;
   category?: string;
   facebook_link?: string;
   lat: number;
@@ -304,9 +307,10 @@ const EventsPage: React.FC<EventsPageProps> = ({
       <div className="eventsPage__cardInner">
         {e.thumbnail ? (
           <img
-            src={e.thumbnail}
+            src={e.thumbnail_variants?.card ?? e.thumbnail}
             alt={`${e.name} thumbnail`}
             className="eventsPage__thumb"
+            style={e.thumbnail_color ? { backgroundColor: e.thumbnail_color } : undefined}
             loading="lazy"
           />
         ) : (
@@ -0,0 +1,133 @@
+import React, { useEffect, useState } from 'react';
+import './FieldPage.css';
+
+type FieldEvent = {
+  id: number;
+  name: string;
+  date?: string;
+  category?: string;
+};
+
+type FieldDetail = {
+  id: number;
+  name: string;
+  address: string;
+  county: string;
+  surface_type: string;
+  owner_club: string;
+  photos: string[];
+  rules: string;
+  parking_notes: string;
+  lat: number;
+  lng: number;
+  upcoming_events: FieldEvent[];
+  past_events: FieldEvent[];
+};
+
+type FieldPageProps = {
+  fieldId: number;
+  authToken?: string | null;
+  onOpenEvent: (eventId: number) => void;
+};
+
+function formatDateDDMMYYYY(value: string) {
+  const d = new Date(value);
+  if (!Number.isFinite(d.getTime())) return value;
+
+  const dd = String(d.getDate()).padStart(2, '0');
+  const mm = String(d.getMonth() + 1).padStart(2, '0');
+  const yyyy = String(d.getFullYear());
+  return `${dd}/${mm}/${yyyy}`;
+}
+
+const FieldPage: React.FC<FieldPageProps> = ({ fieldId, authToken, onOpenEvent }) => {
+  const [field, setField] = useState<FieldDetail | null>(null);
+  const [error, setError] = useState<string | null>(null);
+
+  useEffect(() => {
+    let cancelled = false;
+    setError(null);
+    fetch(`/api/fields/${fieldId}`, {
+      headers: authToken ? { Authorization: `Bearer ${authToken}` } : undefined,
+    })
+      .then(async res => {
+        const data = await res.json().catch(() => null);
+        if (!res.ok) throw new Error(data?.error ?? `Failed to load field (${res.status})`);
+        return data as FieldDetail;
+      })
+      .then(data => {
+        if (!cancelled) setField(data);
+      })
+      .catch((err: unknown) => {
+        if (!cancelled) setError(err instanceof Error ? err.message : 'Failed to load field');
+      });
+    return () => {
+      cancelled = true;
+    };
+  }, [fieldId, authToken]);
+
+  if (error) return <div className="fieldPage fieldPage__error">{error}</div>;
+  if (!field) return <div className="fieldPage">Loading…</div>;
+
+  const renderEvents = (events: FieldEvent[], empty: string) =>
+    events.length === 0 ? (
+      <div className="fieldPage__muted">{empty}</div>
+    ) : (
+      <ul className="fieldPage__events">
+        {events.map(ev => (
+          <li key={ev.id}>
+            <button type="button" className="fieldPage__eventLink" onClick={() => onOpenEvent(ev.id)}>
+              {ev.name}
+            </button>
+            {ev.date ? <span className="fieldPage__muted"> • {formatDateDDMMYYYY(ev.date)}</span> : null}
+          </li>
+        ))}
+      </ul>
+    );
+
+  return (
+    <div className="fieldPage">
+      <h2 className="fieldPage__title">{field.name}</h2>
+      <div className="fieldPage__meta">
+        {field.address ? <span>{field.address}</span> : null}
+        {field.county ? <span>{field.county}</span> : null}
+        {field.surface_type ? <span className="eventCategoryBadge">{field.surface_type}</span> : null}
+        {field.owner_club ? <span>Run by {field.owner_club}</span> : null}
+      </div>
+
+      {field.photos.length > 0 ? (
+        <div className="fieldPage__gallery">
+          {field.photos.map(url => (
+            <a key={url} href={url} target="_blank" rel="noreferrer">
+              <img src={url} alt={`${field.name} photo`} loading="lazy" />
+            </a>
+          ))}
+        </div>
+      ) : null}
+
+      {field.rules ? (
+        <section className="fieldPage__block">
+          <div className="fieldPage__blockTitle">Rules</div>
+          <div className="fieldPage__text">{field.rules}</div>
+        </section>
+      ) : null}
+      {field.parking_notes ? (
+        <section className="fieldPage__block">
+          <div className="fieldPage__blockTitle">Parking</div>
+          <div className="fieldPage__text">{field.parking_notes}</div>
+        </section>
+      ) : null}
+
+      <section className="fieldPage__block">
+        <div className="fieldPage__blockTitle">Upcoming events</div>
+        {renderEvents(field.upcoming_events, 'No upcoming events.')}
+      </section>
+      <section className="fieldPage__block">
+        <div className="fieldPage__blockTitle">Past events</div>
+        {renderEvents(field.past_events, 'No past events yet.')}
+      </section>
+    </div>
+  );
+};
+
+export default FieldPage;
@@ -3,12 +3,14 @@ import { Alert, Box, Button, Paper, Stack, TextField, Typography } from '@mui/ma
 import { alpha, useTheme } from '@mui/material/styles';
 
 type MaintenancePageProps = {
-  onAuthUpdate: (token: string | null, email: string | null) => void;
+  onAuthUpdate: (token: string | null, email: string | null, refreshToken?: string | null, expiresIn?: number) => void;
 };
 
 type LoginResponse = {
   token?: string;
   email?: string;
+  refresh_token?: string;
+  expires_in?: number;
   error?: string;
 };
 
@@ -74,11 +76,17 @@ const MaintenancePage: React.FC<MaintenancePageProps> = ({ onAuthUpdate }) => {
       }
 
       if (!meData.is_admin && !meData.is_maintenance_user) {
+        // Close the session the login just opened.
+        fetch('/api/auth/logout', {
+          method: 'POST',
+          headers: { 'Content-Type': 'application/json', Authorization: `Bearer ${token}` },
+          body: JSON.stringify(data.refresh_token ? { refresh_token: data.refresh_token } : {}),
+        }).catch(() => {});
         onAuthUpdate(null, null);
         throw new Error('Maintenance access required');
       }
 
-      onAuthUpdate(token, serverEmail || nextEmail);
+      onAuthUpdate(token, serverEmail || nextEmail, data.refresh_token, data.expires_in);
       setPassword('');
       setStatus(null);
     } catch (err) {
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/MKolega/AirsoftHubCroatia/internal/config"
+	"github.com/MKolega/AirsoftHubCroatia/internal/db"
+	"github.com/MKolega/AirsoftHubCroatia/internal/mailer"
+	"github.com/MKolega/AirsoftHubCroatia/types"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	verifyEmailTokenTTL   = 48 * time.Hour
+	resetPasswordTokenTTL = time.Hour
+	mailSendTimeout       = 30 * time.Second
+)
+
+// validEmail reports whether email is a bare address like "a@b.hr".
+func validEmail(email string) bool {
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email {
+		return false
+	}
+	at := strings.LastIndex(email, "@")
+	return at > 0 && strings.Contains(email[at+1:], ".")
+}
+
+// appLink builds a link to a frontend page. APP_BASE_URL wins over the
+// request host so mails never point at an internal address.
+func appLink(c *gin.Context, path string, token string) string {
+	base := strings.TrimRight(strings.TrimSpace(config.GetEnv("APP_BASE_URL", "")), "/")
+	if base == "" {
+		base = requestBaseURL(c)
+	}
+	return base + path + "?token=" + url.QueryEscape(token)
+}
+
+// sendMailAsync sends in the background so response timing does not depend
+// on the mail server (or on whether an account exists).
+func sendMailAsync(msg mailer.Message) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), mailSendTimeout)
+		defer cancel()
+		if err := mailer.Send(ctx, msg); err != nil {
+			log.Printf("mailer: failed to send %q to %s: %v", msg.Subject, msg.To, err)
+		}
+	}()
+}
+
+func sendVerificationEmail(c *gin.Context, user *types.User) error {
+	token, err := db.CreateUserToken(user.ID, db.TokenVerifyEmail, verifyEmailTokenTTL)
+	if err != nil {
+		return err
+	}
+	sendMailAsync(mailer.Message{
+		To:      user.Email,
+		Subject: "Confirm your Airsoft Hub Croatia email",
+		Text: "Hi " + user.Username + ",\n\n" +
+			"Please confirm your email address by opening the link below:\n\n" +
+			appLink(c, "/verify-email", token) + "\n\n" +
+			"The link expires in 48 hours. If you did not create an account, you can ignore this email.\n",
+	})
+	return nil
+}
+
+func VerifyEmailHandler(c *gin.Context) {
+	var req types.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Token) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Token is required"})
+		return
+	}
+
+	if err := db.VerifyEmailWithToken(strings.TrimSpace(req.Token)); err != nil {
+		if errors.Is(err, db.ErrInvalidToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Verification link is invalid or expired"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify email"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"email_verified": true})
+}
+
+// ResendVerificationHandler mails a fresh verification link to the caller.
+func ResendVerificationHandler(c *gin.Context) {
+	email, ok := emailFromAuthHeader(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	user, err := db.GetUserByEmail(email)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if !user.EmailVerifiedAt.IsZero() {
+		c.JSON(http.StatusOK, gin.H{"email_verified": true})
+		return
+	}
+
+	if err := sendVerificationEmail(c, user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send verification email"})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"email_verified": false})
+}
+
+// ForgotPasswordHandler mails a reset link. The response is the same whether
+// or not the account exists.
+func ForgotPasswordHandler(c *gin.Context) {
+	var req types.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	email := normalizeEmail(req.Email)
+	if !validEmail(email) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid email"})
+		return
+	}
+
+	if user, err := db.GetUserByEmail(email); err == nil {
+		token, err := db.CreateUserToken(user.ID, db.TokenResetPassword, resetPasswordTokenTTL)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start password reset"})
+			return
+		}
+		sendMailAsync(mailer.Message{
+			To:      user.Email,
+			Subject: "Reset your Airsoft Hub Croatia password",
+			Text: "Hi " + user.Username + ",\n\n" +
+				"Someone asked to reset the password for this account. Open the link below to choose a new one:\n\n" +
+				appLink(c, "/reset-password", token) + "\n\n" +
+				"The link expires in 1 hour and works once. If you did not ask for this, you can ignore this email.\n",
+		})
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "If an account exists for this email, a reset link has been sent"})
+}
+
+func ResetPasswordHandler(c *gin.Context) {
+	var req types.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	token := strings.TrimSpace(req.Token)
+	password := strings.TrimSpace(req.Password)
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Token is required"})
+		return
+	}
+	if len(password) < 6 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Password must be at least 6 characters"})
+		return
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+	if err := db.ResetPasswordWithToken(token, string(hash)); err != nil {
+		if errors.Is(err, db.ErrInvalidToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Reset link is invalid or expired"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -1,8 +1,12 @@
 package handlers
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
+	"log"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -20,28 +24,22 @@ func normalizeEmail(raw string) string {
 	return strings.ToLower(strings.TrimSpace(raw))
 }
 
-func emailInCSVList(email string, rawList string) bool {
-	if email == "" {
-		return false
-	}
-	for _, p := range strings.Split(strings.TrimSpace(rawList), ",") {
-		if normalizeEmail(p) == email {
-			return true
-		}
-	}
-	return false
-}
-
+// authClaims carries the session id (sid) next to the token id (jti) so a
+// whole session can be revoked at once.
 type authClaims struct {
-	Email string `json:"email"`
+	Email     string `json:"email"`
+	SessionID string `json:"sid"`
 	jwt.RegisteredClaims
 }
 
 type jwtSettings struct {
-	secret   []byte
-	ttl      time.Duration
-	issuer   string
-	audience string
+	keys       map[string]*jwtKey
+	kids       []string
+	active     *jwtKey
+	ttl        time.Duration
+	refreshTTL time.Duration
+	issuer     string
+	audience   string
 }
 
 var (
@@ -52,11 +50,16 @@ var (
 
 func getJWTSettings() (jwtSettings, error) {
 	jwtSettingsOnce.Do(func() {
-		secret := strings.TrimSpace(config.GetEnv("AUTH_JWT_SECRET", ""))
-		if secret == "" {
-			jwtSettingsErr = errors.New("AUTH_JWT_SECRET is required")
+		keys, active, err := loadJWTKeys()
+		if err != nil {
+			jwtSettingsErr = err
 			return
 		}
+		kids := make([]string, 0, len(keys))
+		for kid := range keys {
+			kids = append(kids, kid)
+		}
+		sort.Strings(kids)
 
 		ttlMinutes := strings.TrimSpace(config.GetEnv("AUTH_JWT_TTL_MINUTES", "120"))
 		mins, err := strconv.Atoi(ttlMinutes)
@@ -65,26 +68,42 @@ func getJWTSettings() (jwtSettings, error) {
 			return
 		}
 
+		refreshDays := strings.TrimSpace(config.GetEnv("AUTH_REFRESH_TTL_DAYS", "30"))
+		days, err := strconv.Atoi(refreshDays)
+		if err != nil || days <= 0 {
+			jwtSettingsErr = errors.New("AUTH_REFRESH_TTL_DAYS must be a positive integer")
+			return
+		}
+
 		jwtSettingsVal = jwtSettings{
-			secret:   []byte(secret),
-			ttl:      time.Duration(mins) * time.Minute,
-			issuer:   strings.TrimSpace(config.GetEnv("AUTH_JWT_ISSUER", "")),
-			audience: strings.TrimSpace(config.GetEnv("AUTH_JWT_AUDIENCE", "")),
+			keys:       keys,
+			kids:       kids,
+			active:     active,
+			ttl:        time.Duration(mins) * time.Minute,
+			refreshTTL: time.Duration(days) * 24 * time.Hour,
+			issuer:     strings.TrimSpace(config.GetEnv("AUTH_JWT_ISSUER", "")),
+			audience:   strings.TrimSpace(config.GetEnv("AUTH_JWT_AUDIENCE", "")),
 		}
 	})
 	return jwtSettingsVal, jwtSettingsErr
 }
 
-func issueToken(email string) (string, error) {
+func issueToken(email string, sessionID string) (string, error) {
 	s, err := getJWTSettings()
 	if err != nil {
 		return "", err
 	}
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", err
+	}
 
 	now := time.Now()
 	claims := authClaims{
-		Email: email,
+		Email:     email,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        hex.EncodeToString(jti),
 			Subject:   email,
 			Issuer:    s.issuer,
 			IssuedAt:  jwt.NewNumericDate(now),
@@ -95,32 +114,77 @@ func issueToken(email string) (string, error) {
 		claims.Audience = jwt.ClaimStrings{s.audience}
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(s.secret)
+	token := jwt.NewWithClaims(s.active.method, claims)
+	token.Header["kid"] = s.active.kid
+	return token.SignedString(s.active.signKey)
 }
 
-func emailFromAuthHeader(c *gin.Context) (string, bool) {
+// startSession opens a new session for user and returns its first access and
+// refresh tokens.
+func startSession(c *gin.Context, user *types.User) (*types.AuthResponse, error) {
+	s, err := getJWTSettings()
+	if err != nil {
+		return nil, err
+	}
+	session, refresh, err := db.CreateSession(user.ID, c.GetHeader("User-Agent"), c.ClientIP(), s.refreshTTL)
+	if err != nil {
+		return nil, err
+	}
+	tok, err := issueToken(user.Email, session.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &types.AuthResponse{
+		Token:        tok,
+		Email:        user.Email,
+		RefreshToken: refresh,
+		ExpiresIn:    int(s.ttl.Seconds()),
+	}, nil
+}
+
+// authClaimsKey holds the checked claims (nil when the token was rejected) on
+// the request context.
+const authClaimsKey = "authClaims"
+
+// claimsFromAuthHeader validates the bearer token and rejects tokens whose
+// jti or session has been revoked. Middleware and handlers both ask for the
+// claims, so the result is kept on the request and the revocation lookup runs
+// once per request.
+func claimsFromAuthHeader(c *gin.Context) (*authClaims, bool) {
+	if v, ok := c.Get(authClaimsKey); ok {
+		claims, _ := v.(*authClaims)
+		return claims, claims != nil
+	}
+	claims, ok := parseAuthHeader(c)
+	if !ok {
+		claims = nil
+	}
+	c.Set(authClaimsKey, claims)
+	return claims, ok
+}
+
+func parseAuthHeader(c *gin.Context) (*authClaims, bool) {
 	authz := strings.TrimSpace(c.GetHeader("Authorization"))
 	if authz == "" {
-		return "", false
+		return nil, false
 	}
 	const prefix = "Bearer "
 	if !strings.HasPrefix(authz, prefix) {
-		return "", false
+		return nil, false
 	}
 	tok := strings.TrimSpace(strings.TrimPrefix(authz, prefix))
 	if tok == "" {
-		return "", false
+		return nil, false
 	}
 
 	s, err := getJWTSettings()
 	if err != nil {
-		return "", false
+		return nil, false
 	}
 
 	claims := new(authClaims)
 	options := []jwt.ParserOption{
-		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithValidMethods(jwtAlgorithms(s.keys)),
 		jwt.WithExpirationRequired(),
 	}
 	if s.issuer != "" {
@@ -130,10 +194,24 @@ func emailFromAuthHeader(c *gin.Context) (string, bool) {
 		options = append(options, jwt.WithAudience(s.audience))
 	}
 
-	parsed, err := jwt.ParseWithClaims(tok, claims, func(token *jwt.Token) (interface{}, error) {
-		return s.secret, nil
-	}, options...)
+	parsed, err := jwt.ParseWithClaims(tok, claims, jwtKeyFunc(s.keys), options...)
 	if err != nil || parsed == nil || !parsed.Valid {
+		return nil, false
+	}
+	if claims.ID == "" || claims.SessionID == "" {
+		return nil, false
+	}
+
+	revoked, err := db.AccessTokenRevoked(claims.ID, claims.SessionID)
+	if err != nil || revoked {
+		return nil, false
+	}
+	return claims, true
+}
+
+func emailFromAuthHeader(c *gin.Context) (string, bool) {
+	claims, ok := claimsFromAuthHeader(c)
+	if !ok {
 		return "", false
 	}
 
@@ -165,8 +243,7 @@ func RegisterHandler(c *gin.Context) {
 	email := normalizeEmail(req.Email)
 	password := strings.TrimSpace(req.Password)
 	username := strings.TrimSpace(req.Username)
-	club := strings.TrimSpace(req.AirsoftClub)
-	if email == "" || !strings.Contains(email, "@") {
+	if !validEmail(email) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid email"})
 		return
 	}
@@ -178,12 +255,6 @@ func RegisterHandler(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Username is required"})
 		return
 	}
-	if club == "" {
-		club = "No Club/Freelancer"
-	}
-
-	isAdmin := emailInCSVList(email, config.GetEnv("ADMIN_EMAILS", ""))
-	isMaintenanceUser := emailInCSVList(email, config.GetEnv("MAINTENANCE_USER_EMAILS", ""))
 
 	if _, err := db.GetUserByEmail(email); err == nil {
 		c.JSON(http.StatusConflict, gin.H{"error": "Email already in use"})
@@ -196,26 +267,31 @@ func RegisterHandler(c *gin.Context) {
 		return
 	}
 
+	// New players join a club from its page; until then they are freelancers.
 	user := &types.User{
-		Email:             email,
-		Username:          username,
-		AirsoftClub:       club,
-		IsAdmin:           isAdmin,
-		IsMaintenanceUser: isMaintenanceUser,
-		PasswordHash:      string(hash),
+		Email:        email,
+		Username:     username,
+		AirsoftClub:  types.FreelancerClub,
+		PasswordHash: string(hash),
 	}
 	if err := db.InsertUser(user); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create account"})
 		return
 	}
+	if err := db.BootstrapAdminOnRegister(user); err != nil {
+		log.Printf("register: admin bootstrap for %s: %v", email, err)
+	}
+	if err := sendVerificationEmail(c, user); err != nil {
+		log.Printf("register: verification email for %s: %v", email, err)
+	}
 
-	tok, err := issueToken(email)
+	resp, err := startSession(c, user)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign in"})
 		return
 	}
 
-	c.JSON(http.StatusCreated, types.AuthResponse{Token: tok, Email: email})
+	c.JSON(http.StatusCreated, resp)
 }
 
 func MeHandler(c *gin.Context) {
@@ -231,14 +307,23 @@ func MeHandler(c *gin.Context) {
 	}
 	club := strings.TrimSpace(user.AirsoftClub)
 	if club == "" {
-		club = "No Club/Freelancer"
+		club = types.FreelancerClub
+	}
+	membership, err := db.GetUserClub(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch club"})
+		return
 	}
 	c.JSON(http.StatusOK, gin.H{
 		"email":               user.Email,
 		"username":            user.Username,
 		"airsoft_club":        club,
-		"is_admin":            user.IsAdmin,
-		"is_maintenance_user": user.IsMaintenanceUser,
+		"club":                membership,
+		"is_admin":            user.IsAdmin(),
+		"is_maintenance_user": user.HasRole(types.RoleMaintenance),
+		"roles":               user.Roles,
+		"permissions":         user.Permissions(),
+		"email_verified":      !user.EmailVerifiedAt.IsZero(),
 	})
 }
 
@@ -262,14 +347,10 @@ func UpdateMeHandler(c *gin.Context) {
 	}
 
 	username := strings.TrimSpace(req.Username)
-	club := strings.TrimSpace(req.AirsoftClub)
 	if username == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Username is required"})
 		return
 	}
-	if club == "" {
-		club = "No Club/Freelancer"
-	}
 
 	taken, err := db.UsernameTaken(username, user.ID)
 	if err != nil {
@@ -281,6 +362,18 @@ func UpdateMeHandler(c *gin.Context) {
 		return
 	}
 
+	// The club shown is the one the user belongs to; it changes by joining
+	// or leaving a club.
+	membership, err := db.GetUserClub(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update profile"})
+		return
+	}
+	club := types.FreelancerClub
+	if membership != nil {
+		club = membership.ClubName
+	}
+
 	if err := db.UpdateUserProfile(user.ID, username, club); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update profile"})
 		return
@@ -290,8 +383,12 @@ func UpdateMeHandler(c *gin.Context) {
 		"email":               user.Email,
 		"username":            username,
 		"airsoft_club":        club,
-		"is_admin":            user.IsAdmin,
-		"is_maintenance_user": user.IsMaintenanceUser,
+		"club":                membership,
+		"is_admin":            user.IsAdmin(),
+		"is_maintenance_user": user.HasRole(types.RoleMaintenance),
+		"roles":               user.Roles,
+		"permissions":         user.Permissions(),
+		"email_verified":      !user.EmailVerifiedAt.IsZero(),
 	})
 }
 
@@ -309,27 +406,48 @@ func LoginHandler(c *gin.Context) {
 		return
 	}
 
+	// Throttling is keyed by email whether or not the account exists, so a
+	// locked response says nothing about registration. The attempt counts as
+	// failed until the password checks out.
+	policy := loginPolicy()
+	blockedUntil, locks, err := db.ReserveLoginAttempt(email, policy)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign in"})
+		return
+	}
+	if !blockedUntil.IsZero() {
+		loginThrottled(c, blockedUntil)
+		return
+	}
+
 	user, err := db.GetUserByEmail(email)
 	if err != nil {
+		compareDummyPassword(password)
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
 		return
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		if locks {
+			notifyLoginLockout(policy, email, user.Username)
+		}
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
 		return
 	}
+	if err := db.ClearLoginFailures(email, policy.Window); err != nil {
+		log.Printf("login: failed to clear attempts for %s: %v", email, err)
+	}
 
-	if maintenanceEnabled() && !user.IsAdmin && !user.IsMaintenanceUser {
+	if maintenanceEnabled() && !user.Can(types.PermMaintenanceAccess) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "Under maintenance: restricted access"})
 		return
 	}
 
-	tok, err := issueToken(email)
+	resp, err := startSession(c, user)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign in"})
 		return
 	}
 
-	c.JSON(http.StatusOK, types.AuthResponse{Token: tok, Email: email})
+	c.JSON(http.StatusOK, resp)
 }
@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/MKolega/AirsoftHubCroatia/internal/config"
+	"github.com/MKolega/AirsoftHubCroatia/internal/db"
+	"github.com/MKolega/AirsoftHubCroatia/internal/ical"
+	"github.com/MKolega/AirsoftHubCroatia/types"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	calendarProdID = "-//AirsoftHubCroatia//Events//EN"
+	// How long deleted events keep showing up as cancelled in feeds.
+	calendarTombstoneWindow = 60 * 24 * time.Hour
+)
+
+func calendarDomain() string {
+	d := strings.TrimSpace(config.GetEnv("DOMAIN", ""))
+	if d == "" {
+		return "airsofthubcroatia.eu"
+	}
+	return d
+}
+
+// eventUID must stay stable for the lifetime of an event so calendar clients
+// treat edits as updates of the same entry.
+func eventUID(eventID int) string {
+	return fmt.Sprintf("event-%d@%s", eventID, calendarDomain())
+}
+
+func icalEventDescription(e *types.Event) string {
+	parts := make([]string, 0, 3)
+	if d := strings.TrimSpace(e.Description); d != "" {
+		parts = append(parts, d)
+	}
+	if d := strings.TrimSpace(e.DetailedDescription); d != "" {
+		parts = append(parts, d)
+	}
+	if l := strings.TrimSpace(e.FacebookLink); l != "" {
+		parts = append(parts, "Facebook: "+l)
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+func toICalEvent(e *types.Event) (ical.Event, bool) {
+	if e.StartsAt.IsZero() {
+		return ical.Event{}, false
+	}
+	lastModified := e.UpdatedAt
+	if lastModified.IsZero() {
+		lastModified = e.CreatedAt
+	}
+	out := ical.Event{
+		UID:          eventUID(e.ID),
+		Sequence:     e.Sequence,
+		Summary:      e.Name,
+		Description:  icalEventDescription(e),
+		Location:     e.Location,
+		URL:          e.FacebookLink,
+		Lat:          e.Lat,
+		Lng:          e.Lng,
+		HasGeo:       e.Lat != 0 || e.Lng != 0,
+		Start:        e.StartsAt,
+		End:          e.EndsAt,
+		Created:      e.CreatedAt,
+		LastModified: lastModified,
+	}
+	if e.Category != "" {
+		out.Categories = []string{e.Category}
+	}
+	return out, true
+}
+
+func deletedToICalEvent(d *db.DeletedEvent) (ical.Event, bool) {
+	if d.StartsAt.IsZero() {
+		return ical.Event{}, false
+	}
+	return ical.Event{
+		UID:          eventUID(d.EventID),
+		Sequence:     d.Sequence,
+		Summary:      d.Name,
+		Location:     d.Location,
+		Start:        d.StartsAt,
+		End:          d.EndsAt,
+		Created:      d.CreatedAt,
+		LastModified: d.DeletedAt,
+		Cancelled:    true,
+	}, true
+}
+
+func buildCalendar(name string, events []types.Event, deleted []db.DeletedEvent) ical.Calendar {
+	cal := ical.Calendar{ProdID: calendarProdID, Name: name}
+	for i := range events {
+		if ev, ok := toICalEvent(&events[i]); ok {
+			cal.Events = append(cal.Events, ev)
+		}
+	}
+	for i := range deleted {
+		if ev, ok := deletedToICalEvent(&deleted[i]); ok {
+			cal.Events = append(cal.Events, ev)
+		}
+	}
+	return cal
+}
+
+func writeCalendar(c *gin.Context, filename string, cal ical.Calendar) {
+	var buf bytes.Buffer
+	if err := ical.Write(&buf, cal); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render calendar"})
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="%s"`, filename))
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", buf.Bytes())
+}
+
+// EventsICSHandler serves every published event as an iCalendar feed. Events
+// whose edit awaits review are served as they were last approved.
+func EventsICSHandler(c *gin.Context) {
+	events, err := db.GetEventsFromDB()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch events"})
+		return
+	}
+	deleted, err := db.GetDeletedEvents(time.Now().Add(-calendarTombstoneWindow), nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch events"})
+		return
+	}
+	c.Header("Cache-Control", "public, max-age=300")
+	writeCalendar(c, "events.ics", buildCalendar("Airsoft Hub Croatia", events, deleted))
+}
+
+// UserCalendarICSHandler serves the saved events of the user owning the
+// token in /calendar/<token>.ics. The token is the only credential.
+func UserCalendarICSHandler(c *gin.Context) {
+	file := c.Param("file")
+	token, ok := strings.CutSuffix(file, ".ics")
+	if !ok || token == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Calendar not found"})
+		return
+	}
+
+	user, err := db.GetUserByCalendarToken(token)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Calendar not found"})
+		return
+	}
+
+	events, err := db.GetSavedEventsForUser(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch saved events"})
+		return
+	}
+	ids, err := db.GetSavedEventIDsForUser(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch saved events"})
+		return
+	}
+	if ids == nil {
+		ids = []int{}
+	}
+	deleted, err := db.GetDeletedEvents(time.Now().Add(-calendarTombstoneWindow), ids)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch saved events"})
+		return
+	}
+
+	c.Header("Cache-Control", "private, max-age=300")
+	writeCalendar(c, "saved-events.ics", buildCalendar("Airsoft Hub Croatia – Saved events", events, deleted))
+}
+
+func newCalendarToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func requestBaseURL(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil || strings.EqualFold(c.GetHeader("X-Forwarded-Proto"), "https") {
+		scheme = "https"
+	}
+	return scheme + "://" + c.Request.Host
+}
+
+func calendarLinks(c *gin.Context, token string) gin.H {
+	url := requestBaseURL(c) + "/api/calendar/" + token + ".ics"
+	webcal := "webcal://" + strings.TrimPrefix(strings.TrimPrefix(url, "https://"), "http://")
+	return gin.H{"url": url, "webcal_url": webcal}
+}
+
+// MyCalendarHandler returns the subscription URL for the caller's saved events,
+// creating the token on first use.
+func MyCalendarHandler(c *gin.Context) {
+	email, ok := emailFromAuthHeader(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Sign in required"})
+		return
+	}
+	user, err := db.GetUserByEmail(email)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	token := user.CalendarToken
+	if token == "" {
+		candidate, err := newCalendarToken()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create calendar link"})
+			return
+		}
+		token, err = db.EnsureCalendarToken(user.ID, candidate)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create calendar link"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, calendarLinks(c, token))
+}
+
+// ResetMyCalendarHandler replaces the caller's calendar token, invalidating
+// previously shared subscription URLs.
+func ResetMyCalendarHandler(c *gin.Context) {
+	email, ok := emailFromAuthHeader(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Sign in required"})
+		return
+	}
+	user, err := db.GetUserByEmail(email)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	token, err := newCalendarToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset calendar link"})
+		return
+	}
+	if err := db.SetCalendarToken(user.ID, token); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset calendar link"})
+		return
+	}
+
+	c.JSON(http.StatusOK, calendarLinks(c, token))
+}
@@ -0,0 +1,553 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/MKolega/AirsoftHubCroatia/internal/db"
+	"github.com/MKolega/AirsoftHubCroatia/internal/storage"
+	"github.com/MKolega/AirsoftHubCroatia/types"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	maxClubSlugLength       = 60
+	clubUpcomingEventsLimit = 50
+	clubPastEventsLimit     = 20
+)
+
+var clubSlugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+var clubSlugReplacer = strings.NewReplacer("č", "c", "ć", "c", "đ", "d", "š", "s", "ž", "z")
+
+// clubRoleRank orders club roles; users outside the club rank below member.
+var clubRoleRank = map[string]int{
+	db.ClubRoleMember:  1,
+	db.ClubRoleOfficer: 2,
+	db.ClubRoleOwner:   3,
+}
+
+// clubSlug derives a URL slug from a club name, e.g. "Vukovi Šibenik" ->
+// "vukovi-sibenik".
+func clubSlug(name string) string {
+	var b strings.Builder
+	for _, r := range clubSlugReplacer.Replace(strings.ToLower(name)) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case b.Len() > 0 && !strings.HasSuffix(b.String(), "-"):
+			b.WriteByte('-')
+		}
+	}
+	slug := b.String()
+	if len(slug) > maxClubSlugLength {
+		slug = slug[:maxClubSlugLength]
+	}
+	return strings.TrimRight(slug, "-")
+}
+
+func parseClubRequest(c *gin.Context, creating bool) (types.ClubRequest, bool) {
+	var req types.ClubRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return req, false
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || utf8.RuneCountInString(req.Name) > 80 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Club name is required (max 80 characters)"})
+		return req, false
+	}
+	if strings.EqualFold(req.Name, types.FreelancerClub) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Choose another club name"})
+		return req, false
+	}
+	req.Description = strings.TrimSpace(req.Description)
+	if utf8.RuneCountInString(req.Description) > 2000 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Description must be 2000 characters or less"})
+		return req, false
+	}
+	if req.HomeFieldID < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid home field"})
+		return req, false
+	}
+	if creating {
+		req.Slug = strings.ToLower(strings.TrimSpace(req.Slug))
+		if req.Slug == "" {
+			req.Slug = clubSlug(req.Name)
+		}
+		if len(req.Slug) > maxClubSlugLength || !clubSlugPattern.MatchString(req.Slug) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Slug may only use lowercase letters, digits and dashes (max 60 characters)"})
+			return req, false
+		}
+	}
+	return req, true
+}
+
+// checkHomeField accepts an empty home field or an approved field.
+func checkHomeField(c *gin.Context, fieldID int) bool {
+	if fieldID == 0 {
+		return true
+	}
+	field, err := db.GetFieldByID(fieldID)
+	if err != nil && !errors.Is(err, db.ErrFieldNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch field"})
+		return false
+	}
+	if err != nil || field.Status != "approved" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown home field"})
+		return false
+	}
+	return true
+}
+
+func writeClubError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, db.ErrClubNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Club not found"})
+	case errors.Is(err, db.ErrClubNameTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": "A club with this name already exists"})
+	case errors.Is(err, db.ErrClubSlugTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": "This club slug is already taken"})
+	case errors.Is(err, db.ErrAlreadyInClub):
+		c.JSON(http.StatusConflict, gin.H{"error": "Leave your current club first"})
+	case errors.Is(err, db.ErrAlreadyClubMember):
+		c.JSON(http.StatusConflict, gin.H{"error": "Already a member of this club"})
+	case errors.Is(err, db.ErrMembershipNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Membership not found"})
+	case errors.Is(err, db.ErrLastClubOwner):
+		c.JSON(http.StatusConflict, gin.H{"error": "Make someone else an owner first"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// clubRoleFor returns the user's role in the club. Users who may manage
+// clubs act as owners of every club.
+func clubRoleFor(user *types.User, club *types.Club) (string, error) {
+	if user.Can(types.PermManageClubs) {
+		return db.ClubRoleOwner, nil
+	}
+	return db.GetClubRole(club.ID, user.ID)
+}
+
+// requireClubUser loads the signed-in user and the club from the :slug
+// param.
+func requireClubUser(c *gin.Context) (*types.User, *types.Club, bool) {
+	email, ok := emailFromAuthHeader(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return nil, nil, false
+	}
+	user, err := db.GetUserByEmail(email)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return nil, nil, false
+	}
+	club, err := db.GetClubBySlug(c.Param("slug"))
+	if err != nil {
+		writeClubError(c, err, "Failed to fetch club")
+		return nil, nil, false
+	}
+	return user, club, true
+}
+
+// requireClubRole is requireClubUser for club members holding at least
+// minRole. It also returns the user's role.
+func requireClubRole(c *gin.Context, minRole string) (*types.User, *types.Club, string, bool) {
+	user, club, ok := requireClubUser(c)
+	if !ok {
+		return nil, nil, "", false
+	}
+	role, err := clubRoleFor(user, club)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check club membership"})
+		return nil, nil, "", false
+	}
+	if clubRoleRank[role] < clubRoleRank[minRole] {
+		msg := "Only club officers and owners can do this"
+		if minRole == db.ClubRoleOwner {
+			msg = "Only club owners can do this"
+		}
+		c.JSON(http.StatusForbidden, gin.H{"error": msg})
+		return nil, nil, "", false
+	}
+	return user, club, role, true
+}
+
+func clubUserIDParam(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("userId"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user id"})
+		return 0, false
+	}
+	return id, true
+}
+
+// applyEventClub checks that the user may publish the event on behalf of its
+// club: club owners and officers can, and an event keeps the club it already
+// has. ClubID 0 publishes the event without a club.
+func applyEventClub(c *gin.Context, user *types.User, existing *types.Event, event *types.Event) bool {
+	if event.ClubID == 0 || (existing != nil && existing.ClubID == event.ClubID) {
+		return true
+	}
+	club, err := db.GetClubByID(event.ClubID)
+	if err != nil {
+		if errors.Is(err, db.ErrClubNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown club"})
+			return false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch club"})
+		return false
+	}
+	role, err := clubRoleFor(user, club)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check club membership"})
+		return false
+	}
+	if clubRoleRank[role] < clubRoleRank[db.ClubRoleOfficer] {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only club officers and owners can publish events for the club"})
+		return false
+	}
+	return true
+}
+
+// ClubsHandler lists clubs, optionally filtered by ?q= (name).
+func ClubsHandler(c *gin.Context) {
+	clubs, err := db.ListClubs(c.Query("q"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch clubs"})
+		return
+	}
+	c.JSON(http.StatusOK, clubs)
+}
+
+// ClubDetailHandler returns a club with its members and events. Signed-in
+// users also get their own membership, and the club's officers the pending
+// invites and join requests.
+func ClubDetailHandler(c *gin.Context) {
+	club, err := db.GetClubBySlug(c.Param("slug"))
+	if err != nil {
+		writeClubError(c, err, "Failed to fetch club")
+		return
+	}
+	detail := types.ClubDetail{Club: *club}
+
+	if club.HomeFieldID != 0 {
+		field, err := db.GetFieldByID(club.HomeFieldID)
+		if err != nil && !errors.Is(err, db.ErrFieldNotFound) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch field"})
+			return
+		}
+		if err == nil && field.Status == "approved" {
+			detail.HomeField = field
+		}
+	}
+	if detail.Members, err = db.GetClubMembers(club.ID, db.ClubMemberActive); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch club members"})
+		return
+	}
+	if detail.UpcomingEvents, err = db.GetClubEvents(club.ID, true, clubUpcomingEventsLimit); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch club events"})
+		return
+	}
+	if detail.PastEvents, err = db.GetClubEvents(club.ID, false, clubPastEventsLimit); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch club events"})
+		return
+	}
+
+	if email, ok := emailFromAuthHeader(c); ok {
+		if user, err := db.GetUserByEmail(email); err == nil {
+			if member, err := db.GetClubMember(club.ID, user.ID); err == nil {
+				detail.Membership = member
+			}
+			if role, err := clubRoleFor(user, club); err == nil && clubRoleRank[role] >= clubRoleRank[db.ClubRoleOfficer] {
+				if detail.Pending, err = db.GetClubMembers(club.ID, db.ClubMemberInvited, db.ClubMemberRequested); err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch club members"})
+					return
+				}
+			}
+		}
+	}
+	c.JSON(http.StatusOK, detail)
+}
+
+// CreateClubHandler creates a club owned by the signed-in user, who must not
+// already belong to a club.
+func CreateClubHandler(c *gin.Context) {
+	email, ok := emailFromAuthHeader(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Sign in required to create clubs"})
+		return
+	}
+	user, err := db.GetUserByEmail(email)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+	if user.EmailVerifiedAt.IsZero() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Verify your email address before creating clubs"})
+		return
+	}
+	req, ok := parseClubRequest(c, true)
+	if !ok || !checkHomeField(c, req.HomeFieldID) {
+		return
+	}
+
+	club := &types.Club{
+		Slug:        req.Slug,
+		Name:        req.Name,
+		Description: req.Description,
+		HomeFieldID: req.HomeFieldID,
+	}
+	if err := db.CreateClub(club, user.ID); err != nil {
+		writeClubError(c, err, "Failed to create club")
+		return
+	}
+	c.JSON(http.StatusCreated, club)
+}
+
+// UpdateClubHandler edits a club's name, description and home field. The
+// slug stays fixed so links keep working.
+func UpdateClubHandler(c *gin.Context) {
+	_, club, _, ok := requireClubRole(c, db.ClubRoleOfficer)
+	if !ok {
+		return
+	}
+	req, ok := parseClubRequest(c, false)
+	if !ok || !checkHomeField(c, req.HomeFieldID) {
+		return
+	}
+
+	club.Name = req.Name
+	club.Description = req.Description
+	club.HomeFieldID = req.HomeFieldID
+	if err := db.UpdateClub(club); err != nil {
+		writeClubError(c, err, "Failed to update club")
+		return
+	}
+	c.JSON(http.StatusOK, club)
+}
+
+// DeleteClubHandler removes a club. Its members become freelancers and its
+// events stay published without a club.
+func DeleteClubHandler(c *gin.Context) {
+	_, club, _, ok := requireClubRole(c, db.ClubRoleOwner)
+	if !ok {
+		return
+	}
+	keys, err := db.ClubStoredObjectKeys(club.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete club"})
+		return
+	}
+	if err := db.DeleteClub(club.ID); err != nil {
+		writeClubError(c, err, "Failed to delete club")
+		return
+	}
+	discardObjects(keys)
+	c.Status(http.StatusNoContent)
+}
+
+// UploadClubLogoHandler replaces the club's logo with an image (multipart
+// field "file").
+func UploadClubLogoHandler(c *gin.Context) {
+	_, club, _, ok := requireClubRole(c, db.ClubRoleOfficer)
+	if !ok {
+		return
+	}
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing file"})
+		return
+	}
+
+	logo, err := storage.UploadClubLogo(c.Request.Context(), fileHeader)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if storage.IsClientUploadError(err) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	keys, err := trackUploads(db.ObjectKindClubLogo, []storage.Object{*logo})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store logo"})
+		return
+	}
+
+	replaced, err := db.SetClubLogo(club.ID, logo.URL, logo.Key)
+	if err != nil {
+		discardObjects(keys)
+		writeClubError(c, err, "Failed to save logo")
+		return
+	}
+	discardObjects(replaced)
+	club.Logo = logo.URL
+	c.JSON(http.StatusOK, club)
+}
+
+// JoinClubHandler asks to join a club, or accepts a pending invite to it.
+func JoinClubHandler(c *gin.Context) {
+	user, club, ok := requireClubUser(c)
+	if !ok {
+		return
+	}
+	member, err := db.RequestClubMembership(club.ID, user.ID)
+	if err != nil {
+		writeClubError(c, err, "Failed to join club")
+		return
+	}
+	c.JSON(http.StatusOK, member)
+}
+
+// InviteClubMemberHandler invites a user by username. Inviting someone who
+// asked to join accepts their request.
+func InviteClubMemberHandler(c *gin.Context) {
+	user, club, _, ok := requireClubRole(c, db.ClubRoleOfficer)
+	if !ok {
+		return
+	}
+	var req types.ClubInviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Username) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Username is required"})
+		return
+	}
+	invitee, err := db.GetUserByUsername(req.Username)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	member, err := db.InviteClubMember(club.ID, invitee.ID, user.ID)
+	if err != nil {
+		writeClubError(c, err, "Failed to invite member")
+		return
+	}
+	c.JSON(http.StatusOK, member)
+}
+
+// AcceptClubMemberHandler accepts a pending membership: the invited user
+// accepts their own invite, officers accept join requests.
+func AcceptClubMemberHandler(c *gin.Context) {
+	userID, ok := clubUserIDParam(c)
+	if !ok {
+		return
+	}
+	user, club, ok := requireClubUser(c)
+	if !ok {
+		return
+	}
+
+	status := db.ClubMemberInvited
+	if userID != user.ID {
+		role, err := clubRoleFor(user, club)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check club membership"})
+			return
+		}
+		if clubRoleRank[role] < clubRoleRank[db.ClubRoleOfficer] {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only club officers and owners can do this"})
+			return
+		}
+		status = db.ClubMemberRequested
+	}
+
+	member, err := db.AcceptClubMembership(club.ID, userID, status)
+	if err != nil {
+		writeClubError(c, err, "Failed to accept membership")
+		return
+	}
+	c.JSON(http.StatusOK, member)
+}
+
+// UpdateClubMemberHandler changes a member's role. Only owners hand out
+// roles.
+func UpdateClubMemberHandler(c *gin.Context) {
+	userID, ok := clubUserIDParam(c)
+	if !ok {
+		return
+	}
+	_, club, _, ok := requireClubRole(c, db.ClubRoleOwner)
+	if !ok {
+		return
+	}
+	var req types.ClubMemberRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil || clubRoleRank[req.Role] == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Role must be owner, officer or member"})
+		return
+	}
+
+	member, err := db.SetClubMemberRole(club.ID, userID, req.Role)
+	if err != nil {
+		writeClubError(c, err, "Failed to update member")
+		return
+	}
+	c.JSON(http.StatusOK, member)
+}
+
+// RemoveClubMemberHandler removes a membership, invite or join request.
+// Users may always remove their own; officers remove members and pending
+// ones, owners anyone.
+func RemoveClubMemberHandler(c *gin.Context) {
+	userID, ok := clubUserIDParam(c)
+	if !ok {
+		return
+	}
+	user, club, ok := requireClubUser(c)
+	if !ok {
+		return
+	}
+
+	if userID != user.ID {
+		role, err := clubRoleFor(user, club)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check club membership"})
+			return
+		}
+		target, err := db.GetClubMember(club.ID, userID)
+		if err != nil {
+			writeClubError(c, err, "Failed to remove member")
+			return
+		}
+		required := db.ClubRoleOfficer
+		if target.Status == db.ClubMemberActive && target.Role != db.ClubRoleMember {
+			required = db.ClubRoleOwner
+		}
+		if clubRoleRank[role] < clubRoleRank[required] {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Not allowed to remove this member"})
+			return
+		}
+	}
+
+	if err := db.RemoveClubMember(club.ID, userID); err != nil {
+		writeClubError(c, err, "Failed to remove member")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// MyClubsHandler lists the signed-in user's club, invites and join
+// requests.
+func MyClubsHandler(c *gin.Context) {
+	email, ok := emailFromAuthHeader(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	user, err := db.GetUserByEmail(email)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+	memberships, err := db.GetUserClubMemberships(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch clubs"})
+		return
+	}
+	c.JSON(http.StatusOK, memberships)
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+
+	"github.com/MKolega/AirsoftHubCroatia/internal/db"
+	"github.com/gin-gonic/gin"
+	_ "github.com/lib/pq"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+)
+
+// useTestDB points the db package at the disposable Postgres database in
+// TEST_DATABASE_URL, migrated to the latest schema, and skips the test when
+// it is not set.
+func useTestDB(t *testing.T) {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	sqldb, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("open test database: %v", err)
+	}
+	prev := db.Bun
+	db.Bun = bun.NewDB(sqldb, pgdialect.New())
+	t.Cleanup(func() {
+		db.Bun.Close()
+		db.Bun = prev
+	})
+	if err := db.EnsureSchema(context.Background(), true); err != nil {
+		t.Fatalf("migrate test database: %v", err)
+	}
+	gin.SetMode(gin.TestMode)
+}
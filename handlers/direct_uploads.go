@@ -0,0 +1,273 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/MKolega/AirsoftHubCroatia/internal/db"
+	"github.com/MKolega/AirsoftHubCroatia/internal/storage"
+	"github.com/MKolega/AirsoftHubCroatia/types"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// directUploadTTL is how long the issued PUT URL stays valid.
+	directUploadTTL = 15 * time.Minute
+	// directUploadFinalizeWindow leaves time for a PUT started just before
+	// the URL expired to finish before the upload is finalized.
+	directUploadFinalizeWindow = time.Hour
+)
+
+func newUploadID() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// CreateUploadHandler starts a direct upload of event media. The response
+// holds a URL the client PUTs the file to (with the returned headers) before
+// calling POST /api/uploads/:id/finalize. S3/R2 backends hand out a
+// presigned URL; local storage accepts the PUT on /api/uploads/:id/content.
+func CreateUploadHandler(c *gin.Context) {
+	email, ok := emailFromAuthHeader(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	user, err := db.GetUserByEmail(email)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	var req types.UploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	if req.EventID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event id"})
+		return
+	}
+	caption, ok := parseMediaCaption(c, req.Caption)
+	if !ok {
+		return
+	}
+	contentType := strings.ToLower(strings.TrimSpace(req.ContentType))
+
+	event, err := db.GetEventByID(req.EventID)
+	if err != nil {
+		if errors.Is(err, db.ErrEventNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch event"})
+		return
+	}
+	if !canManageEvent(user, event) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the event's organizers or a moderator can do this"})
+		return
+	}
+
+	key, err := storage.DirectUploadKey(contentType, req.Size)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if storage.IsClientUploadError(err) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	store, err := storage.Default()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	id, err := newUploadID()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start upload"})
+		return
+	}
+
+	upload := &types.Upload{
+		ID:          id,
+		UserID:      user.ID,
+		EventID:     event.ID,
+		StorageKey:  key,
+		ContentType: contentType,
+		SizeBytes:   req.Size,
+		Caption:     caption,
+		ExpiresAt:   time.Now().Add(directUploadTTL),
+	}
+	url := "/api/uploads/" + id + "/content"
+	if presigner, ok := store.(storage.Presigner); ok {
+		url, err = presigner.PresignPut(c.Request.Context(), key, contentType, req.Size, directUploadTTL)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start upload"})
+			return
+		}
+	}
+	if err := db.CreateUpload(upload); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start upload"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"upload_id":  upload.ID,
+		"url":        url,
+		"method":     http.MethodPut,
+		"headers":    gin.H{"Content-Type": contentType},
+		"expires_at": upload.ExpiresAt,
+	})
+}
+
+// pendingUpload loads an upload that can still receive or finalize a file.
+func pendingUpload(c *gin.Context, deadline func(*types.Upload) time.Time) (*types.Upload, bool) {
+	upload, err := db.GetUpload(c.Param("id"))
+	if err != nil {
+		if errors.Is(err, db.ErrUploadNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Upload not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch upload"})
+		return nil, false
+	}
+	if !upload.FinalizedAt.IsZero() {
+		c.JSON(http.StatusConflict, gin.H{"error": "Upload already finalized"})
+		return nil, false
+	}
+	if time.Now().After(deadline(upload)) {
+		c.JSON(http.StatusGone, gin.H{"error": "Upload expired"})
+		return nil, false
+	}
+	return upload, true
+}
+
+// PutUploadContentHandler receives the file of a direct upload when the
+// storage backend cannot presign URLs. Like a presigned URL, the upload ID
+// is the credential.
+func PutUploadContentHandler(c *gin.Context) {
+	store, err := storage.Default()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if _, ok := store.(storage.Presigner); ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Upload not found"})
+		return
+	}
+	upload, ok := pendingUpload(c, func(u *types.Upload) time.Time { return u.ExpiresAt })
+	if !ok {
+		return
+	}
+	if !strings.EqualFold(strings.TrimSpace(c.ContentType()), upload.ContentType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Content-Type does not match the upload"})
+		return
+	}
+	if c.Request.ContentLength > upload.SizeBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "File is larger than declared"})
+		return
+	}
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, upload.SizeBytes)
+	if err := store.Put(c.Request.Context(), upload.StorageKey, body, upload.ContentType); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "File is larger than declared"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store file"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// FinalizeUploadHandler checks the uploaded file's type and size and adds it
+// to the upload's event. Like other media, it waits for review when the event
+// is already published.
+func FinalizeUploadHandler(c *gin.Context) {
+	email, ok := emailFromAuthHeader(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	user, err := db.GetUserByEmail(email)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+	upload, ok := pendingUpload(c, func(u *types.Upload) time.Time { return u.CreatedAt.Add(directUploadFinalizeWindow) })
+	if !ok {
+		return
+	}
+	if upload.UserID != user.ID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Upload not found"})
+		return
+	}
+	event, err := db.GetEventByID(upload.EventID)
+	if err != nil {
+		if errors.Is(err, db.ErrEventNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch event"})
+		return
+	}
+	if !canManageEvent(user, event) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the event's organizers or a moderator can do this"})
+		return
+	}
+
+	uploaded, err := storage.FinalizeDirectUpload(c.Request.Context(), upload.StorageKey, upload.ContentType)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if storage.IsClientUploadError(err) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	// The raw file is only deleted once the media is saved so a failed
+	// finalize can be retried. Unfinalized raw files are untracked and
+	// removed by storage-gc.
+	keys, err := trackUploads(db.ObjectKindMedia, []storage.Object{uploaded.Object})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store file"})
+		return
+	}
+
+	media := &types.EventMedia{
+		EventID:     event.ID,
+		Status:      mediaStatus(user, event),
+		Type:        uploaded.Type,
+		Caption:     upload.Caption,
+		StorageKey:  uploaded.Object.Key,
+		URL:         uploaded.Object.URL,
+		ContentType: uploaded.Object.ContentType,
+		SizeBytes:   uploaded.Object.Size,
+	}
+	if err := db.FinalizeUpload(upload.ID, media); err != nil {
+		discardObjects(keys)
+		switch {
+		case errors.Is(err, db.ErrUploadFinalized):
+			c.JSON(http.StatusConflict, gin.H{"error": "Upload already finalized"})
+		case errors.Is(err, db.ErrMediaLimit):
+			c.JSON(http.StatusConflict, gin.H{"error": "An event can have at most 20 media items"})
+		case errors.Is(err, db.ErrUploadNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Upload not found"})
+		case errors.Is(err, db.ErrEventNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save media"})
+		}
+		return
+	}
+	discardObjects([]string{upload.StorageKey})
+	c.JSON(http.StatusCreated, media)
+}
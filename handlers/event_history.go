@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/MKolega/AirsoftHubCroatia/internal/db"
+	"github.com/gin-gonic/gin"
+)
+
+// AdminEventHistoryHandler lists every recorded revision of an event, newest
+// first. It also works for deleted events.
+func AdminEventHistoryHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event id"})
+		return
+	}
+
+	revisions, err := db.GetEventRevisions(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch event history"})
+		return
+	}
+	if len(revisions) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No history for this event"})
+		return
+	}
+	c.JSON(http.StatusOK, revisions)
+}
+
+// AdminRestoreEventRevisionHandler restores an event to a prior revision.
+func AdminRestoreEventRevisionHandler(c *gin.Context) {
+	adminEmail := currentUser(c).Email
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event id"})
+		return
+	}
+	revision, err := strconv.Atoi(c.Param("revision"))
+	if err != nil || revision <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid revision"})
+		return
+	}
+
+	event, err := db.RestoreEventRevision(id, revision, adminEmail)
+	if err != nil {
+		if errors.Is(err, db.ErrRevisionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Revision not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore revision"})
+		return
+	}
+	releaseStaleThumbnails(id)
+	c.JSON(http.StatusOK, event)
+}
@@ -0,0 +1,306 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/MKolega/AirsoftHubCroatia/internal/db"
+	"github.com/MKolega/AirsoftHubCroatia/internal/storage"
+	"github.com/MKolega/AirsoftHubCroatia/types"
+	"github.com/gin-gonic/gin"
+)
+
+const maxMediaCaptionLength = 300
+
+// EventDetailHandler returns one event with its gallery, attachments and
+// field.
+// Unpublished events are only visible to their creator and to users who
+// review or manage events. Everyone else sees an event whose edit awaits
+// review as it was last approved.
+func EventDetailHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event id"})
+		return
+	}
+	event, err := db.GetEventByID(id)
+	if err != nil {
+		if errors.Is(err, db.ErrEventNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch event"})
+		return
+	}
+	// Whoever may see the unpublished event also sees media awaiting review.
+	privileged := canSeeUnpublishedEvent(c, event)
+	if !privileged {
+		if !event.Published() {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return
+		}
+		event = event.PublishedVersion()
+	}
+
+	media, err := db.GetEventMedia(id, privileged)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch event media"})
+		return
+	}
+	detail := types.EventDetail{Event: *event, Media: media}
+	if event.FieldID != 0 {
+		field, err := db.GetFieldByID(event.FieldID)
+		if err != nil && !errors.Is(err, db.ErrFieldNotFound) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch field"})
+			return
+		}
+		if err == nil && field.Status == "approved" {
+			detail.Field = field
+		}
+	}
+	if event.ClubID != 0 {
+		club, err := db.GetClubByID(event.ClubID)
+		if err != nil && !errors.Is(err, db.ErrClubNotFound) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch club"})
+			return
+		}
+		detail.Club = club
+	}
+	detail.PreviousVersion = nil
+	c.JSON(http.StatusOK, detail)
+}
+
+func canSeeUnpublishedEvent(c *gin.Context, event *types.Event) bool {
+	email, ok := emailFromAuthHeader(c)
+	if !ok {
+		return false
+	}
+	if strings.EqualFold(email, event.CreatorEmail) {
+		return true
+	}
+	user, err := db.GetUserByEmail(email)
+	if err != nil {
+		return false
+	}
+	return canManageEvent(user, event) || user.Can(types.PermReviewEvents)
+}
+
+// mediaStatus is the status of media the user adds to the event. Media on a
+// published event, or on a pending edit of one, waits for review unless the
+// user may publish directly; media on an event that was never published is
+// reviewed together with the event.
+func mediaStatus(user *types.User, event *types.Event) string {
+	if user.Can(types.PermPublishDirectly) || !event.Published() {
+		return "approved"
+	}
+	return "pending"
+}
+
+func parseMediaCaption(c *gin.Context, raw string) (string, bool) {
+	caption := strings.TrimSpace(raw)
+	if utf8.RuneCountInString(caption) > maxMediaCaptionLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Caption must be at most 300 characters"})
+		return "", false
+	}
+	return caption, true
+}
+
+// UploadEventMediaHandler adds an image or PDF (multipart field "file", with
+// an optional "caption") to the end of the event's media list.
+func UploadEventMediaHandler(c *gin.Context) {
+	user, event, ok := requireEventManager(c)
+	if !ok {
+		return
+	}
+	caption, ok := parseMediaCaption(c, c.PostForm("caption"))
+	if !ok {
+		return
+	}
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing file"})
+		return
+	}
+
+	uploaded, err := storage.UploadMedia(c.Request.Context(), fileHeader)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if storage.IsClientUploadError(err) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	keys, err := trackUploads(db.ObjectKindMedia, []storage.Object{uploaded.Object})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store file"})
+		return
+	}
+
+	media := &types.EventMedia{
+		EventID:     event.ID,
+		Status:      mediaStatus(user, event),
+		Type:        uploaded.Type,
+		Caption:     caption,
+		StorageKey:  uploaded.Object.Key,
+		URL:         uploaded.Object.URL,
+		ContentType: uploaded.Object.ContentType,
+		SizeBytes:   uploaded.Object.Size,
+	}
+	if err := db.AddEventMedia(media); err != nil {
+		discardObjects(keys)
+		switch {
+		case errors.Is(err, db.ErrMediaLimit):
+			c.JSON(http.StatusConflict, gin.H{"error": "An event can have at most 20 media items"})
+		case errors.Is(err, db.ErrEventNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save media"})
+		}
+		return
+	}
+	c.JSON(http.StatusCreated, media)
+}
+
+func mediaIDParam(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("mediaId"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid media id"})
+		return 0, false
+	}
+	return id, true
+}
+
+// UpdateEventMediaHandler changes a media item's caption. A changed caption
+// on a published event goes back to review like new media.
+func UpdateEventMediaHandler(c *gin.Context) {
+	user, event, ok := requireEventManager(c)
+	if !ok {
+		return
+	}
+	mediaID, ok := mediaIDParam(c)
+	if !ok {
+		return
+	}
+	var req struct {
+		Caption string `json:"caption"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	caption, ok := parseMediaCaption(c, req.Caption)
+	if !ok {
+		return
+	}
+
+	media, err := db.UpdateEventMediaCaption(event.ID, mediaID, caption, mediaStatus(user, event) == "pending")
+	if err != nil {
+		if errors.Is(err, db.ErrMediaNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Media not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update media"})
+		return
+	}
+	c.JSON(http.StatusOK, media)
+}
+
+// ReorderEventMediaHandler sets the display order from {"ids": [...]}.
+func ReorderEventMediaHandler(c *gin.Context) {
+	_, event, ok := requireEventManager(c)
+	if !ok {
+		return
+	}
+	var req types.MediaOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	if err := db.ReorderEventMedia(event.ID, req.IDs); err != nil {
+		if errors.Is(err, db.ErrInvalidMediaSet) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "ids must list every media item of the event once"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reorder media"})
+		return
+	}
+	media, err := db.GetEventMedia(event.ID, true)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch event media"})
+		return
+	}
+	c.JSON(http.StatusOK, media)
+}
+
+// DeleteEventMediaHandler removes a media item and its stored file.
+func DeleteEventMediaHandler(c *gin.Context) {
+	_, event, ok := requireEventManager(c)
+	if !ok {
+		return
+	}
+	mediaID, ok := mediaIDParam(c)
+	if !ok {
+		return
+	}
+
+	media, err := db.DeleteEventMedia(event.ID, mediaID)
+	if err != nil {
+		if errors.Is(err, db.ErrMediaNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Media not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete media"})
+		return
+	}
+	discardObjects([]string{media.StorageKey})
+	c.Status(http.StatusNoContent)
+}
+
+func AdminPendingMediaHandler(c *gin.Context) {
+	media, err := db.GetPendingEventMedia()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch pending media"})
+		return
+	}
+	c.JSON(http.StatusOK, media)
+}
+
+func AdminApproveMediaHandler(c *gin.Context) {
+	mediaID, ok := mediaIDParam(c)
+	if !ok {
+		return
+	}
+	if err := db.ApproveEventMedia(mediaID, currentUser(c).Email); err != nil {
+		if errors.Is(err, db.ErrMediaNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Media not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to approve media"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// AdminRejectMediaHandler removes a pending media item and its stored file.
+func AdminRejectMediaHandler(c *gin.Context) {
+	mediaID, ok := mediaIDParam(c)
+	if !ok {
+		return
+	}
+	media, err := db.RejectEventMedia(mediaID)
+	if err != nil {
+		if errors.Is(err, db.ErrMediaNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Media not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reject media"})
+		return
+	}
+	discardObjects([]string{media.StorageKey})
+	c.Status(http.StatusNoContent)
+}
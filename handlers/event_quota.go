@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MKolega/AirsoftHubCroatia/internal/config"
+	"github.com/MKolega/AirsoftHubCroatia/internal/db"
+	"github.com/MKolega/AirsoftHubCroatia/types"
+	"github.com/gin-gonic/gin"
+)
+
+// Quota tiers. Role tiers are named after the role that grants them.
+const (
+	quotaTierUser       = "user"
+	quotaTierNewAccount = "new_account"
+)
+
+// unlimitedQuota marks a tier without a daily cap.
+const unlimitedQuota = -1
+
+// defaultEventQuotas is the number of events each tier may submit per
+// Europe/Zagreb day. Override with EVENT_QUOTA_<TIER>=<n|unlimited>.
+var defaultEventQuotas = map[string]int{
+	types.RoleAdmin:     unlimitedQuota,
+	types.RoleModerator: unlimitedQuota,
+	types.RoleOrganizer: 10,
+	quotaTierUser:       2,
+	quotaTierNewAccount: 1,
+}
+
+func eventQuotaLimit(tier string) int {
+	fallback := defaultEventQuotas[tier]
+	raw := strings.TrimSpace(config.GetEnv("EVENT_QUOTA_"+strings.ToUpper(tier), ""))
+	if raw == "" {
+		return fallback
+	}
+	if strings.EqualFold(raw, "unlimited") {
+		return unlimitedQuota
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 {
+		return fallback
+	}
+	return n
+}
+
+// eventQuotaTier picks the most generous tier among the user's roles. Users
+// without a quota-bearing role fall back to the new_account tier for their
+// first EVENT_QUOTA_NEW_ACCOUNT_DAYS days, then to the user tier.
+func eventQuotaTier(user *types.User, now time.Time) (string, int) {
+	tier, limit := "", 0
+	for _, role := range user.Roles {
+		if _, ok := defaultEventQuotas[role]; !ok {
+			continue
+		}
+		n := eventQuotaLimit(role)
+		if tier == "" || n == unlimitedQuota || (limit != unlimitedQuota && n > limit) {
+			tier, limit = role, n
+		}
+	}
+	if tier != "" {
+		return tier, limit
+	}
+
+	newAccountAge := time.Duration(envPositiveInt("EVENT_QUOTA_NEW_ACCOUNT_DAYS", 7)) * 24 * time.Hour
+	if !user.CreatedAt.IsZero() && now.Sub(user.CreatedAt) < newAccountAge {
+		return quotaTierNewAccount, eventQuotaLimit(quotaTierNewAccount)
+	}
+	return quotaTierUser, eventQuotaLimit(quotaTierUser)
+}
+
+// quotaDayBounds returns the Europe/Zagreb day containing t, so the quota
+// resets at local midnight regardless of the server's time zone.
+func quotaDayBounds(t time.Time) (time.Time, time.Time) {
+	loc, err := types.LoadEventLocation(types.DefaultEventTimeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+	return dayBounds(t.In(loc))
+}
+
+// eventQuotaFor reports how many events the user may still submit today.
+func eventQuotaFor(user *types.User, now time.Time) (*types.EventQuota, error) {
+	tier, limit := eventQuotaTier(user, now)
+	start, end := quotaDayBounds(now)
+	used, err := db.CountEventsByCreatorInRange(user.Email, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	quota := &types.EventQuota{
+		Tier:      tier,
+		Used:      used,
+		Unlimited: limit == unlimitedQuota,
+		ResetsAt:  end,
+	}
+	if !quota.Unlimited {
+		quota.Limit = limit
+		quota.Remaining = max(limit-used, 0)
+	}
+	return quota, nil
+}
+
+// insertEventWithinQuota saves the event, re-checking the quota in the same
+// transaction so parallel submissions cannot exceed it.
+func insertEventWithinQuota(c *gin.Context, user *types.User, event *types.Event, now time.Time) bool {
+	tier, limit := eventQuotaTier(user, now)
+	start, end := quotaDayBounds(now)
+	err := db.InsertEventWithinQuota(event, limit, start, end)
+	if errors.Is(err, db.ErrEventQuotaExceeded) {
+		writeEventQuotaExceeded(c, &types.EventQuota{Tier: tier, Limit: limit, Used: limit, ResetsAt: end})
+		return false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create event"})
+		return false
+	}
+	return true
+}
+
+func writeEventQuotaExceeded(c *gin.Context, quota *types.EventQuota) {
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(time.Until(quota.ResetsAt).Seconds()))))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error": fmt.Sprintf("Daily limit reached (%d events per day)", quota.Limit),
+		"quota": quota,
+	})
+}
+
+// MyQuotaHandler lets the create form show remaining submissions up front.
+func MyQuotaHandler(c *gin.Context) {
+	email, ok := emailFromAuthHeader(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	user, err := db.GetUserByEmail(email)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+	quota, err := eventQuotaFor(user, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch quota"})
+		return
+	}
+	c.JSON(http.StatusOK, quota)
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/MKolega/AirsoftHubCroatia/types"
+)
+
+// Start time assumed for clients that only send a date.
+const legacyEventStartHour = 9
+
+var eventTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+}
+
+// parseEventTime accepts RFC 3339 or a zone-less local time (as sent by
+// <input type="datetime-local">), which is interpreted in loc.
+func parseEventTime(raw string, loc *time.Location) (time.Time, error) {
+	v := strings.TrimSpace(raw)
+	for _, layout := range eventTimeLayouts {
+		if layout == time.RFC3339 {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t, nil
+			}
+			continue
+		}
+		if t, err := time.ParseInLocation(layout, v, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.New("invalid time")
+}
+
+// normalizeEventSchedule resolves StartsAt/EndsAt/TimeZone on event from
+// startsRaw/endsRaw (form values; empty when already bound from JSON) or,
+// for older clients, from the date-only Date field. A missing end defaults to
+// the category's usual length. On failure it returns a user-facing message.
+func normalizeEventSchedule(event *types.Event, startsRaw string, endsRaw string) (string, bool) {
+	tz := strings.TrimSpace(event.TimeZone)
+	if tz == "" {
+		tz = types.DefaultEventTimeZone
+	}
+	loc, err := types.LoadEventLocation(tz)
+	if err != nil {
+		return "Invalid time zone", false
+	}
+
+	if strings.TrimSpace(startsRaw) != "" {
+		t, err := parseEventTime(startsRaw, loc)
+		if err != nil {
+			return "Invalid start time", false
+		}
+		event.StartsAt = t
+	}
+	if strings.TrimSpace(endsRaw) != "" {
+		t, err := parseEventTime(endsRaw, loc)
+		if err != nil {
+			return "Invalid end time", false
+		}
+		event.EndsAt = t
+	}
+
+	if event.StartsAt.IsZero() {
+		date := strings.TrimSpace(event.Date)
+		if date == "" {
+			return "Start time is required", false
+		}
+		day, err := time.ParseInLocation("2006-01-02", date, loc)
+		if err != nil {
+			return "Invalid date (expected YYYY-MM-DD)", false
+		}
+		event.StartsAt = day.Add(legacyEventStartHour * time.Hour)
+	}
+	if event.EndsAt.IsZero() {
+		event.EndsAt = event.StartsAt.Add(allowedEventCategories[event.Category])
+	}
+	if !event.EndsAt.After(event.StartsAt) {
+		return "End time must be after start time", false
+	}
+
+	event.StartsAt = event.StartsAt.UTC()
+	event.EndsAt = event.EndsAt.UTC()
+	event.TimeZone = tz
+	event.Date = event.LocalDate()
+	return "", true
+}
+
+// carryEventSchedule handles date-only edits from older clients: the event
+// moves to the new date but keeps its local start time and length.
+func carryEventSchedule(event *types.Event, existing *types.Event, startsRaw string, endsRaw string) {
+	if strings.TrimSpace(startsRaw) != "" || strings.TrimSpace(endsRaw) != "" || !event.StartsAt.IsZero() {
+		return
+	}
+	if existing.StartsAt.IsZero() || strings.TrimSpace(event.Date) == "" {
+		return
+	}
+	if strings.TrimSpace(event.TimeZone) == "" {
+		event.TimeZone = existing.TimeZone
+	}
+	loc, err := types.LoadEventLocation(event.TimeZone)
+	if err != nil {
+		return
+	}
+	day, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(event.Date), loc)
+	if err != nil {
+		return
+	}
+	prev := existing.StartsAt.In(loc)
+	event.StartsAt = time.Date(day.Year(), day.Month(), day.Day(), prev.Hour(), prev.Minute(), prev.Second(), 0, loc)
+	if !existing.EndsAt.IsZero() {
+		event.EndsAt = event.StartsAt.Add(existing.EndsAt.Sub(existing.StartsAt))
+	}
+}
@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/MKolega/AirsoftHubCroatia/types"
+)
+
+func mustParseUTC(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestNormalizeEventSchedule(t *testing.T) {
+	tests := []struct {
+		name       string
+		event      types.Event
+		startsRaw  string
+		endsRaw    string
+		wantStart  string
+		wantEnd    string
+		wantZone   string
+		wantDate   string
+		wantErrMsg string
+	}{
+		{
+			name:      "local times in the default zone",
+			event:     types.Event{Category: "Skirmish"},
+			startsRaw: "2026-07-04T09:00",
+			endsRaw:   "2026-07-04T15:30",
+			wantStart: "2026-07-04T07:00:00Z",
+			wantEnd:   "2026-07-04T13:30:00Z",
+			wantZone:  "Europe/Zagreb",
+			wantDate:  "2026-07-04",
+		},
+		{
+			name:      "RFC 3339 start with category length",
+			event:     types.Event{Category: "12h", TimeZone: "Europe/Zagreb"},
+			startsRaw: "2026-01-10T08:00:00Z",
+			wantStart: "2026-01-10T08:00:00Z",
+			wantEnd:   "2026-01-10T20:00:00Z",
+			wantZone:  "Europe/Zagreb",
+			wantDate:  "2026-01-10",
+		},
+		{
+			name:      "other time zone",
+			event:     types.Event{Category: "24h", TimeZone: "Europe/London"},
+			startsRaw: "2026-01-10 10:00",
+			wantStart: "2026-01-10T10:00:00Z",
+			wantEnd:   "2026-01-11T10:00:00Z",
+			wantZone:  "Europe/London",
+			wantDate:  "2026-01-10",
+		},
+		{
+			name:      "local date differs from the UTC date",
+			event:     types.Event{Category: "Skirmish"},
+			startsRaw: "2026-07-04T01:00:00",
+			wantStart: "2026-07-03T23:00:00Z",
+			wantEnd:   "2026-07-04T05:00:00Z",
+			wantZone:  "Europe/Zagreb",
+			wantDate:  "2026-07-04",
+		},
+		{
+			name:      "date-only client",
+			event:     types.Event{Category: "Skirmish", Date: "2026-03-01"},
+			wantStart: "2026-03-01T08:00:00Z",
+			wantEnd:   "2026-03-01T14:00:00Z",
+			wantZone:  "Europe/Zagreb",
+			wantDate:  "2026-03-01",
+		},
+		{
+			name:      "already bound from JSON",
+			event:     types.Event{Category: "Skirmish", StartsAt: mustParseUTC("2026-05-02T06:00:00Z"), EndsAt: mustParseUTC("2026-05-02T09:00:00Z")},
+			wantStart: "2026-05-02T06:00:00Z",
+			wantEnd:   "2026-05-02T09:00:00Z",
+			wantZone:  "Europe/Zagreb",
+			wantDate:  "2026-05-02",
+		},
+		{
+			name:      "raw start overrides the date",
+			event:     types.Event{Category: "Skirmish", Date: "2026-03-01"},
+			startsRaw: "2026-03-02T10:00",
+			wantStart: "2026-03-02T09:00:00Z",
+			wantEnd:   "2026-03-02T15:00:00Z",
+			wantZone:  "Europe/Zagreb",
+			wantDate:  "2026-03-02",
+		},
+		{name: "unknown time zone", event: types.Event{TimeZone: "Mars/Olympus"}, startsRaw: "2026-03-02T10:00", wantErrMsg: "Invalid time zone"},
+		{name: "bad start", startsRaw: "tomorrow", wantErrMsg: "Invalid start time"},
+		{name: "bad end", startsRaw: "2026-03-02T10:00", endsRaw: "03/02/2026", wantErrMsg: "Invalid end time"},
+		{name: "no start", wantErrMsg: "Start time is required"},
+		{name: "bad date", event: types.Event{Date: "2.3.2026."}, wantErrMsg: "Invalid date (expected YYYY-MM-DD)"},
+		{name: "end before start", startsRaw: "2026-03-02T10:00", endsRaw: "2026-03-02T09:00", wantErrMsg: "End time must be after start time"},
+		{name: "end equals start", startsRaw: "2026-03-02T10:00", endsRaw: "2026-03-02T10:00", wantErrMsg: "End time must be after start time"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := tt.event
+			msg, ok := normalizeEventSchedule(&event, tt.startsRaw, tt.endsRaw)
+			if tt.wantErrMsg != "" {
+				if ok || msg != tt.wantErrMsg {
+					t.Fatalf("got (%q, %v), want (%q, false)", msg, ok, tt.wantErrMsg)
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("unexpected error %q", msg)
+			}
+			if !event.StartsAt.Equal(mustParseUTC(tt.wantStart)) || event.StartsAt.Location() != time.UTC {
+				t.Errorf("StartsAt = %v, want %s", event.StartsAt, tt.wantStart)
+			}
+			if !event.EndsAt.Equal(mustParseUTC(tt.wantEnd)) || event.EndsAt.Location() != time.UTC {
+				t.Errorf("EndsAt = %v, want %s", event.EndsAt, tt.wantEnd)
+			}
+			if event.TimeZone != tt.wantZone {
+				t.Errorf("TimeZone = %q, want %q", event.TimeZone, tt.wantZone)
+			}
+			if event.Date != tt.wantDate {
+				t.Errorf("Date = %q, want %q", event.Date, tt.wantDate)
+			}
+		})
+	}
+}
+
+func TestCarryEventSchedule(t *testing.T) {
+	existing := &types.Event{
+		StartsAt: mustParseUTC("2026-07-04T07:30:00Z"), // 09:30 in Zagreb (CEST)
+		EndsAt:   mustParseUTC("2026-07-04T13:30:00Z"),
+		TimeZone: "Europe/Zagreb",
+	}
+	noEnd := &types.Event{StartsAt: existing.StartsAt, TimeZone: existing.TimeZone}
+
+	tests := []struct {
+		name      string
+		event     types.Event
+		existing  *types.Event
+		startsRaw string
+		endsRaw   string
+		wantStart string // empty: left unset
+		wantEnd   string
+		wantZone  string
+	}{
+		{
+			name:      "keeps local start and length across DST",
+			event:     types.Event{Date: "2026-12-05"},
+			existing:  existing,
+			wantStart: "2026-12-05T08:30:00Z",
+			wantEnd:   "2026-12-05T14:30:00Z",
+			wantZone:  "Europe/Zagreb",
+		},
+		{
+			name:      "new time zone",
+			event:     types.Event{Date: "2026-07-11", TimeZone: "Europe/London"},
+			existing:  existing,
+			wantStart: "2026-07-11T07:30:00Z",
+			wantEnd:   "2026-07-11T13:30:00Z",
+			wantZone:  "Europe/London",
+		},
+		{
+			name:      "existing without end",
+			event:     types.Event{Date: "2026-07-11"},
+			existing:  noEnd,
+			wantStart: "2026-07-11T07:30:00Z",
+			wantZone:  "Europe/Zagreb",
+		},
+		{name: "raw start sent", event: types.Event{Date: "2026-07-11"}, existing: existing, startsRaw: "2026-07-11T10:00"},
+		{name: "raw end sent", event: types.Event{Date: "2026-07-11"}, existing: existing, endsRaw: "2026-07-11T18:00"},
+		{name: "start bound from JSON", event: types.Event{Date: "2026-07-11", StartsAt: mustParseUTC("2026-07-11T06:00:00Z")}, existing: existing, wantStart: "2026-07-11T06:00:00Z"},
+		{name: "existing has no start", event: types.Event{Date: "2026-07-11"}, existing: &types.Event{}},
+		{name: "no date", event: types.Event{}, existing: existing},
+		{name: "bad date", event: types.Event{Date: "11.7.2026."}, existing: existing, wantZone: "Europe/Zagreb"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := tt.event
+			carryEventSchedule(&event, tt.existing, tt.startsRaw, tt.endsRaw)
+			check := func(field string, got time.Time, want string) {
+				if want == "" {
+					if !got.IsZero() {
+						t.Errorf("%s = %v, want unset", field, got)
+					}
+					return
+				}
+				if !got.Equal(mustParseUTC(want)) {
+					t.Errorf("%s = %v, want %s", field, got, want)
+				}
+			}
+			check("StartsAt", event.StartsAt, tt.wantStart)
+			check("EndsAt", event.EndsAt, tt.wantEnd)
+			if event.TimeZone != tt.wantZone {
+				t.Errorf("TimeZone = %q, want %q", event.TimeZone, tt.wantZone)
+			}
+		})
+	}
+}
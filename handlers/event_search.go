@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/MKolega/AirsoftHubCroatia/internal/db"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultSearchLimit   = 20
+	maxSearchLimit       = 50
+	maxSearchQueryLength = 200
+)
+
+var highlightReplacer = strings.NewReplacer(db.HighlightStart, "<mark>", db.HighlightStop, "</mark>")
+
+// highlightHTML escapes ts_headline output and turns its markers into <mark>
+// tags, so clients can render it as HTML.
+func highlightHTML(s string) string {
+	return highlightReplacer.Replace(html.EscapeString(s))
+}
+
+// SearchEventsHandler runs a full-text search (?q=) over approved events,
+// optionally narrowed by from/to/category, best match first.
+func SearchEventsHandler(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing search query"})
+		return
+	}
+	if utf8.RuneCountInString(query) > maxSearchQueryLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Search query is too long"})
+		return
+	}
+
+	from, ok := parseDateParam(c.Query("from"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date (expected YYYY-MM-DD)"})
+		return
+	}
+	to, ok := parseDateParam(c.Query("to"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date (expected YYYY-MM-DD)"})
+		return
+	}
+	category := strings.TrimSpace(c.Query("category"))
+	if category != "" {
+		if _, ok := allowedEventCategories[category]; !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category"})
+			return
+		}
+	}
+
+	limit := defaultSearchLimit
+	if raw := strings.TrimSpace(c.Query("limit")); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+		limit = min(n, maxSearchLimit)
+	}
+
+	results, err := db.SearchEvents(db.EventSearchOptions{
+		Query:    query,
+		From:     from,
+		To:       to,
+		Category: category,
+		Limit:    limit,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search events"})
+		return
+	}
+	for i := range results {
+		results[i].NameHighlight = highlightHTML(results[i].NameHighlight)
+		results[i].Snippet = highlightHTML(results[i].Snippet)
+	}
+	c.JSON(http.StatusOK, results)
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MKolega/AirsoftHubCroatia/types"
+)
+
+func TestNormalizeEventText(t *testing.T) {
+	tests := []struct {
+		name       string
+		event      types.Event
+		wantName   string
+		wantErrMsg string
+	}{
+		{
+			name:     "trims fields",
+			event:    types.Event{Name: "  Night op ", DetailedDescription: " Details "},
+			wantName: "Night op",
+		},
+		{
+			name:       "empty name",
+			event:      types.Event{DetailedDescription: "Details"},
+			wantErrMsg: "Name is required",
+		},
+		{
+			name:       "whitespace name",
+			event:      types.Event{Name: " \t\n", DetailedDescription: "Details"},
+			wantErrMsg: "Name is required",
+		},
+		{
+			name:       "whitespace detailed description",
+			event:      types.Event{Name: "Night op", DetailedDescription: "  "},
+			wantErrMsg: "Detailed description is required",
+		},
+		{
+			name:       "small description too long",
+			event:      types.Event{Name: "Night op", DetailedDescription: "Details", Description: strings.Repeat("ž", 401)},
+			wantErrMsg: "Small description must be 400 characters or less",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := tt.event
+			msg, ok := normalizeEventText(&event)
+			if tt.wantErrMsg != "" {
+				if ok || msg != tt.wantErrMsg {
+					t.Fatalf("got (%q, %v), want error %q", msg, ok, tt.wantErrMsg)
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("unexpected error %q", msg)
+			}
+			if event.Name != tt.wantName {
+				t.Errorf("name = %q, want %q", event.Name, tt.wantName)
+			}
+		})
+	}
+}
@@ -0,0 +1,311 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/MKolega/AirsoftHubCroatia/internal/db"
+	"github.com/MKolega/AirsoftHubCroatia/types"
+	"github.com/gin-gonic/gin"
+)
+
+func parseFactionRequest(c *gin.Context) (types.FactionRequest, bool) {
+	var req types.FactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return req, false
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len(req.Name) > 60 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Faction name is required (max 60 characters)"})
+		return req, false
+	}
+	if req.MaxPlayers < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "max_players must be a positive number"})
+		return req, false
+	}
+	return req, true
+}
+
+func writeFactionError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, db.ErrFactionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Faction not found"})
+	case errors.Is(err, db.ErrFactionNameTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": "A faction with this name already exists"})
+	case errors.Is(err, db.ErrEventNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// EventFactionsHandler lists a published event's factions with player
+// counts, including events whose edit awaits review.
+func EventFactionsHandler(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event id"})
+		return
+	}
+	event, err := db.GetEventByID(id)
+	if err != nil || !event.Published() {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+
+	factions, err := db.GetEventFactions(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch factions"})
+		return
+	}
+	c.JSON(http.StatusOK, factions)
+}
+
+func CreateEventFactionHandler(c *gin.Context) {
+	_, event, ok := requireEventManager(c)
+	if !ok {
+		return
+	}
+	req, ok := parseFactionRequest(c)
+	if !ok {
+		return
+	}
+
+	faction := &types.EventFaction{EventID: event.ID, Name: req.Name, MaxPlayers: req.MaxPlayers}
+	if err := db.CreateEventFaction(faction); err != nil {
+		writeFactionError(c, err, "Failed to create faction")
+		return
+	}
+	c.JSON(http.StatusCreated, faction)
+}
+
+func UpdateEventFactionHandler(c *gin.Context) {
+	_, event, ok := requireEventManager(c)
+	if !ok {
+		return
+	}
+	factionID, err := strconv.Atoi(c.Param("factionId"))
+	if err != nil || factionID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid faction id"})
+		return
+	}
+	req, ok := parseFactionRequest(c)
+	if !ok {
+		return
+	}
+
+	faction := &types.EventFaction{ID: factionID, EventID: event.ID, Name: req.Name, MaxPlayers: req.MaxPlayers}
+	if err := db.UpdateEventFaction(faction); err != nil {
+		writeFactionError(c, err, "Failed to update faction")
+		return
+	}
+	c.JSON(http.StatusOK, faction)
+}
+
+// DeleteEventFactionHandler removes a faction; its players stay registered
+// without a side.
+func DeleteEventFactionHandler(c *gin.Context) {
+	_, event, ok := requireEventManager(c)
+	if !ok {
+		return
+	}
+	factionID, err := strconv.Atoi(c.Param("factionId"))
+	if err != nil || factionID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid faction id"})
+		return
+	}
+
+	if err := db.DeleteEventFaction(event.ID, factionID); err != nil {
+		writeFactionError(c, err, "Failed to delete faction")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// FactionBalanceHandler proposes a balanced split of the registered players
+// without changing anything.
+func FactionBalanceHandler(c *gin.Context) {
+	_, event, ok := requireEventManager(c)
+	if !ok {
+		return
+	}
+	balance, ok := proposeFactionBalance(c, event.ID)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, balance)
+}
+
+// ApplyFactionBalanceHandler computes the same proposal as
+// FactionBalanceHandler and saves it. The proposal is recomputed under the
+// event lock so registrations made meanwhile are not overwritten.
+func ApplyFactionBalanceHandler(c *gin.Context) {
+	_, event, ok := requireEventManager(c)
+	if !ok {
+		return
+	}
+
+	var balance *types.FactionBalance
+	err := db.ApplyFactionBalance(event.ID, func(factions []types.EventFaction, roster []types.RosterEntry) (map[int]int, error) {
+		if len(factions) < 2 {
+			return nil, errTooFewFactions
+		}
+		balance = balanceFactions(factions, roster)
+
+		assignments := map[int]int{}
+		for _, f := range balance.Factions {
+			for _, p := range f.Players {
+				assignments[p.UserID] = f.FactionID
+			}
+		}
+		for _, p := range balance.Unassigned {
+			assignments[p.UserID] = 0
+		}
+		return assignments, nil
+	})
+	if errors.Is(err, errTooFewFactions) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Define at least two factions before balancing"})
+		return
+	}
+	if err != nil {
+		writeFactionError(c, err, "Failed to apply faction balance")
+		return
+	}
+	c.JSON(http.StatusOK, balance)
+}
+
+var errTooFewFactions = errors.New("too few factions")
+
+func proposeFactionBalance(c *gin.Context, eventID int) (*types.FactionBalance, bool) {
+	factions, err := db.GetEventFactions(eventID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch factions"})
+		return nil, false
+	}
+	if len(factions) < 2 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Define at least two factions before balancing"})
+		return nil, false
+	}
+	roster, err := db.GetEventRoster(eventID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch roster"})
+		return nil, false
+	}
+	return balanceFactions(factions, roster), true
+}
+
+// balanceFactions splits players across factions keeping members of the same
+// club together where caps allow. Clubs are placed largest first, each into
+// the faction with the fewest players that still has room; ties prefer the
+// faction most of the club already picked so fewer players are moved. A club
+// too large for any remaining space is split, and players that fit nowhere
+// are left unassigned. Waitlisted players are not moved; the faction they
+// picked still counts against its cap, as it does when registering.
+func balanceFactions(factions []types.EventFaction, roster []types.RosterEntry) *types.FactionBalance {
+	out := &types.FactionBalance{
+		Factions:   make([]types.FactionAssignment, len(factions)),
+		Unassigned: []types.RosterEntry{},
+	}
+	for i, f := range factions {
+		out.Factions[i] = types.FactionAssignment{
+			FactionID:  f.ID,
+			Name:       f.Name,
+			MaxPlayers: f.MaxPlayers,
+			Players:    []types.RosterEntry{},
+		}
+	}
+
+	reserved := map[int]int{}
+	players := make([]types.RosterEntry, 0, len(roster))
+	for _, p := range roster {
+		if p.Status == db.RegistrationWaitlisted {
+			if p.FactionID != 0 {
+				reserved[p.FactionID]++
+			}
+			continue
+		}
+		players = append(players, p)
+	}
+
+	var units [][]types.RosterEntry
+	clubIndex := map[string]int{}
+	for _, p := range players {
+		club := strings.ToLower(strings.TrimSpace(p.AirsoftClub))
+		if club == "" || club == strings.ToLower(types.FreelancerClub) {
+			units = append(units, []types.RosterEntry{p})
+			continue
+		}
+		if i, ok := clubIndex[club]; ok {
+			units[i] = append(units[i], p)
+			continue
+		}
+		clubIndex[club] = len(units)
+		units = append(units, []types.RosterEntry{p})
+	}
+	sort.SliceStable(units, func(i, j int) bool { return len(units[i]) > len(units[j]) })
+
+	room := func(i int) int {
+		if out.Factions[i].MaxPlayers <= 0 {
+			return len(players)
+		}
+		return out.Factions[i].MaxPlayers - reserved[out.Factions[i].FactionID] - len(out.Factions[i].Players)
+	}
+	// pick returns the faction for n players, or -1 when none has room.
+	pick := func(unit []types.RosterEntry, n int) int {
+		best := -1
+		bestPicked := 0
+		for i := range out.Factions {
+			if room(i) < n {
+				continue
+			}
+			picked := 0
+			for _, p := range unit {
+				if p.FactionID == out.Factions[i].FactionID {
+					picked++
+				}
+			}
+			if best < 0 {
+				best, bestPicked = i, picked
+				continue
+			}
+			size, bestSize := len(out.Factions[i].Players), len(out.Factions[best].Players)
+			if size < bestSize || (size == bestSize && picked > bestPicked) {
+				best, bestPicked = i, picked
+			}
+		}
+		return best
+	}
+
+	for _, unit := range units {
+		if i := pick(unit, len(unit)); i >= 0 {
+			out.Factions[i].Players = append(out.Factions[i].Players, unit...)
+			continue
+		}
+		for _, p := range unit {
+			one := []types.RosterEntry{p}
+			if i := pick(one, 1); i >= 0 {
+				out.Factions[i].Players = append(out.Factions[i].Players, p)
+			} else {
+				out.Unassigned = append(out.Unassigned, p)
+			}
+		}
+	}
+
+	for _, f := range out.Factions {
+		for _, p := range f.Players {
+			if p.FactionID != f.FactionID {
+				out.Moves++
+			}
+		}
+	}
+	for _, p := range out.Unassigned {
+		if p.FactionID != 0 {
+			out.Moves++
+		}
+	}
+	return out
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/MKolega/AirsoftHubCroatia/internal/db"
+	"github.com/MKolega/AirsoftHubCroatia/types"
+)
+
+func TestBalanceFactions(t *testing.T) {
+	player := func(id int, club string, factionID int) types.RosterEntry {
+		return types.RosterEntry{UserID: id, AirsoftClub: club, FactionID: factionID}
+	}
+	twoSides := []types.EventFaction{{ID: 10, Name: "Blue"}, {ID: 20, Name: "Red"}}
+	capped := []types.EventFaction{{ID: 10, Name: "Blue", MaxPlayers: 2}, {ID: 20, Name: "Red", MaxPlayers: 2}}
+
+	tests := []struct {
+		name       string
+		factions   []types.EventFaction
+		players    []types.RosterEntry
+		want       map[int][]int // faction ID -> user IDs
+		unassigned []int
+		moves      int
+	}{
+		{
+			name:     "club kept together",
+			factions: twoSides,
+			players: []types.RosterEntry{
+				player(1, "Vukovi", 0),
+				player(2, "", 0),
+				player(3, "Vukovi", 0),
+				player(4, types.FreelancerClub, 0),
+				player(5, "Vukovi", 0),
+			},
+			want:  map[int][]int{10: {1, 3, 5}, 20: {2, 4}},
+			moves: 5,
+		},
+		{
+			name:     "club names match ignoring case and spaces",
+			factions: twoSides,
+			players: []types.RosterEntry{
+				player(1, "Vukovi", 10),
+				player(2, " vukovi ", 10),
+				player(3, "Sokolovi", 20),
+			},
+			want:  map[int][]int{10: {1, 2}, 20: {3}},
+			moves: 0,
+		},
+		{
+			name:     "freelancers are not grouped",
+			factions: twoSides,
+			players: []types.RosterEntry{
+				player(1, types.FreelancerClub, 0),
+				player(2, "no club/freelancer", 0),
+			},
+			want:  map[int][]int{10: {1}, 20: {2}},
+			moves: 2,
+		},
+		{
+			name:     "ties prefer the faction the club picked",
+			factions: twoSides,
+			players: []types.RosterEntry{
+				player(1, "Vukovi", 20),
+				player(2, "Vukovi", 0),
+				player(3, "Sokolovi", 10),
+				player(4, "Sokolovi", 10),
+			},
+			want:  map[int][]int{10: {3, 4}, 20: {1, 2}},
+			moves: 1,
+		},
+		{
+			name:     "largest club placed first",
+			factions: twoSides,
+			players: []types.RosterEntry{
+				player(1, "Sokolovi", 0),
+				player(2, "Vukovi", 0),
+				player(3, "Vukovi", 0),
+				player(4, "Sokolovi", 0),
+				player(5, "Vukovi", 0),
+				player(6, "", 0),
+			},
+			want:  map[int][]int{10: {2, 3, 5}, 20: {1, 4, 6}},
+			moves: 6,
+		},
+		{
+			name:     "club too large for any faction is split",
+			factions: capped,
+			players: []types.RosterEntry{
+				player(1, "Vukovi", 0),
+				player(2, "Vukovi", 0),
+				player(3, "Vukovi", 0),
+			},
+			want:  map[int][]int{10: {1, 3}, 20: {2}},
+			moves: 3,
+		},
+		{
+			name:     "players beyond the caps are unassigned",
+			factions: capped,
+			players: []types.RosterEntry{
+				player(1, "Vukovi", 10),
+				player(2, "Vukovi", 10),
+				player(3, "Sokolovi", 20),
+				player(4, "Sokolovi", 20),
+				player(5, "", 10),
+				player(6, "", 0),
+			},
+			want:       map[int][]int{10: {1, 2}, 20: {3, 4}},
+			unassigned: []int{5, 6},
+			moves:      1,
+		},
+		{
+			name:     "waitlisted players keep their faction's slots",
+			factions: capped,
+			players: []types.RosterEntry{
+				player(1, "Vukovi", 0),
+				player(2, "Vukovi", 0),
+				player(3, "", 0),
+				{UserID: 4, FactionID: 10, Status: db.RegistrationWaitlisted},
+				{UserID: 5, Status: db.RegistrationWaitlisted},
+			},
+			want:  map[int][]int{10: {3}, 20: {1, 2}},
+			moves: 3,
+		},
+		{
+			name:       "no factions",
+			factions:   nil,
+			players:    []types.RosterEntry{player(1, "Vukovi", 10), player(2, "", 0)},
+			want:       map[int][]int{},
+			unassigned: []int{1, 2},
+			moves:      1,
+		},
+	}
+
+	userIDs := func(entries []types.RosterEntry) []int {
+		ids := []int{}
+		for _, e := range entries {
+			ids = append(ids, e.UserID)
+		}
+		return ids
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := balanceFactions(tt.factions, tt.players)
+
+			if len(got.Factions) != len(tt.factions) {
+				t.Fatalf("got %d factions, want %d", len(got.Factions), len(tt.factions))
+			}
+			for i, f := range got.Factions {
+				if f.FactionID != tt.factions[i].ID || f.MaxPlayers != tt.factions[i].MaxPlayers {
+					t.Errorf("faction %d = %d (max %d), want %d (max %d)", i, f.FactionID, f.MaxPlayers, tt.factions[i].ID, tt.factions[i].MaxPlayers)
+				}
+				want := tt.want[f.FactionID]
+				if want == nil {
+					want = []int{}
+				}
+				if ids := userIDs(f.Players); !reflect.DeepEqual(ids, want) {
+					t.Errorf("faction %d players = %v, want %v", f.FactionID, ids, want)
+				}
+			}
+			wantUnassigned := tt.unassigned
+			if wantUnassigned == nil {
+				wantUnassigned = []int{}
+			}
+			if ids := userIDs(got.Unassigned); !reflect.DeepEqual(ids, wantUnassigned) {
+				t.Errorf("unassigned = %v, want %v", ids, wantUnassigned)
+			}
+			if got.Moves != tt.moves {
+				t.Errorf("moves = %d, want %d", got.Moves, tt.moves)
+			}
+		})
+	}
+}
@@ -0,0 +1,433 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/MKolega/AirsoftHubCroatia/internal/db"
+	"github.com/MKolega/AirsoftHubCroatia/internal/storage"
+	"github.com/MKolega/AirsoftHubCroatia/types"
+	"github.com/gin-gonic/gin"
+)
+
+// croatianCounties are the accepted values of Field.County.
+var croatianCounties = []string{
+	"Bjelovarsko-bilogorska", "Brodsko-posavska", "Dubrovačko-neretvanska",
+	"Grad Zagreb", "Istarska", "Karlovačka", "Koprivničko-križevačka",
+	"Krapinsko-zagorska", "Ličko-senjska", "Međimurska", "Osječko-baranjska",
+	"Požeško-slavonska", "Primorsko-goranska", "Sisačko-moslavačka",
+	"Splitsko-dalmatinska", "Šibensko-kninska", "Varaždinska",
+	"Virovitičko-podravska", "Vukovarsko-srijemska", "Zadarska", "Zagrebačka",
+}
+
+var fieldSurfaceTypes = map[string]bool{
+	"woodland": true,
+	"open":     true,
+	"urban":    true,
+	"cqb":      true,
+	"indoor":   true,
+	"mixed":    true,
+}
+
+const (
+	fieldUpcomingEventsLimit = 50
+	fieldPastEventsLimit     = 20
+)
+
+// normalizeCounty returns the canonical spelling of a county, matched
+// case-insensitively. An empty county is allowed.
+func normalizeCounty(raw string) (string, bool) {
+	v := strings.TrimSpace(raw)
+	if v == "" {
+		return "", true
+	}
+	for _, county := range croatianCounties {
+		if strings.EqualFold(county, v) {
+			return county, true
+		}
+	}
+	return "", false
+}
+
+func parseFieldRequest(c *gin.Context) (types.FieldRequest, bool) {
+	var req types.FieldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return req, false
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || utf8.RuneCountInString(req.Name) > 120 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Field name is required (max 120 characters)"})
+		return req, false
+	}
+	if req.Lat < -90 || req.Lat > 90 || req.Lng < -180 || req.Lng > 180 || (req.Lat == 0 && req.Lng == 0) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid coordinates"})
+		return req, false
+	}
+	county, ok := normalizeCounty(req.County)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid county"})
+		return req, false
+	}
+	req.County = county
+	req.SurfaceType = strings.ToLower(strings.TrimSpace(req.SurfaceType))
+	if req.SurfaceType != "" && !fieldSurfaceTypes[req.SurfaceType] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid surface type"})
+		return req, false
+	}
+
+	req.Address = strings.TrimSpace(req.Address)
+	req.OwnerClub = strings.TrimSpace(req.OwnerClub)
+	req.Rules = strings.TrimSpace(req.Rules)
+	req.ParkingNotes = strings.TrimSpace(req.ParkingNotes)
+	switch {
+	case utf8.RuneCountInString(req.Address) > 200:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Address must be 200 characters or less"})
+		return req, false
+	case utf8.RuneCountInString(req.OwnerClub) > 120:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Owner club must be 120 characters or less"})
+		return req, false
+	case utf8.RuneCountInString(req.Rules) > 4000:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Rules must be 4000 characters or less"})
+		return req, false
+	case utf8.RuneCountInString(req.ParkingNotes) > 1000:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parking notes must be 1000 characters or less"})
+		return req, false
+	}
+	return req, true
+}
+
+func applyFieldRequest(field *types.Field, req types.FieldRequest) {
+	field.Name = req.Name
+	field.Lat = req.Lat
+	field.Lng = req.Lng
+	field.Address = req.Address
+	field.County = req.County
+	field.SurfaceType = req.SurfaceType
+	field.OwnerClub = req.OwnerClub
+	field.Rules = req.Rules
+	field.ParkingNotes = req.ParkingNotes
+}
+
+func writeFieldError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, db.ErrFieldNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Field not found"})
+	case errors.Is(err, db.ErrFieldNameTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": "A field with this name already exists"})
+	case errors.Is(err, db.ErrFieldPhotoLimit):
+		c.JSON(http.StatusConflict, gin.H{"error": "A field can have at most 10 photos"})
+	case errors.Is(err, db.ErrFieldPhotoNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Photo not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+func fieldIDParam(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid field id"})
+		return 0, false
+	}
+	return id, true
+}
+
+// canManageField lets moderators manage every field and creators edit their
+// own until it is approved; approved fields are maintained by moderators.
+func canManageField(user *types.User, field *types.Field) bool {
+	if user.Can(types.PermManageFields) {
+		return true
+	}
+	return field.Status != "approved" && normalizeEmail(field.CreatorEmail) == normalizeEmail(user.Email)
+}
+
+// requireFieldManager loads the field from the :id param and allows users
+// who may manage it through.
+func requireFieldManager(c *gin.Context) (*types.User, *types.Field, bool) {
+	email, ok := emailFromAuthHeader(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return nil, nil, false
+	}
+	user, err := db.GetUserByEmail(email)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return nil, nil, false
+	}
+	id, ok := fieldIDParam(c)
+	if !ok {
+		return nil, nil, false
+	}
+	field, err := db.GetFieldByID(id)
+	if err != nil {
+		writeFieldError(c, err, "Failed to fetch field")
+		return nil, nil, false
+	}
+	if !canManageField(user, field) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only moderators can change an approved field"})
+		return nil, nil, false
+	}
+	return user, field, true
+}
+
+func canSeeUnpublishedField(c *gin.Context, field *types.Field) bool {
+	email, ok := emailFromAuthHeader(c)
+	if !ok {
+		return false
+	}
+	if strings.EqualFold(email, field.CreatorEmail) {
+		return true
+	}
+	user, err := db.GetUserByEmail(email)
+	if err != nil {
+		return false
+	}
+	return user.Can(types.PermManageFields)
+}
+
+// applyEventField copies the name and coordinates of the event's field onto
+// the event. Only approved fields can be linked; FieldID 0 leaves the event
+// unlinked.
+func applyEventField(c *gin.Context, event *types.Event) bool {
+	if event.FieldID == 0 {
+		return true
+	}
+	field, err := db.GetFieldByID(event.FieldID)
+	if err != nil && !errors.Is(err, db.ErrFieldNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch field"})
+		return false
+	}
+	if err != nil || field.Status != "approved" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown field"})
+		return false
+	}
+	event.Location = field.Name
+	event.Lat = field.Lat
+	event.Lng = field.Lng
+	return true
+}
+
+// FieldsHandler lists approved fields, optionally filtered by ?q= (name or
+// address) and ?county=.
+func FieldsHandler(c *gin.Context) {
+	county, ok := normalizeCounty(c.Query("county"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid county"})
+		return
+	}
+	fields, err := db.ListFields(c.Query("q"), county)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch fields"})
+		return
+	}
+	c.JSON(http.StatusOK, fields)
+}
+
+// FieldDetailHandler returns a field with its upcoming and past events.
+// Fields that are not approved are only visible to their creator and to
+// moderators.
+func FieldDetailHandler(c *gin.Context) {
+	id, ok := fieldIDParam(c)
+	if !ok {
+		return
+	}
+	field, err := db.GetFieldByID(id)
+	if err != nil {
+		writeFieldError(c, err, "Failed to fetch field")
+		return
+	}
+	if field.Status != "approved" && !canSeeUnpublishedField(c, field) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Field not found"})
+		return
+	}
+
+	upcoming, err := db.GetFieldEvents(id, true, fieldUpcomingEventsLimit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch field events"})
+		return
+	}
+	past, err := db.GetFieldEvents(id, false, fieldPastEventsLimit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch field events"})
+		return
+	}
+	c.JSON(http.StatusOK, types.FieldDetail{Field: *field, UpcomingEvents: upcoming, PastEvents: past})
+}
+
+// CreateFieldHandler submits a new field. Fields from moderators are
+// published right away; others wait for review.
+func CreateFieldHandler(c *gin.Context) {
+	email, ok := emailFromAuthHeader(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Sign in required to add fields"})
+		return
+	}
+	user, err := db.GetUserByEmail(email)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+	if user.EmailVerifiedAt.IsZero() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Verify your email address before adding fields"})
+		return
+	}
+	req, ok := parseFieldRequest(c)
+	if !ok {
+		return
+	}
+
+	field := &types.Field{Status: "pending", CreatorEmail: user.Email}
+	if user.Can(types.PermManageFields) {
+		field.Status = "approved"
+	}
+	applyFieldRequest(field, req)
+	if err := db.CreateField(field); err != nil {
+		writeFieldError(c, err, "Failed to create field")
+		return
+	}
+	c.JSON(http.StatusCreated, field)
+}
+
+// UpdateFieldHandler edits a field. A creator's edit sends a rejected field
+// back to the review queue.
+func UpdateFieldHandler(c *gin.Context) {
+	user, field, ok := requireFieldManager(c)
+	if !ok {
+		return
+	}
+	req, ok := parseFieldRequest(c)
+	if !ok {
+		return
+	}
+
+	applyFieldRequest(field, req)
+	var extra []string
+	if !user.Can(types.PermManageFields) {
+		field.Status = "pending"
+		field.RejectionReason = ""
+		extra = append(extra, "status", "rejection_reason")
+	}
+	if err := db.UpdateField(field, extra...); err != nil {
+		writeFieldError(c, err, "Failed to update field")
+		return
+	}
+	c.JSON(http.StatusOK, field)
+}
+
+// DeleteFieldHandler removes a field and its photos. Linked events keep their
+// copied location.
+func DeleteFieldHandler(c *gin.Context) {
+	_, field, ok := requireFieldManager(c)
+	if !ok {
+		return
+	}
+	keys, err := db.FieldStoredObjectKeys(field.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete field"})
+		return
+	}
+	if err := db.DeleteField(field.ID); err != nil {
+		writeFieldError(c, err, "Failed to delete field")
+		return
+	}
+	discardObjects(keys)
+	c.Status(http.StatusNoContent)
+}
+
+// UploadFieldPhotoHandler appends an image (multipart field "file") to the
+// field's photos.
+func UploadFieldPhotoHandler(c *gin.Context) {
+	_, field, ok := requireFieldManager(c)
+	if !ok {
+		return
+	}
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing file"})
+		return
+	}
+
+	photo, err := storage.UploadFieldPhoto(c.Request.Context(), fileHeader)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if storage.IsClientUploadError(err) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	keys, err := trackUploads(db.ObjectKindFieldPhoto, []storage.Object{*photo})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store photo"})
+		return
+	}
+
+	updated, err := db.AddFieldPhoto(field.ID, photo.URL, photo.Key)
+	if err != nil {
+		discardObjects(keys)
+		writeFieldError(c, err, "Failed to save photo")
+		return
+	}
+	c.JSON(http.StatusCreated, updated)
+}
+
+// DeleteFieldPhotoHandler removes the photo at position :photo (0-based).
+func DeleteFieldPhotoHandler(c *gin.Context) {
+	_, field, ok := requireFieldManager(c)
+	if !ok {
+		return
+	}
+	index, err := strconv.Atoi(c.Param("photo"))
+	if err != nil || index < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid photo"})
+		return
+	}
+
+	updated, keys, err := db.RemoveFieldPhoto(field.ID, index)
+	if err != nil {
+		writeFieldError(c, err, "Failed to delete photo")
+		return
+	}
+	discardObjects(keys)
+	c.JSON(http.StatusOK, updated)
+}
+
+func AdminPendingFieldsHandler(c *gin.Context) {
+	fields, err := db.GetPendingFields()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch pending fields"})
+		return
+	}
+	c.JSON(http.StatusOK, fields)
+}
+
+func AdminApproveFieldHandler(c *gin.Context) {
+	id, ok := fieldIDParam(c)
+	if !ok {
+		return
+	}
+	if err := db.ReviewField(id, "approved", currentUser(c).Email, ""); err != nil {
+		writeFieldError(c, err, "Failed to approve field")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func AdminRejectFieldHandler(c *gin.Context) {
+	id, ok := fieldIDParam(c)
+	if !ok {
+		return
+	}
+	var req types.AdminRejectRequest
+	_ = c.ShouldBindJSON(&req)
+
+	if err := db.ReviewField(id, "rejected", currentUser(c).Email, req.Reason); err != nil {
+		writeFieldError(c, err, "Failed to reject field")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
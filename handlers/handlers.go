@@ -1,10 +1,14 @@
 package handlers
 
 import (
+	"errors"
+	"math"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
 	"unicode/utf8"
 
 	"github.com/MKolega/AirsoftHubCroatia/internal/db"
@@ -21,10 +25,12 @@ func dayBounds(t time.Time) (time.Time, time.Time) {
 	return start, end
 }
 
-var allowedEventCategories = map[string]struct{}{
-	"24h":      {},
-	"12h":      {},
-	"Skirmish": {},
+// allowedEventCategories maps each category to the event length assumed when
+// a client does not send an end time.
+var allowedEventCategories = map[string]time.Duration{
+	"24h":      24 * time.Hour,
+	"12h":      12 * time.Hour,
+	"Skirmish": 6 * time.Hour,
 }
 
 func normalizeCategory(raw string) (string, bool) {
@@ -36,17 +42,291 @@ func normalizeCategory(raw string) (string, bool) {
 	return cat, ok
 }
 
+// parseMaxPlayers reads the optional player cap; empty or 0 means unlimited.
+func parseMaxPlayers(raw string) (int, bool) {
+	v := strings.TrimSpace(raw)
+	if v == "" {
+		return 0, true
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return n, true
+}
+
+// normalizeFacebookLink checks the optional event link is an absolute
+// http(s) URL; an empty value is allowed.
+func normalizeFacebookLink(raw string) (string, bool) {
+	v := strings.TrimSpace(raw)
+	if v == "" {
+		return "", true
+	}
+	if strings.ContainsFunc(v, unicode.IsControl) {
+		return "", false
+	}
+	u, err := url.Parse(v)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", false
+	}
+	return v, true
+}
+
+// normalizeEventText trims the event's name and descriptions and checks the
+// required ones are set. Multipart and JSON submissions both go through it.
+func normalizeEventText(event *types.Event) (string, bool) {
+	event.Name = strings.TrimSpace(event.Name)
+	event.Description = strings.TrimSpace(event.Description)
+	event.DetailedDescription = strings.TrimSpace(event.DetailedDescription)
+	switch {
+	case event.Name == "":
+		return "Name is required", false
+	case event.DetailedDescription == "":
+		return "Detailed description is required", false
+	case utf8.RuneCountInString(event.Description) > 400:
+		return "Small description must be 400 characters or less", false
+	}
+	return "", true
+}
+
+// parseOptionalID reads an optional id (e.g. fieldId) of a multipart event
+// form; an empty value is 0.
+func parseOptionalID(raw string) (int, bool) {
+	v := strings.TrimSpace(raw)
+	if v == "" {
+		return 0, true
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return n, true
+}
+
+// parseEditedInt parses the form value of key for an event edit. Forms that
+// do not send key at all keep current, so older clients don't clear it.
+func parseEditedInt(c *gin.Context, key string, current int, parse func(string) (int, bool)) (int, bool) {
+	raw, sent := c.GetPostForm(key)
+	if !sent {
+		return current, true
+	}
+	return parse(raw)
+}
+
 func HomeHandler(c *gin.Context) {
 	c.String(http.StatusOK, "Welcome to the Airsoft Hub Croatia")
 
 }
 
+const (
+	defaultEventsPageSize = 50
+	maxEventsPageSize     = 200
+)
+
+func parseDateParam(raw string) (string, bool) {
+	v := strings.TrimSpace(raw)
+	if v == "" {
+		return "", true
+	}
+	if _, err := time.Parse("2006-01-02", v); err != nil {
+		return "", false
+	}
+	return v, true
+}
+
+// EventsHandler lists approved events. Filters: from, to (YYYY-MM-DD),
+// category, q, sort. Passing limit or cursor switches the response to a
+// paged envelope with next_cursor; otherwise a plain array is returned.
 func EventsHandler(c *gin.Context) {
-	events, err := db.GetEventsFromDB()
+	from, ok := parseDateParam(c.Query("from"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date (expected YYYY-MM-DD)"})
+		return
+	}
+	to, ok := parseDateParam(c.Query("to"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date (expected YYYY-MM-DD)"})
+		return
+	}
+	if from != "" && to != "" && from > to {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must not be after to"})
+		return
+	}
+
+	category := strings.TrimSpace(c.Query("category"))
+	if category != "" {
+		if _, ok := allowedEventCategories[category]; !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category"})
+			return
+		}
+	}
+
+	sort := strings.TrimSpace(c.Query("sort"))
+	if sort != "" && !db.ValidEventSort(sort) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sort"})
+		return
+	}
+
+	limitStr, hasLimit := c.GetQuery("limit")
+	cursor, hasCursor := c.GetQuery("cursor")
+	paged := hasLimit || hasCursor
+
+	opts := db.EventListOptions{
+		From:     from,
+		To:       to,
+		Category: category,
+		Query:    c.Query("q"),
+		Sort:     sort,
+		Cursor:   cursor,
+	}
+	if paged {
+		opts.Limit = defaultEventsPageSize
+		if strings.TrimSpace(limitStr) != "" {
+			limit, err := strconv.Atoi(strings.TrimSpace(limitStr))
+			if err != nil || limit <= 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+				return
+			}
+			if limit > maxEventsPageSize {
+				limit = maxEventsPageSize
+			}
+			opts.Limit = limit
+		}
+	}
+
+	events, nextCursor, err := db.ListEvents(opts)
 	if err != nil {
+		if errors.Is(err, db.ErrInvalidEventCursor) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch events"})
 		return
 	}
+
+	if !paged {
+		c.JSON(http.StatusOK, events)
+		return
+	}
+
+	var next any
+	if nextCursor != "" {
+		next = nextCursor
+	}
+	c.JSON(http.StatusOK, gin.H{"events": events, "next_cursor": next})
+}
+
+const (
+	defaultNearbyRadiusKm = 50
+	maxNearbyRadiusKm     = 500
+	defaultNearbyLimit    = 100
+	maxNearbyLimit        = 500
+)
+
+func parseCoordinate(raw string, max float64) (float64, bool) {
+	v, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+	if err != nil || math.IsNaN(v) || v < -max || v > max {
+		return 0, false
+	}
+	return v, true
+}
+
+// parseBBox parses "minLng,minLat,maxLng,maxLat" (the GeoJSON/Leaflet order).
+func parseBBox(raw string) (*db.BoundingBox, bool) {
+	parts := strings.Split(raw, ",")
+	if len(parts) != 4 {
+		return nil, false
+	}
+	minLng, ok1 := parseCoordinate(parts[0], 180)
+	minLat, ok2 := parseCoordinate(parts[1], 90)
+	maxLng, ok3 := parseCoordinate(parts[2], 180)
+	maxLat, ok4 := parseCoordinate(parts[3], 90)
+	if !ok1 || !ok2 || !ok3 || !ok4 || minLat > maxLat || minLng > maxLng {
+		return nil, false
+	}
+	return &db.BoundingBox{MinLat: minLat, MinLng: minLng, MaxLat: maxLat, MaxLng: maxLng}, true
+}
+
+// NearbyEventsHandler finds approved events around lat/lng within radius_km,
+// or inside bbox when given, ordered by distance.
+func NearbyEventsHandler(c *gin.Context) {
+	opts := db.NearbyOptions{Limit: defaultNearbyLimit}
+
+	if raw := strings.TrimSpace(c.Query("bbox")); raw != "" {
+		box, ok := parseBBox(raw)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid bbox (expected minLng,minLat,maxLng,maxLat)"})
+			return
+		}
+		opts.BBox = box
+		opts.Lat = (box.MinLat + box.MaxLat) / 2
+		opts.Lng = (box.MinLng + box.MaxLng) / 2
+	}
+
+	latStr, lngStr := c.Query("lat"), c.Query("lng")
+	if opts.BBox == nil || latStr != "" || lngStr != "" {
+		lat, ok := parseCoordinate(latStr, 90)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid lat"})
+			return
+		}
+		lng, ok := parseCoordinate(lngStr, 180)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid lng"})
+			return
+		}
+		opts.Lat, opts.Lng = lat, lng
+	}
+
+	if opts.BBox == nil {
+		opts.RadiusKm = defaultNearbyRadiusKm
+		if raw := strings.TrimSpace(c.Query("radius_km")); raw != "" {
+			r, err := strconv.ParseFloat(raw, 64)
+			if err != nil || math.IsNaN(r) || r <= 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid radius_km"})
+				return
+			}
+			opts.RadiusKm = math.Min(r, maxNearbyRadiusKm)
+		}
+	}
+
+	from, ok := parseDateParam(c.Query("from"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date (expected YYYY-MM-DD)"})
+		return
+	}
+	to, ok := parseDateParam(c.Query("to"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date (expected YYYY-MM-DD)"})
+		return
+	}
+	opts.From, opts.To = from, to
+
+	opts.Category = strings.TrimSpace(c.Query("category"))
+	if opts.Category != "" {
+		if _, ok := allowedEventCategories[opts.Category]; !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category"})
+			return
+		}
+	}
+
+	if raw := strings.TrimSpace(c.Query("limit")); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+		if limit > maxNearbyLimit {
+			limit = maxNearbyLimit
+		}
+		opts.Limit = limit
+	}
+
+	events, err := db.GetNearbyEvents(opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch nearby events"})
+		return
+	}
 	c.JSON(http.StatusOK, events)
 }
 
@@ -78,76 +358,104 @@ func CreateEventHandler(c *gin.Context) {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
 		return
 	}
+	if user.EmailVerifiedAt.IsZero() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Verify your email address before creating events"})
+		return
+	}
 	status := "pending"
-	if user.IsAdmin {
+	if user.Can(types.PermPublishDirectly) {
 		status = "approved"
 	}
-	start, end := dayBounds(time.Now())
-	count, err := db.CountEventsByCreatorInRange(creatorEmail, start, end)
+	now := time.Now()
+	quota, err := eventQuotaFor(user, now)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate daily limit"})
 		return
 	}
-	if count >= 2 {
-		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Daily limit reached (2 events per day)"})
+	if !quota.Unlimited && quota.Remaining == 0 {
+		writeEventQuotaExceeded(c, quota)
 		return
 	}
 
 	if strings.Contains(contentType, "multipart/form-data") {
-		name := strings.TrimSpace(c.PostForm("name"))
-		if name == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Name is required"})
+		category, ok := normalizeCategory(c.PostForm("category"))
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category"})
 			return
 		}
-
-		detailed := strings.TrimSpace(c.PostForm("detailedDescription"))
-		if detailed == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Detailed description is required"})
+		fieldID, ok := parseOptionalID(c.PostForm("fieldId"))
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid field"})
 			return
 		}
-
-		description := strings.TrimSpace(c.PostForm("description"))
-		if utf8.RuneCountInString(description) > 400 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Small description must be 400 characters or less"})
+		clubID, ok := parseOptionalID(c.PostForm("clubId"))
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid club"})
 			return
 		}
+		// A linked field supplies the location and coordinates.
+		var lat, lng float64
+		if fieldID == 0 {
+			latStr := strings.TrimSpace(c.PostForm("lat"))
+			lngStr := strings.TrimSpace(c.PostForm("lng"))
+			var err error
+			lat, err = strconv.ParseFloat(latStr, 64)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid lat"})
+				return
+			}
+			lng, err = strconv.ParseFloat(lngStr, 64)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid lng"})
+				return
+			}
+		}
 
-		category, ok := normalizeCategory(c.PostForm("category"))
+		maxPlayers, ok := parseMaxPlayers(c.PostForm("maxPlayers"))
 		if !ok {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid max players"})
 			return
 		}
-		latStr := strings.TrimSpace(c.PostForm("lat"))
-		lngStr := strings.TrimSpace(c.PostForm("lng"))
-		lat, err := strconv.ParseFloat(latStr, 64)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid lat"})
-			return
-		}
-		lng, err := strconv.ParseFloat(lngStr, 64)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid lng"})
+		facebookLink, ok := normalizeFacebookLink(c.PostForm("facebookLink"))
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Facebook link"})
 			return
 		}
 
 		event := types.Event{
 			Status:              status,
-			Name:                name,
-			Description:         description,
-			DetailedDescription: detailed,
+			Name:                c.PostForm("name"),
+			Description:         c.PostForm("description"),
+			DetailedDescription: c.PostForm("detailedDescription"),
 			CreatorEmail:        creatorEmail,
 			Location:            c.PostForm("location"),
 			Date:                c.PostForm("date"),
+			TimeZone:            c.PostForm("timeZone"),
 			Lat:                 lat,
 			Lng:                 lng,
 			Category:            category,
-			FacebookLink:        c.PostForm("facebookLink"),
+			FacebookLink:        facebookLink,
+			MaxPlayers:          maxPlayers,
+			FieldID:             fieldID,
+			ClubID:              clubID,
+		}
+		if msg, ok := normalizeEventText(&event); !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+			return
+		}
+		if !applyEventField(c, &event) || !applyEventClub(c, user, nil, &event) {
+			return
+		}
+		if msg, ok := normalizeEventSchedule(&event, c.PostForm("startsAt"), c.PostForm("endsAt")); !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+			return
 		}
 
+		var thumbnailKeys []string
 		fileHeader, err := c.FormFile("thumbnail")
 		if err == nil && fileHeader != nil {
 			const maxSize = 5 << 20 // 5 MiB
-			url, err := storage.UploadThumbnail(c.Request.Context(), fileHeader, maxSize)
+			thumb, err := storage.UploadThumbnail(c.Request.Context(), fileHeader, maxSize)
 			if err != nil {
 				status := http.StatusInternalServerError
 				if storage.IsClientUploadError(err) {
@@ -156,13 +464,20 @@ func CreateEventHandler(c *gin.Context) {
 				c.JSON(status, gin.H{"error": err.Error()})
 				return
 			}
-			event.Thumbnail = url
+			if thumbnailKeys, err = trackUploads(db.ObjectKindThumbnail, thumb.Objects); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store thumbnail"})
+				return
+			}
+			setEventThumbnail(&event, thumb)
 		}
 
-		if err := db.InsertEventToDB(&event); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create event"})
+		if !insertEventWithinQuota(c, user, &event, now) {
+			discardObjects(thumbnailKeys)
 			return
 		}
+		if thumbnailKeys != nil {
+			attachEventThumbnail(event.ID, thumbnailKeys)
+		}
 		c.JSON(http.StatusCreated, event)
 		return
 	}
@@ -182,64 +497,161 @@ func CreateEventHandler(c *gin.Context) {
 		return
 	}
 
-	event.Description = strings.TrimSpace(event.Description)
-	event.DetailedDescription = strings.TrimSpace(event.DetailedDescription)
-	if event.DetailedDescription == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Detailed description is required"})
-		return
-	}
-	if utf8.RuneCountInString(event.Description) > 400 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Small description must be 400 characters or less"})
+	if msg, ok := normalizeEventText(&event); !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
 		return
 	}
 
 	event.Category = category
+	if event.MaxPlayers < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid max players"})
+		return
+	}
+	if event.FacebookLink, ok = normalizeFacebookLink(event.FacebookLink); !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Facebook link"})
+		return
+	}
+	if msg, ok := normalizeEventSchedule(&event, "", ""); !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	if !applyEventField(c, &event) || !applyEventClub(c, user, nil, &event) {
+		return
+	}
 	event.CreatorEmail = creatorEmail
 	event.Status = status
-	if err := db.InsertEventToDB(&event); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create event"})
+	event.ThumbnailVariants, event.ThumbnailBlurhash, event.ThumbnailColor = nil, "", ""
+	if !insertEventWithinQuota(c, user, &event, now) {
 		return
 	}
 	c.JSON(http.StatusCreated, event)
 }
 
-func requireAdmin(c *gin.Context) (string, bool) {
+// eventThumbnailColumns are written together whenever the thumbnail changes.
+var eventThumbnailColumns = []string{"thumbnail", "thumbnail_variants", "thumbnail_blurhash", "thumbnail_color"}
+
+func setEventThumbnail(event *types.Event, thumb *storage.Thumbnail) {
+	event.Thumbnail = thumb.URL
+	event.ThumbnailVariants = thumb.Variants
+	event.ThumbnailBlurhash = thumb.Blurhash
+	event.ThumbnailColor = thumb.Color
+}
+
+// requireEventManager loads the event from the :id param and allows users
+// who may manage it through.
+func requireEventManager(c *gin.Context) (*types.User, *types.Event, bool) {
 	email, ok := emailFromAuthHeader(c)
 	if !ok {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return "", false
+		return nil, nil, false
 	}
 	user, err := db.GetUserByEmail(email)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
-		return "", false
+		return nil, nil, false
 	}
-	if !user.IsAdmin {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Admin only"})
-		return "", false
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event id"})
+		return nil, nil, false
+	}
+	event, err := db.GetEventByID(id)
+	if err != nil {
+		if errors.Is(err, db.ErrEventNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return nil, nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch event"})
+		return nil, nil, false
 	}
-	return email, true
+
+	if !canManageEvent(user, event) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the event's organizers or a moderator can do this"})
+		return nil, nil, false
+	}
+	return user, event, true
 }
 
-func AdminPendingReviewEventsHandler(c *gin.Context) {
-	_, ok := requireAdmin(c)
-	if !ok {
-		return
+// canManageEvent allows the event's creator, moderators and the officers
+// and owners of the club the event is published for.
+func canManageEvent(user *types.User, event *types.Event) bool {
+	if user.Can(types.PermManageAnyEvent) || normalizeEmail(event.CreatorEmail) == normalizeEmail(user.Email) {
+		return true
+	}
+	if event.ClubID == 0 {
+		return false
 	}
+	role, err := db.GetClubRole(event.ClubID, user.ID)
+	return err == nil && clubRoleRank[role] >= clubRoleRank[db.ClubRoleOfficer]
+}
 
+// resubmitForReview sends an edit back through moderation unless the editor
+// may publish directly. For an event that was already approved the approved
+// version is kept in previous_version so reviewers can see what changed.
+func resubmitForReview(user *types.User, existing *types.Event, event *types.Event, columns []string) []string {
+	if user.Can(types.PermPublishDirectly) {
+		return columns
+	}
+	event.Status = "pending"
+	event.RejectionReason = ""
+	columns = append(columns, "status", "rejection_reason")
+
+	if existing.Status == "approved" && existing.PreviousVersion == nil {
+		prev := *existing
+		prev.PreviousVersion = nil
+		event.PreviousVersion = &prev
+		columns = append(columns, "previous_version")
+	}
+	return columns
+}
+
+// eventChanges lists the user-editable fields that differ between before and after.
+func eventChanges(before *types.Event, after *types.Event) []types.EventFieldChange {
+	var changes []types.EventFieldChange
+	add := func(field string, from, to any) {
+		if from != to {
+			changes = append(changes, types.EventFieldChange{Field: field, From: from, To: to})
+		}
+	}
+	add("name", before.Name, after.Name)
+	add("starts_at", before.StartsAt.UTC(), after.StartsAt.UTC())
+	add("ends_at", before.EndsAt.UTC(), after.EndsAt.UTC())
+	add("time_zone", before.TimeZone, after.TimeZone)
+	add("description", before.Description, after.Description)
+	add("detailed_description", before.DetailedDescription, after.DetailedDescription)
+	add("location", before.Location, after.Location)
+	add("lat", before.Lat, after.Lat)
+	add("lng", before.Lng, after.Lng)
+	add("field_id", before.FieldID, after.FieldID)
+	add("club_id", before.ClubID, after.ClubID)
+	add("category", before.Category, after.Category)
+	add("facebook_link", before.FacebookLink, after.FacebookLink)
+	add("max_players", before.MaxPlayers, after.MaxPlayers)
+	add("thumbnail", before.Thumbnail, after.Thumbnail)
+	return changes
+}
+
+func AdminPendingReviewEventsHandler(c *gin.Context) {
 	events, err := db.GetPendingEventsFromDB()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch pending events"})
 		return
 	}
-	c.JSON(http.StatusOK, events)
+
+	pending := make([]types.PendingEvent, 0, len(events))
+	for i := range events {
+		p := types.PendingEvent{Event: events[i]}
+		if prev := events[i].PreviousVersion; prev != nil {
+			p.Changes = eventChanges(prev, &events[i])
+		}
+		pending = append(pending, p)
+	}
+	c.JSON(http.StatusOK, pending)
 }
 
 func AdminApproveEventHandler(c *gin.Context) {
-	adminEmail, ok := requireAdmin(c)
-	if !ok {
-		return
-	}
+	reviewerEmail := currentUser(c).Email
 
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil || id <= 0 {
@@ -247,18 +659,21 @@ func AdminApproveEventHandler(c *gin.Context) {
 		return
 	}
 
-	if err := db.ReviewEvent(id, "approved", adminEmail, nil); err != nil {
+	if err := db.ReviewEvent(id, "approved", reviewerEmail, nil); err != nil {
+		if errors.Is(err, db.ErrEventNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to approve event"})
 		return
 	}
+	// The approved version's thumbnail is stale once its edit is published.
+	releaseStaleThumbnails(id)
 
 	c.Status(http.StatusNoContent)
 }
 func AdminRejectEventHandler(c *gin.Context) {
-	adminEmail, ok := requireAdmin(c)
-	if !ok {
-		return
-	}
+	reviewerEmail := currentUser(c).Email
 
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil || id <= 0 {
@@ -269,77 +684,117 @@ func AdminRejectEventHandler(c *gin.Context) {
 	var req types.AdminRejectRequest
 	_ = c.ShouldBindJSON(&req)
 
-	if err := db.ReviewEvent(id, "rejected", adminEmail, &req.Reason); err != nil {
+	if err := db.ReviewEvent(id, "rejected", reviewerEmail, &req.Reason); err != nil {
+		if errors.Is(err, db.ErrEventNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reject event"})
 		return
 	}
+	// A rejected edit of an approved event leaves the thumbnail it uploaded
+	// unused.
+	releaseStaleThumbnails(id)
 
 	c.Status(http.StatusNoContent)
 }
 
 func UpdateEventHandler(c *gin.Context) {
-	_, ok := requireAdmin(c)
+	user, existing, ok := requireEventManager(c)
 	if !ok {
 		return
 	}
 
-	id := c.Param("id")
+	id := strconv.Itoa(existing.ID)
 	contentType := c.GetHeader("Content-Type")
 	if strings.Contains(contentType, "multipart/form-data") {
-		name := strings.TrimSpace(c.PostForm("name"))
-		if name == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Name is required"})
-			return
-		}
-
 		category, ok := normalizeCategory(c.PostForm("category"))
 		if !ok {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category"})
 			return
 		}
-		latStr := strings.TrimSpace(c.PostForm("lat"))
-		lngStr := strings.TrimSpace(c.PostForm("lng"))
-		lat, err := strconv.ParseFloat(latStr, 64)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid lat"})
+		fieldID, ok := parseEditedInt(c, "fieldId", existing.FieldID, parseOptionalID)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid field"})
 			return
 		}
-		lng, err := strconv.ParseFloat(lngStr, 64)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid lng"})
+		clubID, ok := parseEditedInt(c, "clubId", existing.ClubID, parseOptionalID)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid club"})
 			return
 		}
+		// A linked field supplies the location and coordinates.
+		var lat, lng float64
+		if fieldID == 0 {
+			latStr := strings.TrimSpace(c.PostForm("lat"))
+			lngStr := strings.TrimSpace(c.PostForm("lng"))
+			var err error
+			lat, err = strconv.ParseFloat(latStr, 64)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid lat"})
+				return
+			}
+			lng, err = strconv.ParseFloat(lngStr, 64)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid lng"})
+				return
+			}
+		}
 
-		detailed := strings.TrimSpace(c.PostForm("detailedDescription"))
-		if detailed == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Detailed description is required"})
+		maxPlayers, ok := parseEditedInt(c, "maxPlayers", existing.MaxPlayers, parseMaxPlayers)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid max players"})
 			return
 		}
-
-		description := strings.TrimSpace(c.PostForm("description"))
-		if utf8.RuneCountInString(description) > 400 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Small description must be 400 characters or less"})
+		facebookLink, ok := normalizeFacebookLink(c.PostForm("facebookLink"))
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Facebook link"})
 			return
 		}
 
+		// An omitted time zone keeps the event's, so a new start time is
+		// read in the zone it was entered in.
+		timeZone, ok := c.GetPostForm("timeZone")
+		if !ok {
+			timeZone = existing.TimeZone
+		}
+
 		event := types.Event{
-			Name:                name,
-			Description:         description,
-			DetailedDescription: detailed,
+			Name:                c.PostForm("name"),
+			Description:         c.PostForm("description"),
+			DetailedDescription: c.PostForm("detailedDescription"),
 			Location:            c.PostForm("location"),
 			Date:                c.PostForm("date"),
+			TimeZone:            timeZone,
 			Lat:                 lat,
 			Lng:                 lng,
 			Category:            category,
-			FacebookLink:        c.PostForm("facebookLink"),
+			FacebookLink:        facebookLink,
+			MaxPlayers:          maxPlayers,
+			FieldID:             fieldID,
+			ClubID:              clubID,
+		}
+		if msg, ok := normalizeEventText(&event); !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+			return
+		}
+		if !applyEventField(c, &event) || !applyEventClub(c, user, existing, &event) {
+			return
+		}
+		startsRaw, endsRaw := c.PostForm("startsAt"), c.PostForm("endsAt")
+		carryEventSchedule(&event, existing, startsRaw, endsRaw)
+		if msg, ok := normalizeEventSchedule(&event, startsRaw, endsRaw); !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+			return
 		}
 
-		columns := []string{"name", "description", "detailed_description", "location", "date", "lat", "lng", "category", "facebook_link"}
+		columns := []string{"name", "description", "detailed_description", "location", "starts_at", "ends_at", "time_zone", "lat", "lng", "category", "facebook_link", "max_players", "field_id", "club_id"}
 
+		var thumbnailKeys []string
 		fileHeader, err := c.FormFile("thumbnail")
 		if err == nil && fileHeader != nil {
 			const maxSize = 5 << 20 // 5 MiB
-			url, err := storage.UploadThumbnail(c.Request.Context(), fileHeader, maxSize)
+			thumb, err := storage.UploadThumbnail(c.Request.Context(), fileHeader, maxSize)
 			if err != nil {
 				status := http.StatusInternalServerError
 				if storage.IsClientUploadError(err) {
@@ -348,19 +803,29 @@ func UpdateEventHandler(c *gin.Context) {
 				c.JSON(status, gin.H{"error": err.Error()})
 				return
 			}
-			event.Thumbnail = url
-			columns = append(columns, "thumbnail")
+			if thumbnailKeys, err = trackUploads(db.ObjectKindThumbnail, thumb.Objects); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store thumbnail"})
+				return
+			}
+			setEventThumbnail(&event, thumb)
+			columns = append(columns, eventThumbnailColumns...)
 		}
 
-		if err := db.UpdateEventInDBColumns(id, &event, columns...); err != nil {
+		columns = resubmitForReview(user, existing, &event, columns)
+		if err := db.UpdateEventInDBColumns(id, user.Email, &event, columns...); err != nil {
+			discardObjects(thumbnailKeys)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update event", "details": err.Error()})
 			return
 		}
+		if thumbnailKeys != nil {
+			attachEventThumbnail(existing.ID, thumbnailKeys)
+		}
 		c.JSON(http.StatusOK, event)
 		return
 	}
 
-	var event types.Event
+	// Keys missing from the body keep their current values.
+	event := types.Event{TimeZone: existing.TimeZone, MaxPlayers: existing.MaxPlayers, FieldID: existing.FieldID, ClubID: existing.ClubID}
 	if err := c.ShouldBindJSON(&event); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":        "Invalid input",
@@ -375,41 +840,61 @@ func UpdateEventHandler(c *gin.Context) {
 		return
 	}
 
-	event.Description = strings.TrimSpace(event.Description)
-	event.DetailedDescription = strings.TrimSpace(event.DetailedDescription)
-	if event.DetailedDescription == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Detailed description is required"})
-		return
-	}
-	if utf8.RuneCountInString(event.Description) > 400 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Small description must be 400 characters or less"})
+	if msg, ok := normalizeEventText(&event); !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
 		return
 	}
 
 	event.Category = category
-	columns := []string{"name", "description", "detailed_description", "location", "date", "lat", "lng", "category", "facebook_link"}
-	if event.Thumbnail != "" {
-		columns = append(columns, "thumbnail")
+	if event.MaxPlayers < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid max players"})
+		return
+	}
+	if event.FacebookLink, ok = normalizeFacebookLink(event.FacebookLink); !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Facebook link"})
+		return
 	}
-	if err := db.UpdateEventInDBColumns(id, &event, columns...); err != nil {
+	carryEventSchedule(&event, existing, "", "")
+	if msg, ok := normalizeEventSchedule(&event, "", ""); !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	if !applyEventField(c, &event) || !applyEventClub(c, user, existing, &event) {
+		return
+	}
+	columns := []string{"name", "description", "detailed_description", "location", "starts_at", "ends_at", "time_zone", "lat", "lng", "category", "facebook_link", "max_players", "field_id", "club_id"}
+	// A new thumbnail must go through the multipart upload, which processes
+	// and tracks it; a JSON thumbnail keeps the current one.
+	event.Thumbnail = existing.Thumbnail
+	event.ThumbnailVariants = existing.ThumbnailVariants
+	event.ThumbnailBlurhash = existing.ThumbnailBlurhash
+	event.ThumbnailColor = existing.ThumbnailColor
+	columns = resubmitForReview(user, existing, &event, columns)
+	if err := db.UpdateEventInDBColumns(id, user.Email, &event, columns...); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update event", "details": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, event)
 }
 
+// DeleteEventHandler lets moderators delete any event and creators withdraw their own.
 func DeleteEventHandler(c *gin.Context) {
-	_, ok := requireAdmin(c)
+	user, existing, ok := requireEventManager(c)
 	if !ok {
 		return
 	}
 
-	id := c.Param("id")
-	err := db.DeleteEventFromDB(id)
+	objectKeys, err := db.EventStoredObjectKeys(existing.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete event"})
+		return
+	}
+	err = db.DeleteEventFromDB(strconv.Itoa(existing.ID), user.Email)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete event"})
 		return
 	}
+	discardObjects(objectKeys)
 	c.Status(http.StatusNoContent)
 }
 
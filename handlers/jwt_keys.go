@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/MKolega/AirsoftHubCroatia/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// legacySecretKID is the kid given to AUTH_JWT_SECRET, and the key used for
+// tokens minted before kid headers existed.
+const legacySecretKID = "default"
+
+// jwtKey is one entry of the key ring. signKey is nil for verification-only
+// keys.
+type jwtKey struct {
+	kid       string
+	method    jwt.SigningMethod
+	signKey   any
+	verifyKey any
+}
+
+// loadJWTKeys builds the key ring from AUTH_JWT_SECRET and AUTH_JWT_KEYS and
+// returns it with the active signing key.
+//
+// AUTH_JWT_KEYS is a comma-separated list of kid:ALG:value entries. For HS256
+// the value is the secret; for RS256 and EdDSA it is the path to a PEM file
+// holding a private key, or a public key for a verification-only entry.
+// AUTH_JWT_ACTIVE_KID picks the signing key and defaults to the first entry
+// (or to AUTH_JWT_SECRET when no list is given).
+func loadJWTKeys() (map[string]*jwtKey, *jwtKey, error) {
+	keys := map[string]*jwtKey{}
+	defaultKID := ""
+
+	if secret := strings.TrimSpace(config.GetEnv("AUTH_JWT_SECRET", "")); secret != "" {
+		keys[legacySecretKID] = &jwtKey{
+			kid:       legacySecretKID,
+			method:    jwt.SigningMethodHS256,
+			signKey:   []byte(secret),
+			verifyKey: []byte(secret),
+		}
+		defaultKID = legacySecretKID
+	}
+
+	for _, entry := range strings.Split(config.GetEnv("AUTH_JWT_KEYS", ""), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) != 3 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[2]) == "" {
+			return nil, nil, fmt.Errorf("AUTH_JWT_KEYS: entry %q must look like kid:ALG:value", entry)
+		}
+		kid := strings.TrimSpace(parts[0])
+		if _, dup := keys[kid]; dup {
+			return nil, nil, fmt.Errorf("AUTH_JWT_KEYS: duplicate kid %q", kid)
+		}
+		key, err := parseJWTKey(kid, strings.ToUpper(strings.TrimSpace(parts[1])), strings.TrimSpace(parts[2]))
+		if err != nil {
+			return nil, nil, err
+		}
+		keys[kid] = key
+		if defaultKID == "" || defaultKID == legacySecretKID {
+			defaultKID = kid
+		}
+	}
+	if len(keys) == 0 {
+		return nil, nil, errors.New("AUTH_JWT_SECRET or AUTH_JWT_KEYS is required")
+	}
+
+	activeKID := strings.TrimSpace(config.GetEnv("AUTH_JWT_ACTIVE_KID", ""))
+	if activeKID == "" {
+		activeKID = defaultKID
+	}
+	active, ok := keys[activeKID]
+	if !ok {
+		return nil, nil, fmt.Errorf("AUTH_JWT_ACTIVE_KID %q is not a configured key", activeKID)
+	}
+	if active.signKey == nil {
+		return nil, nil, fmt.Errorf("AUTH_JWT_ACTIVE_KID %q has no private key", activeKID)
+	}
+	return keys, active, nil
+}
+
+func parseJWTKey(kid string, alg string, value string) (*jwtKey, error) {
+	key := &jwtKey{kid: kid}
+	switch alg {
+	case "HS256":
+		key.method = jwt.SigningMethodHS256
+		key.signKey = []byte(value)
+		key.verifyKey = []byte(value)
+		return key, nil
+	case "RS256", "EDDSA":
+	default:
+		return nil, fmt.Errorf("AUTH_JWT_KEYS: key %q has unsupported algorithm %q (HS256, RS256, EdDSA)", kid, alg)
+	}
+
+	pem, err := os.ReadFile(value)
+	if err != nil {
+		return nil, fmt.Errorf("AUTH_JWT_KEYS: key %q: %w", kid, err)
+	}
+	if alg == "RS256" {
+		key.method = jwt.SigningMethodRS256
+		if priv, err := jwt.ParseRSAPrivateKeyFromPEM(pem); err == nil {
+			key.signKey, key.verifyKey = priv, &priv.PublicKey
+			return key, nil
+		}
+		pub, err := jwt.ParseRSAPublicKeyFromPEM(pem)
+		if err != nil {
+			return nil, fmt.Errorf("AUTH_JWT_KEYS: key %q is not an RSA PEM key", kid)
+		}
+		key.verifyKey = pub
+		return key, nil
+	}
+
+	key.method = jwt.SigningMethodEdDSA
+	if priv, err := jwt.ParseEdPrivateKeyFromPEM(pem); err == nil {
+		edPriv, ok := priv.(ed25519.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("AUTH_JWT_KEYS: key %q is not an Ed25519 key", kid)
+		}
+		key.signKey, key.verifyKey = edPriv, edPriv.Public()
+		return key, nil
+	}
+	pub, err := jwt.ParseEdPublicKeyFromPEM(pem)
+	if err != nil {
+		return nil, fmt.Errorf("AUTH_JWT_KEYS: key %q is not an Ed25519 PEM key", kid)
+	}
+	key.verifyKey = pub
+	return key, nil
+}
+
+// jwtKeyFunc resolves the verification key from the token's kid header.
+func jwtKeyFunc(keys map[string]*jwtKey) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			kid = legacySecretKID
+		}
+		key, ok := keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown kid %q", kid)
+		}
+		if token.Method.Alg() != key.method.Alg() {
+			return nil, fmt.Errorf("kid %q does not use %s", kid, token.Method.Alg())
+		}
+		return key.verifyKey, nil
+	}
+}
+
+func jwtAlgorithms(keys map[string]*jwtKey) []string {
+	seen := map[string]bool{}
+	var algs []string
+	for _, k := range keys {
+		if alg := k.method.Alg(); !seen[alg] {
+			seen[alg] = true
+			algs = append(algs, alg)
+		}
+	}
+	return algs
+}
+
+// ValidateAuthConfig loads the JWT settings so misconfiguration stops the
+// server at startup instead of on the first sign-in.
+func ValidateAuthConfig() error {
+	_, err := getJWTSettings()
+	return err
+}
+
+// JWKSHandler publishes the public halves of the asymmetric keys so other
+// services can verify access tokens. HS256 secrets are never exposed.
+func JWKSHandler(c *gin.Context) {
+	s, err := getJWTSettings()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Auth is not configured"})
+		return
+	}
+
+	b64 := base64.RawURLEncoding
+	jwks := []gin.H{}
+	for _, kid := range s.kids {
+		k := s.keys[kid]
+		switch pub := k.verifyKey.(type) {
+		case *rsa.PublicKey:
+			jwks = append(jwks, gin.H{
+				"kty": "RSA",
+				"use": "sig",
+				"alg": k.method.Alg(),
+				"kid": k.kid,
+				"n":   b64.EncodeToString(pub.N.Bytes()),
+				"e":   b64.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			jwks = append(jwks, gin.H{
+				"kty": "OKP",
+				"crv": "Ed25519",
+				"use": "sig",
+				"alg": k.method.Alg(),
+				"kid": k.kid,
+				"x":   b64.EncodeToString(pub),
+			})
+		}
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, gin.H{"keys": jwks})
+}
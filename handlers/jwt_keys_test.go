@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// writePEM writes a PKCS#8 private key, or its public half, to a temp file.
+func writePEM(t *testing.T, priv crypto.Signer, public bool) string {
+	t.Helper()
+	var block *pem.Block
+	if public {
+		der, err := x509.MarshalPKIXPublicKey(priv.Public())
+		if err != nil {
+			t.Fatal(err)
+		}
+		block = &pem.Block{Type: "PUBLIC KEY", Bytes: der}
+	} else {
+		der, err := x509.MarshalPKCS8PrivateKey(priv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		block = &pem.Block{Type: "PRIVATE KEY", Bytes: der}
+	}
+	path := filepath.Join(t.TempDir(), "key.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadJWTKeys(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaPriv := writePEM(t, rsaKey, false)
+	rsaPub := writePEM(t, rsaKey, true)
+	edPriv := writePEM(t, edKey, false)
+
+	tests := []struct {
+		name      string
+		secret    string
+		keys      string
+		activeKID string
+		wantKIDs  []string
+		wantSign  string
+		wantAlg   string
+		wantErr   string
+	}{
+		{
+			name:     "secret only",
+			secret:   "s3cret",
+			wantKIDs: []string{legacySecretKID},
+			wantSign: legacySecretKID,
+			wantAlg:  "HS256",
+		},
+		{
+			name:     "first listed key signs over the secret",
+			secret:   "s3cret",
+			keys:     "2026a:HS256:newer, 2026b:RS256:" + rsaPriv,
+			wantKIDs: []string{legacySecretKID, "2026a", "2026b"},
+			wantSign: "2026a",
+			wantAlg:  "HS256",
+		},
+		{
+			name:      "active kid picks the key",
+			keys:      "a:HS256:one,b:EdDSA:" + edPriv,
+			activeKID: "b",
+			wantKIDs:  []string{"a", "b"},
+			wantSign:  "b",
+			wantAlg:   "EdDSA",
+		},
+		{
+			name:     "algorithm is case-insensitive",
+			keys:     "ed:eddsa:" + edPriv,
+			wantKIDs: []string{"ed"},
+			wantSign: "ed",
+			wantAlg:  "EdDSA",
+		},
+		{
+			name:      "public key verifies only",
+			secret:    "s3cret",
+			keys:      "old:RS256:" + rsaPub,
+			activeKID: legacySecretKID,
+			wantKIDs:  []string{legacySecretKID, "old"},
+			wantSign:  legacySecretKID,
+			wantAlg:   "HS256",
+		},
+		{name: "nothing configured", wantErr: "is required"},
+		{name: "malformed entry", keys: "a:HS256", wantErr: "must look like kid:ALG:value"},
+		{name: "empty kid", keys: ":HS256:x", wantErr: "must look like kid:ALG:value"},
+		{name: "duplicate kid", keys: "a:HS256:x,a:HS256:y", wantErr: `duplicate kid "a"`},
+		{name: "secret kid reused", secret: "s3cret", keys: "default:HS256:y", wantErr: `duplicate kid "default"`},
+		{name: "unsupported algorithm", keys: "a:ES256:x", wantErr: "unsupported algorithm"},
+		{name: "missing pem", keys: "a:RS256:" + filepath.Join(t.TempDir(), "missing.pem"), wantErr: `key "a"`},
+		{name: "wrong pem type", keys: "a:RS256:" + edPriv, wantErr: "is not an RSA PEM key"},
+		{name: "unknown active kid", keys: "a:HS256:x", activeKID: "b", wantErr: `"b" is not a configured key`},
+		{name: "active kid without private key", keys: "old:RS256:" + rsaPub, wantErr: `"old" has no private key`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("AUTH_JWT_SECRET", tt.secret)
+			t.Setenv("AUTH_JWT_KEYS", tt.keys)
+			t.Setenv("AUTH_JWT_ACTIVE_KID", tt.activeKID)
+
+			keys, active, err := loadJWTKeys()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("loadJWTKeys: %v", err)
+			}
+			if len(keys) != len(tt.wantKIDs) {
+				t.Errorf("got %d keys, want %v", len(keys), tt.wantKIDs)
+			}
+			for _, kid := range tt.wantKIDs {
+				if k, ok := keys[kid]; !ok || k.kid != kid {
+					t.Errorf("key %q missing", kid)
+				}
+			}
+			if active.kid != tt.wantSign || active.method.Alg() != tt.wantAlg {
+				t.Errorf("active key = %s/%s, want %s/%s", active.kid, active.method.Alg(), tt.wantSign, tt.wantAlg)
+			}
+		})
+	}
+}
+
+func TestJWTKeyFunc(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := map[string]*jwtKey{
+		legacySecretKID: {kid: legacySecretKID, method: jwt.SigningMethodHS256, signKey: []byte("legacy"), verifyKey: []byte("legacy")},
+		"new":           {kid: "new", method: jwt.SigningMethodHS256, signKey: []byte("newer"), verifyKey: []byte("newer")},
+		"ed":            {kid: "ed", method: jwt.SigningMethodEdDSA, signKey: edKey, verifyKey: edKey.Public()},
+	}
+
+	sign := func(method jwt.SigningMethod, kid string, key any) string {
+		token := jwt.NewWithClaims(method, jwt.MapClaims{"sub": "player@example.com"})
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		s, err := token.SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		valid bool
+	}{
+		{"no kid uses the legacy secret", sign(jwt.SigningMethodHS256, "", []byte("legacy")), true},
+		{"kid selects the key", sign(jwt.SigningMethodHS256, "new", []byte("newer")), true},
+		{"asymmetric kid", sign(jwt.SigningMethodEdDSA, "ed", edKey), true},
+		{"kid with another key's secret", sign(jwt.SigningMethodHS256, "new", []byte("legacy")), false},
+		{"no kid with a newer secret", sign(jwt.SigningMethodHS256, "", []byte("newer")), false},
+		{"unknown kid", sign(jwt.SigningMethodHS256, "gone", []byte("legacy")), false},
+		{"algorithm does not match the kid", sign(jwt.SigningMethodHS256, "ed", []byte("legacy")), false},
+	}
+	parser := jwt.NewParser(jwt.WithValidMethods(jwtAlgorithms(keys)))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parser.Parse(tt.token, jwtKeyFunc(keys))
+			if tt.valid && err != nil {
+				t.Errorf("Parse: %v", err)
+			}
+			if !tt.valid && err == nil {
+				t.Error("Parse accepted the token")
+			}
+		})
+	}
+}
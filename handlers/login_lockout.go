@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MKolega/AirsoftHubCroatia/internal/config"
+	"github.com/MKolega/AirsoftHubCroatia/internal/db"
+	"github.com/MKolega/AirsoftHubCroatia/internal/mailer"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func envPositiveInt(key string, fallback int) int {
+	n, err := strconv.Atoi(strings.TrimSpace(config.GetEnv(key, strconv.Itoa(fallback))))
+	if err != nil || n <= 0 {
+		return fallback
+	}
+	return n
+}
+
+// loginPolicy reads the per-email throttling settings. The defaults allow 3
+// free failures, then wait 1s, 2s, 4s... (capped at 5 minutes) and lock the
+// email for 30 minutes after 10 failures.
+func loginPolicy() db.LoginPolicy {
+	return db.LoginPolicy{
+		FreeAttempts:     envPositiveInt("AUTH_LOGIN_FREE_ATTEMPTS", 3),
+		BaseDelay:        time.Second,
+		MaxDelay:         5 * time.Minute,
+		LockoutThreshold: envPositiveInt("AUTH_LOCKOUT_THRESHOLD", 10),
+		LockoutDuration:  time.Duration(envPositiveInt("AUTH_LOCKOUT_MINUTES", 30)) * time.Minute,
+		Window:           24 * time.Hour,
+	}
+}
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     []byte
+)
+
+// compareDummyPassword burns the same bcrypt time as a real check so unknown
+// emails cannot be told apart by response time.
+func compareDummyPassword(password string) {
+	dummyHashOnce.Do(func() {
+		buf := make([]byte, 16)
+		_, _ = rand.Read(buf)
+		dummyHash, _ = bcrypt.GenerateFromPassword(buf, bcrypt.DefaultCost)
+	})
+	_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+}
+
+func loginThrottled(c *gin.Context, until time.Time) {
+	secs := int(math.Ceil(time.Until(until).Seconds()))
+	if secs < 1 {
+		secs = 1
+	}
+	c.Header("Retry-After", strconv.Itoa(secs))
+	c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many failed sign-in attempts, please try again later"})
+}
+
+// notifyLoginLockout tells the owner of an account that failed sign-ins
+// locked it.
+func notifyLoginLockout(policy db.LoginPolicy, email string, username string) {
+	sendMailAsync(mailer.Message{
+		To:      email,
+		Subject: "Sign-in to your Airsoft Hub Croatia account was locked",
+		Text: "Hi " + username + ",\n\n" +
+			"We saw " + strconv.Itoa(policy.LockoutThreshold) + " failed sign-in attempts for your account, so sign-in is paused for " +
+			strconv.Itoa(int(policy.LockoutDuration.Minutes())) + " minutes.\n\n" +
+			"If this was you, wait and try again or reset your password. If it was not, we recommend resetting your password.\n",
+	})
+}
+
+// AdminUnlockUserHandler clears failed sign-ins for a user, lifting any
+// lockout or backoff.
+func AdminUnlockUserHandler(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || userID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user id"})
+		return
+	}
+	user, err := db.GetUserByID(userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	if err := db.ClearLoginFailures(normalizeEmail(user.Email), loginPolicy().Window); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlock account"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
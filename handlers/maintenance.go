@@ -1,19 +1,32 @@
 package handlers
 
 import (
+	"log"
 	"net/http"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/MKolega/AirsoftHubCroatia/internal/config"
 	"github.com/MKolega/AirsoftHubCroatia/internal/db"
+	"github.com/MKolega/AirsoftHubCroatia/types"
 	"github.com/gin-gonic/gin"
 )
 
-func maintenanceEnabled() bool {
+// maintenanceCacheTTL bounds how long another API instance can lag behind a
+// change made through PUT /api/admin/maintenance.
+const maintenanceCacheTTL = 10 * time.Second
+
+var maintenanceCache struct {
+	mu       sync.Mutex
+	settings types.MaintenanceSettings
+	loadedAt time.Time
+}
+
+// maintenanceForcedByEnv keeps MAINTENANCE_MODE=true as an emergency switch
+// that works even when the settings row cannot be read.
+func maintenanceForcedByEnv() bool {
 	v := strings.TrimSpace(config.GetEnv("MAINTENANCE_MODE", "false"))
-	if v == "" {
-		return false
-	}
 	switch strings.ToLower(v) {
 	case "1", "true", "yes", "y", "on":
 		return true
@@ -22,12 +35,101 @@ func maintenanceEnabled() bool {
 	}
 }
 
+// currentMaintenance returns the cached settings, reloading them from the
+// database once the cache is stale. On a load error the last known settings
+// are kept.
+func currentMaintenance() types.MaintenanceSettings {
+	maintenanceCache.mu.Lock()
+	defer maintenanceCache.mu.Unlock()
+
+	if time.Since(maintenanceCache.loadedAt) >= maintenanceCacheTTL {
+		settings, err := db.GetMaintenanceSettings()
+		if err != nil {
+			log.Printf("maintenance: failed to load settings: %v", err)
+		} else {
+			maintenanceCache.settings = *settings
+		}
+		maintenanceCache.loadedAt = time.Now()
+	}
+	return maintenanceCache.settings
+}
+
+func setCachedMaintenance(settings types.MaintenanceSettings) {
+	maintenanceCache.mu.Lock()
+	defer maintenanceCache.mu.Unlock()
+	maintenanceCache.settings = settings
+	maintenanceCache.loadedAt = time.Now()
+}
+
+func maintenanceActiveAt(s types.MaintenanceSettings, now time.Time) bool {
+	if !s.Enabled {
+		return false
+	}
+	if s.StartsAt != nil && now.Before(*s.StartsAt) {
+		return false
+	}
+	if s.EndsAt != nil && !now.Before(*s.EndsAt) {
+		return false
+	}
+	return true
+}
+
+func maintenanceEnabled() bool {
+	return maintenanceForcedByEnv() || maintenanceActiveAt(currentMaintenance(), time.Now())
+}
+
+// MaintenanceStatusHandler tells clients whether maintenance is on, plus any
+// upcoming window, message and ETA to show.
 func MaintenanceStatusHandler(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"enabled": maintenanceEnabled()})
+	s := currentMaintenance()
+	forced := maintenanceForcedByEnv()
+	active := forced || maintenanceActiveAt(s, time.Now())
+
+	eta := s.ETA
+	if eta == nil {
+		eta = s.EndsAt
+	}
+	resp := gin.H{
+		"enabled":   active,
+		"read_only": active && !forced && s.AllowReads,
+		"message":   s.Message,
+		"eta":       eta,
+	}
+	if s.Enabled && s.StartsAt != nil && time.Now().Before(*s.StartsAt) {
+		resp["scheduled"] = gin.H{"starts_at": s.StartsAt, "ends_at": s.EndsAt}
+	}
+	c.JSON(http.StatusOK, resp)
 }
 
-// MaintenanceGate blocks access to API routes when MAINTENANCE_MODE is enabled.
-// Auth login + /auth/me are allowed so eligible users can sign in.
+// maintenanceRouteAllowed reports whether an allowlist entry such as
+// "GET /api/events" or "* /api/events/:id/*" covers the request.
+func maintenanceRouteAllowed(entries []string, method string, route string) bool {
+	for _, entry := range entries {
+		m, pattern, ok := strings.Cut(strings.TrimSpace(entry), " ")
+		if !ok {
+			continue
+		}
+		pattern = strings.TrimSpace(pattern)
+		if m != "*" && !strings.EqualFold(m, method) {
+			continue
+		}
+		if prefix, wildcard := strings.CutSuffix(pattern, "*"); wildcard {
+			if strings.HasPrefix(route, prefix) {
+				return true
+			}
+			continue
+		}
+		if route == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// MaintenanceGate blocks access to API routes while maintenance is active.
+// Auth login/refresh/logout, /auth/me and password reset are allowed so
+// eligible users can sign in, and the stored allowlist (or allow_reads) can
+// keep parts of the site open.
 func MaintenanceGate() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if !maintenanceEnabled() {
@@ -40,7 +142,9 @@ func MaintenanceGate() gin.HandlerFunc {
 			p = strings.TrimSpace(c.Request.URL.Path)
 		}
 
-		if strings.HasSuffix(p, "/auth/login") || strings.HasSuffix(p, "/auth/me") {
+		if strings.HasSuffix(p, "/auth/login") || strings.HasSuffix(p, "/auth/me") ||
+			strings.HasSuffix(p, "/auth/refresh") || strings.HasSuffix(p, "/auth/logout") ||
+			strings.HasSuffix(p, "/auth/forgot-password") || strings.HasSuffix(p, "/auth/reset-password") {
 			c.Next()
 			return
 		}
@@ -51,6 +155,16 @@ func MaintenanceGate() gin.HandlerFunc {
 			return
 		}
 
+		if !maintenanceForcedByEnv() {
+			s := currentMaintenance()
+			method := c.Request.Method
+			if (s.AllowReads && (method == http.MethodGet || method == http.MethodHead)) ||
+				maintenanceRouteAllowed(s.AllowedRoutes, method, p) {
+				c.Next()
+				return
+			}
+		}
+
 		email, ok := emailFromAuthHeader(c)
 		if !ok {
 			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Under maintenance"})
@@ -59,7 +173,7 @@ func MaintenanceGate() gin.HandlerFunc {
 		}
 
 		user, err := db.GetUserByEmail(email)
-		if err != nil || user == nil || (!user.IsAdmin && !user.IsMaintenanceUser) {
+		if err != nil || user == nil || !user.Can(types.PermMaintenanceAccess) {
 			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Under maintenance"})
 			c.Abort()
 			return
@@ -68,3 +182,80 @@ func MaintenanceGate() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// AdminMaintenanceHandler returns the stored maintenance settings.
+func AdminMaintenanceHandler(c *gin.Context) {
+	settings, err := db.GetMaintenanceSettings()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch maintenance settings"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"settings":  settings,
+		"active":    maintenanceActiveAt(*settings, time.Now()),
+		"env_force": maintenanceForcedByEnv(),
+	})
+}
+
+// UpdateMaintenanceHandler replaces the maintenance settings. Setting
+// enabled with a future starts_at schedules a window; ends_at turns it off
+// automatically.
+func UpdateMaintenanceHandler(c *gin.Context) {
+	var req types.MaintenanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	req.Message = strings.TrimSpace(req.Message)
+	if len(req.Message) > 500 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Message must be at most 500 characters"})
+		return
+	}
+	if req.StartsAt != nil && req.EndsAt != nil && !req.EndsAt.After(*req.StartsAt) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ends_at must be after starts_at"})
+		return
+	}
+
+	routes := make([]string, 0, len(req.AllowedRoutes))
+	for _, entry := range req.AllowedRoutes {
+		m, pattern, ok := strings.Cut(strings.TrimSpace(entry), " ")
+		pattern = strings.TrimSpace(pattern)
+		if !ok || !strings.HasPrefix(pattern, "/") || !validMaintenanceMethod(m) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "allowed_routes entries must look like \"GET /api/events\""})
+			return
+		}
+		routes = append(routes, strings.ToUpper(m)+" "+pattern)
+	}
+
+	settings := &types.MaintenanceSettings{
+		Enabled:        req.Enabled,
+		StartsAt:       req.StartsAt,
+		EndsAt:         req.EndsAt,
+		Message:        req.Message,
+		ETA:            req.ETA,
+		AllowReads:     req.AllowReads,
+		AllowedRoutes:  routes,
+		UpdatedByEmail: currentUser(c).Email,
+	}
+	if err := db.SaveMaintenanceSettings(settings); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save maintenance settings"})
+		return
+	}
+	setCachedMaintenance(*settings)
+
+	c.JSON(http.StatusOK, gin.H{
+		"settings":  settings,
+		"active":    maintenanceActiveAt(*settings, time.Now()),
+		"env_force": maintenanceForcedByEnv(),
+	})
+}
+
+func validMaintenanceMethod(m string) bool {
+	switch strings.ToUpper(m) {
+	case "*", http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
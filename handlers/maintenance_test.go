@@ -0,0 +1,39 @@
+package handlers
+
+import "testing"
+
+func TestMaintenanceRouteAllowed(t *testing.T) {
+	entries := []string{
+		"GET /api/events",
+		"* /api/events/:id/*",
+		"  post   /api/auth/register  ",
+		"DELETE",
+		"",
+	}
+	tests := []struct {
+		name    string
+		entries []string
+		method  string
+		route   string
+		want    bool
+	}{
+		{"exact method and route", entries, "GET", "/api/events", true},
+		{"other method", entries, "POST", "/api/events", false},
+		{"route must match exactly", entries, "GET", "/api/events/:id", false},
+		{"wildcard method and prefix", entries, "DELETE", "/api/events/:id/media", true},
+		{"wildcard matches the bare prefix", entries, "GET", "/api/events/:id/", true},
+		{"wildcard needs the prefix", entries, "GET", "/api/events/:id", false},
+		{"method is case-insensitive", entries, "POST", "/api/auth/register", true},
+		{"entry without a route is ignored", entries, "DELETE", "/api/clubs/:slug", false},
+		{"unlisted route", entries, "GET", "/api/clubs", false},
+		{"no entries", nil, "GET", "/api/events", false},
+		{"catch-all", []string{"* *"}, "PATCH", "/api/anything", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := maintenanceRouteAllowed(tt.entries, tt.method, tt.route); got != tt.want {
+				t.Errorf("maintenanceRouteAllowed(%q, %q) = %v, want %v", tt.method, tt.route, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/MKolega/AirsoftHubCroatia/internal/db"
+	"github.com/MKolega/AirsoftHubCroatia/types"
+	"github.com/gin-gonic/gin"
+)
+
+func TestEditedEventStaysPublishedAsApproved(t *testing.T) {
+	useTestDB(t)
+
+	start := time.Now().Add(30 * 24 * time.Hour).UTC().Truncate(time.Second)
+	event := &types.Event{
+		Status:              "approved",
+		Name:                "Approved name",
+		DetailedDescription: "Approved details",
+		CreatorEmail:        "organizer@example.com",
+		StartsAt:            start,
+		EndsAt:              start.Add(6 * time.Hour),
+		TimeZone:            types.DefaultEventTimeZone,
+		Category:            "Skirmish",
+	}
+	if err := db.InsertEventToDB(event); err != nil {
+		t.Fatalf("insert event: %v", err)
+	}
+	t.Cleanup(func() { _ = db.DeleteEventFromDB(strconv.Itoa(event.ID), "") })
+
+	// The organizer may not publish directly, so the edit goes to review.
+	organizer := &types.User{Email: event.CreatorEmail}
+	edit := *event
+	edit.Name = "Edited name"
+	columns := resubmitForReview(organizer, event, &edit, []string{"name"})
+	if err := db.UpdateEventInDBColumns(strconv.Itoa(event.ID), organizer.Email, &edit, columns...); err != nil {
+		t.Fatalf("update event: %v", err)
+	}
+
+	router := gin.New()
+	router.GET("/api/events", EventsHandler)
+	router.GET("/api/events/:id", EventDetailHandler)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/events", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /api/events = %d: %s", rec.Code, rec.Body)
+	}
+	var listed []types.Event
+	if err := json.Unmarshal(rec.Body.Bytes(), &listed); err != nil {
+		t.Fatalf("decode events: %v", err)
+	}
+	var found *types.Event
+	for i := range listed {
+		if listed[i].ID == event.ID {
+			found = &listed[i]
+		}
+	}
+	if found == nil {
+		t.Fatalf("edited event %d is not listed", event.ID)
+	}
+	if found.Name != "Approved name" || found.Status != "approved" {
+		t.Errorf("listed event = %q (%s), want the approved version", found.Name, found.Status)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/events/"+strconv.Itoa(event.ID), nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /api/events/%d = %d: %s", event.ID, rec.Code, rec.Body)
+	}
+	var detail types.EventDetail
+	if err := json.Unmarshal(rec.Body.Bytes(), &detail); err != nil {
+		t.Fatalf("decode event: %v", err)
+	}
+	if detail.Name != "Approved name" {
+		t.Errorf("detail name = %q, want the approved %q", detail.Name, "Approved name")
+	}
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MKolega/AirsoftHubCroatia/internal/config"
+	"github.com/MKolega/AirsoftHubCroatia/internal/db"
+	"github.com/MKolega/AirsoftHubCroatia/internal/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+// rateLimitPolicies are the named limits applied per route group. Each can be
+// overridden with RATE_LIMIT_<NAME>="<requests>/<window>", e.g. "30/1m".
+var rateLimitPolicies = map[string]ratelimit.Policy{
+	"auth":         {Name: "auth", Limit: 20, Window: time.Minute},
+	"event_create": {Name: "event_create", Limit: 10, Window: time.Hour},
+	"saves":        {Name: "saves", Limit: 60, Window: time.Minute},
+	"uploads":      {Name: "uploads", Limit: 60, Window: time.Hour},
+	"clubs":        {Name: "clubs", Limit: 30, Window: time.Hour},
+	"admin":        {Name: "admin", Limit: 120, Window: time.Minute},
+}
+
+var (
+	rateLimiterOnce sync.Once
+	rateLimiterVal  ratelimit.Limiter
+)
+
+func rateLimiter() ratelimit.Limiter {
+	rateLimiterOnce.Do(func() {
+		l, err := ratelimit.FromEnv(db.Bun)
+		if err != nil {
+			log.Printf("ratelimit: %v; falling back to in-memory limits", err)
+			l = ratelimit.NewMemory()
+		}
+		rateLimiterVal = l
+	})
+	return rateLimiterVal
+}
+
+func rateLimitPolicy(name string) ratelimit.Policy {
+	p, ok := rateLimitPolicies[name]
+	if !ok {
+		panic(fmt.Sprintf("unknown rate limit policy %q", name))
+	}
+
+	// AUTH_RATE_LIMIT_RPM predates named policies and still applies.
+	if name == "auth" {
+		if rpm, err := strconv.Atoi(strings.TrimSpace(config.GetEnv("AUTH_RATE_LIMIT_RPM", ""))); err == nil && rpm > 0 {
+			p.Limit = rpm
+		}
+	}
+
+	raw := strings.TrimSpace(config.GetEnv("RATE_LIMIT_"+strings.ToUpper(name), ""))
+	if raw == "" {
+		return p
+	}
+	limitStr, windowStr, ok := strings.Cut(raw, "/")
+	limit, err := strconv.Atoi(strings.TrimSpace(limitStr))
+	window, werr := time.ParseDuration(strings.TrimSpace(windowStr))
+	if !ok || err != nil || werr != nil || limit <= 0 || window < time.Second {
+		log.Printf("ratelimit: ignoring invalid RATE_LIMIT_%s=%q", strings.ToUpper(name), raw)
+		return p
+	}
+	p.Limit, p.Window = limit, window
+	return p
+}
+
+// rateLimitKey identifies the caller: the signed-in user when there is one,
+// otherwise the client IP. Auth endpoints always key by IP.
+func rateLimitKey(c *gin.Context, policy string) string {
+	if policy != "auth" {
+		if email, ok := emailFromAuthHeader(c); ok {
+			return "user:" + email
+		}
+	}
+	ip := strings.TrimSpace(c.ClientIP())
+	if ip == "" {
+		ip = "unknown"
+	}
+	return "ip:" + ip
+}
+
+// RateLimit applies the named policy and reports it with the RateLimit-*
+// headers. If the limiter backend fails, requests are let through.
+func RateLimit(name string) gin.HandlerFunc {
+	policy := rateLimitPolicy(name)
+	policyHeader := fmt.Sprintf("%d;w=%d", policy.Limit, int(policy.Window.Seconds()))
+
+	return func(c *gin.Context) {
+		res, err := rateLimiter().Allow(c.Request.Context(), policy, rateLimitKey(c, name))
+		if err != nil {
+			log.Printf("ratelimit: %s: %v", name, err)
+			c.Next()
+			return
+		}
+
+		reset := int(math.Ceil(res.Reset.Seconds()))
+		c.Header("RateLimit-Policy", policyHeader)
+		c.Header("RateLimit-Limit", strconv.Itoa(res.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(reset))
+
+		if !res.Allowed {
+			c.Header("Retry-After", strconv.Itoa(max(reset, 1)))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "Too many requests, please try again later"})
+			return
+		}
+		c.Next()
+	}
+}
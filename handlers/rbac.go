@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/MKolega/AirsoftHubCroatia/internal/db"
+	"github.com/MKolega/AirsoftHubCroatia/types"
+	"github.com/gin-gonic/gin"
+)
+
+const currentUserKey = "currentUser"
+
+// RequirePermission only lets signed-in users holding perm through and makes
+// the user available to the handler via currentUser.
+func RequirePermission(perm types.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		email, ok := emailFromAuthHeader(c)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
+		user, err := db.GetUserByEmail(email)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+			return
+		}
+		if !user.Can(perm) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "You do not have permission to do this"})
+			return
+		}
+		c.Set(currentUserKey, user)
+		c.Next()
+	}
+}
+
+// currentUser returns the user stored by RequirePermission.
+func currentUser(c *gin.Context) *types.User {
+	user, _ := c.MustGet(currentUserKey).(*types.User)
+	return user
+}
+
+// AdminRolesHandler lists the grantable roles and what each allows.
+func AdminRolesHandler(c *gin.Context) {
+	roles := make([]gin.H, 0, len(types.GrantableRoles))
+	for _, role := range types.GrantableRoles {
+		roles = append(roles, gin.H{"role": role, "permissions": types.RolePermissions(role)})
+	}
+	c.JSON(http.StatusOK, roles)
+}
+
+// AdminUsersHandler searches users by email/username (?q=) and role (?role=).
+func AdminUsersHandler(c *gin.Context) {
+	role := strings.TrimSpace(c.Query("role"))
+	if role != "" && !types.ValidGrantableRole(role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown role"})
+		return
+	}
+
+	users, err := db.ListUsers(c.Query("q"), role)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
+		return
+	}
+	c.JSON(http.StatusOK, users)
+}
+
+func AdminGrantRoleHandler(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || userID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user id"})
+		return
+	}
+	var req types.RoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	role := strings.ToLower(strings.TrimSpace(req.Role))
+	if !types.ValidGrantableRole(role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown role"})
+		return
+	}
+
+	if _, err := db.GetUserByID(userID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if err := db.GrantRole(userID, role, currentUser(c).Email); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to grant role"})
+		return
+	}
+
+	user, err := db.GetUserByID(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+func AdminRevokeRoleHandler(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || userID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user id"})
+		return
+	}
+	role := strings.ToLower(strings.TrimSpace(c.Param("role")))
+	if !types.ValidGrantableRole(role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown role"})
+		return
+	}
+
+	if err := db.RevokeRole(userID, role); err != nil {
+		switch {
+		case errors.Is(err, db.ErrRoleNotGranted):
+			c.JSON(http.StatusNotFound, gin.H{"error": "User does not have this role"})
+		case errors.Is(err, db.ErrLastAdmin):
+			c.JSON(http.StatusConflict, gin.H{"error": "Cannot remove the last admin"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke role"})
+		}
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/MKolega/AirsoftHubCroatia/internal/db"
+	"github.com/MKolega/AirsoftHubCroatia/types"
+	"github.com/gin-gonic/gin"
+)
+
+func requireUserAndEventID(c *gin.Context) (*types.User, int, bool) {
+	email, ok := emailFromAuthHeader(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Sign in required"})
+		return nil, 0, false
+	}
+	user, err := db.GetUserByEmail(email)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return nil, 0, false
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event id"})
+		return nil, 0, false
+	}
+	return user, id, true
+}
+
+// RegistrationHandler returns the caller's registration status for an event.
+// Unpublished events are only visible to users who may manage them.
+func RegistrationHandler(c *gin.Context) {
+	user, id, ok := requireUserAndEventID(c)
+	if !ok {
+		return
+	}
+
+	event, err := db.GetEventByID(id)
+	if err != nil {
+		if errors.Is(err, db.ErrEventNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch event"})
+		return
+	}
+	if !event.Published() && !canManageEvent(user, event) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+
+	status, err := db.GetRegistrationStatus(event, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch registration"})
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// RegisterForEventHandler registers the caller, or waitlists them when the
+// event is at max_players. An optional {"faction_id"} body picks a side.
+func RegisterForEventHandler(c *gin.Context) {
+	user, id, ok := requireUserAndEventID(c)
+	if !ok {
+		return
+	}
+
+	var req types.RegistrationRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	if req.FactionID < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid faction id"})
+		return
+	}
+
+	status, err := db.RegisterForEvent(id, user.ID, req.FactionID)
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrEventNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		case errors.Is(err, db.ErrFactionNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Faction not found"})
+		case errors.Is(err, db.ErrFactionFull):
+			c.JSON(http.StatusConflict, gin.H{"error": "This faction is full"})
+		case errors.Is(err, db.ErrRegistrationClosed):
+			c.JSON(http.StatusConflict, gin.H{"error": "Registration is closed for this event"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+func UnregisterFromEventHandler(c *gin.Context) {
+	user, id, ok := requireUserAndEventID(c)
+	if !ok {
+		return
+	}
+
+	if err := db.UnregisterFromEvent(id, user.ID); err != nil {
+		if errors.Is(err, db.ErrEventNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel registration"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// EventRosterHandler lists registrants for the event's creator or a moderator.
+func EventRosterHandler(c *gin.Context) {
+	_, event, ok := requireEventManager(c)
+	if !ok {
+		return
+	}
+
+	roster, err := db.GetEventRoster(event.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch roster"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"max_players": event.MaxPlayers, "players": roster})
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/MKolega/AirsoftHubCroatia/internal/db"
+	"github.com/MKolega/AirsoftHubCroatia/types"
+	"github.com/gin-gonic/gin"
+)
+
+// RefreshHandler trades a refresh token for a new access token and a new
+// refresh token. The old refresh token stops working.
+func RefreshHandler(c *gin.Context) {
+	var req types.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.RefreshToken) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "refresh_token is required"})
+		return
+	}
+	s, err := getJWTSettings()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh session"})
+		return
+	}
+
+	session, refresh, err := db.RotateSession(strings.TrimSpace(req.RefreshToken), s.refreshTTL)
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidSession) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Session expired, please sign in again"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh session"})
+		return
+	}
+	user, err := db.GetUserByID(session.UserID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+	if maintenanceEnabled() && !user.Can(types.PermMaintenanceAccess) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Under maintenance: restricted access"})
+		return
+	}
+
+	tok, err := issueToken(user.Email, session.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh session"})
+		return
+	}
+	c.JSON(http.StatusOK, types.AuthResponse{
+		Token:        tok,
+		Email:        user.Email,
+		RefreshToken: refresh,
+		ExpiresIn:    int(s.ttl.Seconds()),
+	})
+}
+
+// LogoutHandler ends the session of the presented access token and/or
+// refresh token and revokes the access token immediately.
+func LogoutHandler(c *gin.Context) {
+	var req types.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	claims, ok := claimsFromAuthHeader(c)
+	refresh := strings.TrimSpace(req.RefreshToken)
+	if !ok && refresh == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if ok {
+		if err := db.RevokeAccessToken(claims.ID, claims.ExpiresAt.Time); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign out"})
+			return
+		}
+		if user, err := db.GetUserByEmail(normalizeEmail(claims.Email)); err == nil {
+			if err := db.RevokeSession(user.ID, claims.SessionID); err != nil && !errors.Is(err, db.ErrSessionNotFound) {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign out"})
+				return
+			}
+		}
+	}
+	if refresh != "" {
+		if err := db.RevokeSessionByRefreshToken(refresh); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign out"})
+			return
+		}
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func requireSessionUser(c *gin.Context) (*types.User, *authClaims, bool) {
+	claims, ok := claimsFromAuthHeader(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return nil, nil, false
+	}
+	user, err := db.GetUserByEmail(normalizeEmail(claims.Email))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return nil, nil, false
+	}
+	return user, claims, true
+}
+
+// SessionsHandler lists the caller's active sessions, flagging the one the
+// request was made with.
+func SessionsHandler(c *gin.Context) {
+	user, claims, ok := requireSessionUser(c)
+	if !ok {
+		return
+	}
+
+	sessions, err := db.ListActiveSessions(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sessions"})
+		return
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == claims.SessionID
+	}
+	c.JSON(http.StatusOK, sessions)
+}
+
+func RevokeSessionHandler(c *gin.Context) {
+	user, _, ok := requireSessionUser(c)
+	if !ok {
+		return
+	}
+
+	if err := db.RevokeSession(user.ID, c.Param("id")); err != nil {
+		if errors.Is(err, db.ErrSessionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// RevokeOtherSessionsHandler signs the caller out everywhere except the
+// current session.
+func RevokeOtherSessionsHandler(c *gin.Context) {
+	user, claims, ok := requireSessionUser(c)
+	if !ok {
+		return
+	}
+
+	if err := db.RevokeOtherSessions(user.ID, claims.SessionID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke sessions"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/MKolega/AirsoftHubCroatia/internal/db"
+	"github.com/MKolega/AirsoftHubCroatia/internal/storage"
+	"github.com/MKolega/AirsoftHubCroatia/types"
+)
+
+const objectDeleteTimeout = 30 * time.Second
+
+// trackUploads records uploaded objects before anything references them, so
+// an upload whose request later fails is still known to GC.
+func trackUploads(kind string, objects []storage.Object) ([]string, error) {
+	keys := make([]string, 0, len(objects))
+	rows := make([]types.StoredObject, 0, len(objects))
+	for _, o := range objects {
+		keys = append(keys, o.Key)
+		rows = append(rows, types.StoredObject{
+			Key:         o.Key,
+			URL:         o.URL,
+			Kind:        kind,
+			ContentType: o.ContentType,
+			SizeBytes:   o.Size,
+		})
+	}
+	if err := db.TrackStoredObjects(rows); err != nil {
+		discardObjects(keys)
+		return nil, err
+	}
+	return keys, nil
+}
+
+// discardObjects deletes objects from storage in the background. Objects that
+// fail to delete stay unattached in stored_objects and are retried by GC.
+func discardObjects(keys []string) {
+	if len(keys) == 0 {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), objectDeleteTimeout)
+		defer cancel()
+
+		store, err := storage.Default()
+		if err != nil {
+			log.Printf("storage: cannot delete %d objects: %v", len(keys), err)
+			return
+		}
+		deleted := make([]string, 0, len(keys))
+		for _, key := range keys {
+			if err := store.Delete(ctx, key); err != nil {
+				log.Printf("storage: failed to delete %s: %v", key, err)
+				continue
+			}
+			deleted = append(deleted, key)
+		}
+		if err := db.MarkStoredObjectsDeleted(deleted); err != nil {
+			log.Printf("storage: failed to mark %d objects deleted: %v", len(deleted), err)
+		}
+	}()
+}
+
+// attachEventThumbnail links newly uploaded thumbnail objects to the event
+// and removes the ones it no longer references.
+func attachEventThumbnail(eventID int, keys []string) {
+	if err := db.AttachStoredObjects(eventID, keys); err != nil {
+		log.Printf("storage: failed to attach thumbnail to event %d: %v", eventID, err)
+		return
+	}
+	releaseStaleThumbnails(eventID)
+}
+
+// releaseStaleThumbnails deletes the event's thumbnail objects that neither
+// its current thumbnail nor the approved version of a pending edit use.
+func releaseStaleThumbnails(eventID int) {
+	event, err := db.GetEventByID(eventID)
+	if err != nil {
+		log.Printf("storage: failed to release old thumbnails of event %d: %v", eventID, err)
+		return
+	}
+	keep := event.ThumbnailURLs()
+	if event.PreviousVersion != nil {
+		keep = append(keep, event.PreviousVersion.ThumbnailURLs()...)
+	}
+	stale, err := db.DetachEventObjects(eventID, db.ObjectKindThumbnail, keep)
+	if err != nil {
+		log.Printf("storage: failed to release old thumbnails of event %d: %v", eventID, err)
+		return
+	}
+	discardObjects(stale)
+}
@@ -0,0 +1,77 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/MKolega/AirsoftHubCroatia/types"
+	"github.com/uptrace/bun"
+)
+
+// DeletedEvent is a tombstone for an approved event that was deleted, kept so
+// calendar feeds can publish a cancellation instead of silently dropping it.
+type DeletedEvent struct {
+	bun.BaseModel `bun:"table:deleted_events,alias:de"`
+
+	EventID   int       `bun:"event_id,pk"`
+	Name      string    `bun:"name"`
+	StartsAt  time.Time `bun:"starts_at,nullzero"`
+	EndsAt    time.Time `bun:"ends_at,nullzero"`
+	Location  string    `bun:"location"`
+	Sequence  int       `bun:"sequence,notnull"`
+	CreatedAt time.Time `bun:"created_at,nullzero"`
+	DeletedAt time.Time `bun:"deleted_at,notnull"`
+}
+
+// GetDeletedEvents returns tombstones newer than since. When eventIDs is
+// non-nil only those events are considered.
+func GetDeletedEvents(since time.Time, eventIDs []int) ([]DeletedEvent, error) {
+	var rows []DeletedEvent
+	if eventIDs != nil && len(eventIDs) == 0 {
+		return rows, nil
+	}
+	q := Bun.NewSelect().Model(&rows).Where("deleted_at >= ?", since)
+	if eventIDs != nil {
+		q = q.Where("event_id IN (?)", bun.In(eventIDs))
+	}
+	if err := q.Order("deleted_at").Scan(context.Background()); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func GetUserByCalendarToken(token string) (*types.User, error) {
+	tok := strings.TrimSpace(token)
+	if tok == "" {
+		return nil, ErrUserNotFound
+	}
+	user := new(types.User)
+	err := Bun.NewSelect().Model(user).Where("calendar_token = ?", tok).Limit(1).Scan(context.Background())
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+// EnsureCalendarToken stores candidate as the user's calendar token unless
+// one is already set, and returns the token in effect.
+func EnsureCalendarToken(userID int, candidate string) (string, error) {
+	var token string
+	err := Bun.NewUpdate().
+		Model((*types.User)(nil)).
+		Set("calendar_token = COALESCE(calendar_token, ?)", candidate).
+		Where("id = ?", userID).
+		Returning("calendar_token").
+		Scan(context.Background(), &token)
+	return token, err
+}
+
+func SetCalendarToken(userID int, token string) error {
+	_, err := Bun.NewUpdate().
+		Model((*types.User)(nil)).
+		Set("calendar_token = ?", token).
+		Where("id = ?", userID).
+		Exec(context.Background())
+	return err
+}
@@ -0,0 +1,556 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/MKolega/AirsoftHubCroatia/types"
+	"github.com/uptrace/bun"
+)
+
+// Club member roles.
+const (
+	ClubRoleOwner   = "owner"
+	ClubRoleOfficer = "officer"
+	ClubRoleMember  = "member"
+)
+
+// Club membership statuses.
+const (
+	ClubMemberActive    = "active"
+	ClubMemberInvited   = "invited"
+	ClubMemberRequested = "requested"
+)
+
+var (
+	ErrClubNotFound       = errors.New("club not found")
+	ErrClubNameTaken      = errors.New("club name already used")
+	ErrClubSlugTaken      = errors.New("club slug already used")
+	ErrAlreadyInClub      = errors.New("user already belongs to a club")
+	ErrAlreadyClubMember  = errors.New("user is already a member")
+	ErrMembershipNotFound = errors.New("membership not found")
+	ErrLastClubOwner      = errors.New("club would be left without an owner")
+)
+
+// selectClubs selects clubs with their member count filled in.
+func selectClubs(model any) *bun.SelectQuery {
+	return Bun.NewSelect().
+		Model(model).
+		ColumnExpr("?TableAlias.*").
+		ColumnExpr("(SELECT count(*) FROM club_members AS cm WHERE cm.club_id = ?TableAlias.id AND cm.status = ?) AS member_count", ClubMemberActive)
+}
+
+func getClub(where string, arg any) (*types.Club, error) {
+	club := new(types.Club)
+	err := selectClubs(club).Where(where, arg).Limit(1).Scan(context.Background())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrClubNotFound
+		}
+		return nil, err
+	}
+	return club, nil
+}
+
+func GetClubByID(id int) (*types.Club, error) {
+	return getClub("?TableAlias.id = ?", id)
+}
+
+func GetClubBySlug(slug string) (*types.Club, error) {
+	return getClub("?TableAlias.slug = ?", slug)
+}
+
+// ListClubs returns clubs by name, optionally filtered by a name fragment.
+func ListClubs(query string) ([]types.Club, error) {
+	clubs := []types.Club{}
+	q := selectClubs(&clubs)
+	if text := strings.TrimSpace(query); text != "" {
+		q = q.Where("?TableAlias.name ILIKE ?", "%"+escapeLike(text)+"%")
+	}
+	if err := q.OrderExpr("lower(?TableAlias.name)").Scan(context.Background()); err != nil {
+		return nil, err
+	}
+	return clubs, nil
+}
+
+// clubConflict reports whether another club already uses the club's name or
+// slug.
+func clubConflict(ctx context.Context, idb bun.IDB, club *types.Club) error {
+	taken, err := idb.NewSelect().
+		Model((*types.Club)(nil)).
+		Where("lower(name) = lower(?)", club.Name).
+		Where("id <> ?", club.ID).
+		Exists(ctx)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return ErrClubNameTaken
+	}
+	taken, err = idb.NewSelect().
+		Model((*types.Club)(nil)).
+		Where("slug = ?", club.Slug).
+		Where("id <> ?", club.ID).
+		Exists(ctx)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return ErrClubSlugTaken
+	}
+	return nil
+}
+
+// CreateClub inserts the club with ownerID as its first owner.
+func CreateClub(club *types.Club, ownerID int) error {
+	ctx := context.Background()
+	return Bun.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if err := clubConflict(ctx, tx, club); err != nil {
+			return err
+		}
+		if _, err := tx.NewInsert().Model(club).Returning("*").Exec(ctx); err != nil {
+			return err
+		}
+		if err := joinClub(ctx, tx, club, ownerID, ClubRoleOwner); err != nil {
+			return err
+		}
+		club.MemberCount = 1
+		return nil
+	})
+}
+
+// UpdateClub writes the club's name, description and home field and renames
+// the club on its members' profiles.
+func UpdateClub(club *types.Club) error {
+	ctx := context.Background()
+	return Bun.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if err := clubConflict(ctx, tx, club); err != nil {
+			return err
+		}
+		res, err := tx.NewUpdate().
+			Model(club).
+			Column("name", "description", "home_field_id", "updated_at").
+			Value("updated_at", "now()").
+			WherePK().
+			Returning("*").
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return ErrClubNotFound
+		}
+		return setMembersClubName(ctx, tx, club.ID, club.Name)
+	})
+}
+
+// DeleteClub removes the club and its memberships. Its events stay, no
+// longer published on behalf of a club.
+func DeleteClub(id int) error {
+	ctx := context.Background()
+	return Bun.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if err := setMembersClubName(ctx, tx, id, types.FreelancerClub); err != nil {
+			return err
+		}
+		res, err := tx.NewDelete().Model((*types.Club)(nil)).Where("id = ?", id).Exec(ctx)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return ErrClubNotFound
+		}
+		return nil
+	})
+}
+
+func setMembersClubName(ctx context.Context, idb bun.IDB, clubID int, name string) error {
+	_, err := idb.NewUpdate().
+		Model((*types.User)(nil)).
+		Set("airsoft_club = ?", name).
+		Where("id IN (SELECT user_id FROM club_members WHERE club_id = ? AND status = ?)", clubID, ClubMemberActive).
+		Exec(ctx)
+	return err
+}
+
+func setUserClubName(ctx context.Context, idb bun.IDB, userID int, name string) error {
+	_, err := idb.NewUpdate().
+		Model((*types.User)(nil)).
+		Set("airsoft_club = ?", name).
+		Where("id = ?", userID).
+		Exec(ctx)
+	return err
+}
+
+// joinClub makes userID an active member of club with the given role and
+// drops the user's pending invites and requests elsewhere.
+func joinClub(ctx context.Context, tx bun.Tx, club *types.Club, userID int, role string) error {
+	inClub, err := tx.NewSelect().
+		Model((*types.ClubMember)(nil)).
+		Where("user_id = ?", userID).
+		Where("status = ?", ClubMemberActive).
+		Exists(ctx)
+	if err != nil {
+		return err
+	}
+	if inClub {
+		return ErrAlreadyInClub
+	}
+	_, err = tx.NewDelete().
+		Model((*types.ClubMember)(nil)).
+		Where("user_id = ?", userID).
+		Where("club_id <> ?", club.ID).
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+	member := &types.ClubMember{ClubID: club.ID, UserID: userID, Role: role, Status: ClubMemberActive}
+	_, err = tx.NewInsert().
+		Model(member).
+		Value("joined_at", "now()").
+		On("CONFLICT (club_id, user_id) DO UPDATE").
+		Set("status = EXCLUDED.status").
+		Set("joined_at = EXCLUDED.joined_at").
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+	return setUserClubName(ctx, tx, userID, club.Name)
+}
+
+// lockClubMember loads and locks the membership row; a missing row is
+// returned as nil.
+func lockClubMember(ctx context.Context, tx bun.Tx, clubID int, userID int) (*types.ClubMember, error) {
+	member := new(types.ClubMember)
+	err := tx.NewSelect().
+		Model(member).
+		Where("club_id = ? AND user_id = ?", clubID, userID).
+		For("UPDATE").
+		Scan(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return member, err
+}
+
+// changeClubMembership runs fn in a transaction with the club locked and the
+// user's membership row (nil if none) loaded.
+func changeClubMembership(clubID int, userID int, fn func(ctx context.Context, tx bun.Tx, club *types.Club, member *types.ClubMember) error) error {
+	ctx := context.Background()
+	return Bun.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		club := new(types.Club)
+		if err := tx.NewSelect().Model(club).Where("id = ?", clubID).For("UPDATE").Scan(ctx); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrClubNotFound
+			}
+			return err
+		}
+		member, err := lockClubMember(ctx, tx, clubID, userID)
+		if err != nil {
+			return err
+		}
+		return fn(ctx, tx, club, member)
+	})
+}
+
+// RequestClubMembership asks to join a club. A pending invite is accepted
+// instead.
+func RequestClubMembership(clubID int, userID int) (*types.ClubMember, error) {
+	err := changeClubMembership(clubID, userID, func(ctx context.Context, tx bun.Tx, club *types.Club, member *types.ClubMember) error {
+		switch {
+		case member == nil:
+			_, err := tx.NewInsert().
+				Model(&types.ClubMember{ClubID: clubID, UserID: userID, Role: ClubRoleMember, Status: ClubMemberRequested}).
+				Exec(ctx)
+			return err
+		case member.Status == ClubMemberInvited:
+			return joinClub(ctx, tx, club, userID, member.Role)
+		case member.Status == ClubMemberActive:
+			return ErrAlreadyClubMember
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return GetClubMember(clubID, userID)
+}
+
+// InviteClubMember invites a user to the club. A pending join request is
+// accepted instead.
+func InviteClubMember(clubID int, userID int, invitedBy int) (*types.ClubMember, error) {
+	err := changeClubMembership(clubID, userID, func(ctx context.Context, tx bun.Tx, club *types.Club, member *types.ClubMember) error {
+		switch {
+		case member == nil:
+			_, err := tx.NewInsert().
+				Model(&types.ClubMember{ClubID: clubID, UserID: userID, Role: ClubRoleMember, Status: ClubMemberInvited, InvitedBy: invitedBy}).
+				Exec(ctx)
+			return err
+		case member.Status == ClubMemberRequested:
+			return joinClub(ctx, tx, club, userID, member.Role)
+		case member.Status == ClubMemberActive:
+			return ErrAlreadyClubMember
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return GetClubMember(clubID, userID)
+}
+
+// AcceptClubMembership turns a membership with the given pending status into
+// an active one.
+func AcceptClubMembership(clubID int, userID int, status string) (*types.ClubMember, error) {
+	err := changeClubMembership(clubID, userID, func(ctx context.Context, tx bun.Tx, club *types.Club, member *types.ClubMember) error {
+		if member == nil || member.Status != status {
+			return ErrMembershipNotFound
+		}
+		return joinClub(ctx, tx, club, userID, member.Role)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return GetClubMember(clubID, userID)
+}
+
+// countOtherOwners counts the club's owners other than userID.
+func countOtherOwners(ctx context.Context, tx bun.Tx, clubID int, userID int) (int, error) {
+	return tx.NewSelect().
+		Model((*types.ClubMember)(nil)).
+		Where("club_id = ?", clubID).
+		Where("user_id <> ?", userID).
+		Where("status = ?", ClubMemberActive).
+		Where("role = ?", ClubRoleOwner).
+		Count(ctx)
+}
+
+// RemoveClubMember deletes a membership, invite or join request. The last
+// owner cannot leave.
+func RemoveClubMember(clubID int, userID int) error {
+	return changeClubMembership(clubID, userID, func(ctx context.Context, tx bun.Tx, club *types.Club, member *types.ClubMember) error {
+		if member == nil {
+			return ErrMembershipNotFound
+		}
+		if member.Status == ClubMemberActive && member.Role == ClubRoleOwner {
+			owners, err := countOtherOwners(ctx, tx, clubID, userID)
+			if err != nil {
+				return err
+			}
+			if owners == 0 {
+				return ErrLastClubOwner
+			}
+		}
+		if _, err := tx.NewDelete().Model(member).WherePK().Exec(ctx); err != nil {
+			return err
+		}
+		if member.Status == ClubMemberActive {
+			return setUserClubName(ctx, tx, userID, types.FreelancerClub)
+		}
+		return nil
+	})
+}
+
+// SetClubMemberRole changes an active member's role. The last owner cannot
+// step down.
+func SetClubMemberRole(clubID int, userID int, role string) (*types.ClubMember, error) {
+	err := changeClubMembership(clubID, userID, func(ctx context.Context, tx bun.Tx, club *types.Club, member *types.ClubMember) error {
+		if member == nil || member.Status != ClubMemberActive {
+			return ErrMembershipNotFound
+		}
+		if member.Role == ClubRoleOwner && role != ClubRoleOwner {
+			owners, err := countOtherOwners(ctx, tx, clubID, userID)
+			if err != nil {
+				return err
+			}
+			if owners == 0 {
+				return ErrLastClubOwner
+			}
+		}
+		_, err := tx.NewUpdate().
+			Model((*types.ClubMember)(nil)).
+			Set("role = ?", role).
+			Where("club_id = ? AND user_id = ?", clubID, userID).
+			Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return GetClubMember(clubID, userID)
+}
+
+func GetClubMember(clubID int, userID int) (*types.ClubMember, error) {
+	member := new(types.ClubMember)
+	err := Bun.NewSelect().
+		Model(member).
+		ColumnExpr("?TableAlias.*").
+		ColumnExpr("u.username").
+		Join("JOIN users AS u ON u.id = ?TableAlias.user_id").
+		Where("?TableAlias.club_id = ? AND ?TableAlias.user_id = ?", clubID, userID).
+		Limit(1).
+		Scan(context.Background())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrMembershipNotFound
+		}
+		return nil, err
+	}
+	return member, nil
+}
+
+// GetClubRole returns the user's role in the club, or "" if they are not an
+// active member.
+func GetClubRole(clubID int, userID int) (string, error) {
+	var role string
+	err := Bun.NewSelect().
+		Model((*types.ClubMember)(nil)).
+		Column("role").
+		Where("club_id = ? AND user_id = ?", clubID, userID).
+		Where("status = ?", ClubMemberActive).
+		Limit(1).
+		Scan(context.Background(), &role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return role, err
+}
+
+// GetClubMembers lists the club's memberships with the given statuses,
+// owners and officers first.
+func GetClubMembers(clubID int, statuses ...string) ([]types.ClubMember, error) {
+	members := []types.ClubMember{}
+	err := Bun.NewSelect().
+		Model(&members).
+		ColumnExpr("?TableAlias.*").
+		ColumnExpr("u.username").
+		Join("JOIN users AS u ON u.id = ?TableAlias.user_id").
+		Where("?TableAlias.club_id = ?", clubID).
+		Where("?TableAlias.status IN (?)", bun.In(statuses)).
+		OrderExpr("CASE ?TableAlias.role WHEN ? THEN 0 WHEN ? THEN 1 ELSE 2 END", ClubRoleOwner, ClubRoleOfficer).
+		OrderExpr("lower(u.username), ?TableAlias.user_id").
+		Scan(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// GetUserClubMemberships lists a user's club, invites and join requests.
+func GetUserClubMemberships(userID int) ([]types.ClubMember, error) {
+	members := []types.ClubMember{}
+	err := Bun.NewSelect().
+		Model(&members).
+		ColumnExpr("?TableAlias.*").
+		ColumnExpr("c.slug AS club_slug, c.name AS club_name").
+		Join("JOIN clubs AS c ON c.id = ?TableAlias.club_id").
+		Where("?TableAlias.user_id = ?", userID).
+		OrderExpr("?TableAlias.status = ? DESC", ClubMemberActive).
+		OrderExpr("?TableAlias.created_at DESC").
+		Scan(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// GetUserClub returns the user's active membership with the club's slug and
+// name, or nil if they are not in a club.
+func GetUserClub(userID int) (*types.ClubMember, error) {
+	member := new(types.ClubMember)
+	err := Bun.NewSelect().
+		Model(member).
+		ColumnExpr("?TableAlias.*").
+		ColumnExpr("c.slug AS club_slug, c.name AS club_name").
+		Join("JOIN clubs AS c ON c.id = ?TableAlias.club_id").
+		Where("?TableAlias.user_id = ?", userID).
+		Where("?TableAlias.status = ?", ClubMemberActive).
+		Limit(1).
+		Scan(context.Background())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return member, nil
+}
+
+// GetClubEvents returns published events of the club, ordered like
+// GetFieldEvents.
+func GetClubEvents(clubID int, upcoming bool, limit int) ([]types.Event, error) {
+	return linkedEvents("club_id", clubID, upcoming, limit)
+}
+
+// SetClubLogo points the club's logo at url, attaches the object under key to
+// the club and returns the keys of the replaced logo for deletion.
+func SetClubLogo(clubID int, url string, key string) ([]string, error) {
+	var old []string
+	ctx := context.Background()
+	err := Bun.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		res, err := tx.NewUpdate().
+			Model((*types.Club)(nil)).
+			Set("logo = ?", url).
+			Set("updated_at = now()").
+			Where("id = ?", clubID).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return ErrClubNotFound
+		}
+		_, err = tx.NewUpdate().
+			Model((*types.StoredObject)(nil)).
+			Set("club_id = NULL").
+			Where("club_id = ?", clubID).
+			Where("kind = ?", ObjectKindClubLogo).
+			Where("key <> ?", key).
+			Where("deleted_at IS NULL").
+			Returning("key").
+			Exec(ctx, &old)
+		if err != nil {
+			return err
+		}
+		_, err = tx.NewUpdate().
+			Model((*types.StoredObject)(nil)).
+			Set("club_id = ?", clubID).
+			Where("key = ?", key).
+			Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return old, nil
+}
+
+// ClubStoredObjectKeys lists the live objects attached to a club.
+func ClubStoredObjectKeys(clubID int) ([]string, error) {
+	var keys []string
+	err := Bun.NewSelect().
+		Model((*types.StoredObject)(nil)).
+		Column("key").
+		Where("club_id = ?", clubID).
+		Where("deleted_at IS NULL").
+		Scan(context.Background(), &keys)
+	return keys, err
+}
+
+// dropDeletedClub unlinks a restored snapshot from a club that no longer
+// exists.
+func dropDeletedClub(ctx context.Context, idb bun.IDB, event *types.Event) error {
+	if event.ClubID == 0 {
+		return nil
+	}
+	exists, err := idb.NewSelect().
+		Model((*types.Club)(nil)).
+		Where("id = ?", event.ClubID).
+		Exists(ctx)
+	if err != nil || exists {
+		return err
+	}
+	event.ClubID = 0
+	return nil
+}
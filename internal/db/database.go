@@ -3,12 +3,17 @@ package db
 import (
 	"context"
 	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"net/url"
 	"os"
+	"slices"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/MKolega/AirsoftHubCroatia/internal/config"
 	"github.com/MKolega/AirsoftHubCroatia/types"
@@ -123,7 +128,8 @@ func CreateDatabase() (*sql.DB, error) {
 	return db, nil
 }
 
-func Init() error {
+// Connect opens the database (creating it if needed) without touching the schema.
+func Connect() error {
 	db, err := CreateDatabase()
 	if err != nil {
 		return err
@@ -132,32 +138,34 @@ func Init() error {
 	if dbDebugEnabled() {
 		Bun.AddQueryHook(bundebug.NewQueryHook())
 	}
+	return nil
+}
 
-	err = CreateUsersTable()
-	if err != nil {
-		return err
-	}
-	if err := PromoteAdminsFromEnv(); err != nil {
-		return err
-	}
-	if err := PromoteMaintenanceUsersFromEnv(); err != nil {
+// Init connects, brings the schema up to date and applies env bootstrap data.
+func Init() error {
+	if err := Connect(); err != nil {
 		return err
 	}
 
-	err = CreateSavedEventsTable()
-	if err != nil {
+	if err := EnsureSchema(context.Background(), autoMigrateEnabled()); err != nil {
 		return err
 	}
 
-	err = CreateEventsTable()
-	if err != nil {
+	if err := BootstrapAdminsFromEnv(); err != nil {
 		return err
 	}
-	err = SeedEventsTable()
-	if err != nil {
-		return err
+
+	return SeedEventsTable()
+}
+
+func autoMigrateEnabled() bool {
+	v := strings.TrimSpace(config.GetEnv("DB_AUTO_MIGRATE", "true"))
+	switch strings.ToLower(v) {
+	case "0", "false", "no", "n", "off":
+		return false
+	default:
+		return true
 	}
-	return nil
 }
 
 func dbDebugEnabled() bool {
@@ -173,101 +181,37 @@ func dbDebugEnabled() bool {
 	}
 }
 
-func CreateEventsTable() error {
-	query := `CREATE TABLE IF NOT EXISTS events (
-			id SERIAL PRIMARY KEY,
-			created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
-			status TEXT NOT NULL DEFAULT 'approved',
-			rejection_reason TEXT,
-			reviewed_at TIMESTAMPTZ,
-			reviewed_by_email TEXT,
-			name TEXT NOT NULL,
-			description TEXT,
-			detailed_description TEXT,
-			creator_email TEXT,
-			lat DOUBLE PRECISION,
-			lng DOUBLE PRECISION,
-			location TEXT,
-			date DATE,
-			category TEXT DEFAULT 'Skirmish',
-			facebook_link TEXT,
-			thumbnail TEXT
-		);`
-	_, err := Bun.ExecContext(context.Background(), query)
-	if err != nil {
-		return err
-	}
+var ErrEventNotFound = errors.New("event not found")
 
-	// Ensure columns exist for older databases.
-	if _, err := Bun.ExecContext(context.Background(), `ALTER TABLE events ADD COLUMN IF NOT EXISTS category TEXT;`); err != nil {
-		return err
-	}
-	// Default and backfill for older rows.
-	if _, err := Bun.ExecContext(context.Background(), `ALTER TABLE events ALTER COLUMN category SET DEFAULT 'Skirmish';`); err != nil {
-		return err
-	}
-	if _, err := Bun.ExecContext(context.Background(), `UPDATE events SET category='Skirmish' WHERE category IS NULL OR category='';`); err != nil {
-		return err
-	}
-	if _, err := Bun.ExecContext(context.Background(), `ALTER TABLE events ADD COLUMN IF NOT EXISTS facebook_link TEXT;`); err != nil {
-		return err
-	}
-	if _, err := Bun.ExecContext(context.Background(), `ALTER TABLE events ADD COLUMN IF NOT EXISTS detailed_description TEXT;`); err != nil {
-		return err
-	}
-	if _, err := Bun.ExecContext(context.Background(), `ALTER TABLE events ADD COLUMN IF NOT EXISTS creator_email TEXT;`); err != nil {
-		return err
-	}
-	if _, err := Bun.ExecContext(context.Background(), `ALTER TABLE events ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ;`); err != nil {
-		return err
-	}
-	if _, err := Bun.ExecContext(context.Background(), `ALTER TABLE events ALTER COLUMN created_at SET DEFAULT now();`); err != nil {
-		return err
-	}
-	if _, err := Bun.ExecContext(context.Background(), `UPDATE events SET created_at=now() WHERE created_at IS NULL;`); err != nil {
-		return err
-	}
-	if _, err := Bun.ExecContext(context.Background(), `ALTER TABLE events ALTER COLUMN created_at SET NOT NULL;`); err != nil {
-		return err
-	}
-	if _, err := Bun.ExecContext(context.Background(), `ALTER TABLE events ADD COLUMN IF NOT EXISTS thumbnail TEXT;`); err != nil {
-		return err
-	}
-	if _, err := Bun.ExecContext(context.Background(), `ALTER TABLE events ADD COLUMN IF NOT EXISTS status TEXT;`); err != nil {
-		return err
-	}
-	if _, err := Bun.ExecContext(context.Background(), `ALTER TABLE events ALTER COLUMN status SET DEFAULT 'approved';`); err != nil {
-		return err
-	}
-	if _, err := Bun.ExecContext(context.Background(), `UPDATE events SET status='approved' WHERE status IS NULL OR status='';`); err != nil {
-		return err
-	}
-	if _, err := Bun.ExecContext(context.Background(), `ALTER TABLE events ALTER COLUMN status SET NOT NULL;`); err != nil {
-		return err
-	}
-	if _, err := Bun.ExecContext(context.Background(), `ALTER TABLE events ADD COLUMN IF NOT EXISTS rejection_reason TEXT;`); err != nil {
-		return err
-	}
-	if _, err := Bun.ExecContext(context.Background(), `ALTER TABLE events ADD COLUMN IF NOT EXISTS reviewed_at TIMESTAMPTZ;`); err != nil {
-		return err
-	}
-	if _, err := Bun.ExecContext(context.Background(), `ALTER TABLE events ADD COLUMN IF NOT EXISTS reviewed_by_email TEXT;`); err != nil {
-		return err
+func GetEventByID(id int) (*types.Event, error) {
+	event := new(types.Event)
+	err := Bun.NewSelect().Model(event).Where("id = ?", id).Limit(1).Scan(context.Background())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrEventNotFound
+		}
+		return nil, err
 	}
-	return nil
+	return event, nil
 }
 
+// CountEventsByCreatorInRange counts the events the creator submitted in the
+// range. It reads the revision log, so events deleted since still count.
 func CountEventsByCreatorInRange(creatorEmail string, start time.Time, end time.Time) (int, error) {
-	return Bun.NewSelect().
-		Model((*types.Event)(nil)).
-		Where("creator_email = ?", creatorEmail).
+	return countEventsByCreatorInRange(context.Background(), Bun, creatorEmail, start, end)
+}
+
+func countEventsByCreatorInRange(ctx context.Context, idb bun.IDB, creatorEmail string, start time.Time, end time.Time) (int, error) {
+	return idb.NewSelect().
+		Model((*types.EventRevision)(nil)).
+		Where("action = ?", EventActionCreated).
+		Where("actor_email = ?", strings.TrimSpace(creatorEmail)).
 		Where("created_at >= ? AND created_at < ?", start, end).
-		Count(context.Background())
+		Count(ctx)
 }
 
 func GetEventsFromDB() ([]types.Event, error) {
-	var events []types.Event
-	err := Bun.NewSelect().Model(&events).Where("status = ?", "approved").Order("date").Scan(context.Background())
+	events, _, err := ListEvents(EventListOptions{})
 	if err != nil {
 		return nil, err
 	}
@@ -305,25 +249,71 @@ func ReviewEvent(eventID int, status string, reviewedByEmail string, rejectionRe
 		return fmt.Errorf("invalid status")
 	}
 
-	var reason any
+	var reason string
 	if rejectionReason != nil {
-		r := strings.TrimSpace(*rejectionReason)
-		if r != "" {
-			reason = r
-		}
+		reason = strings.TrimSpace(*rejectionReason)
 	}
 
 	adminEmail := strings.TrimSpace(reviewedByEmail)
 
-	_, err := Bun.NewUpdate().
-		Model((*types.Event)(nil)).
-		Set("status = ?", st).
-		Set("rejection_reason = ?", reason).
-		Set("reviewed_at = now()").
-		Set("reviewed_by_email = ?", adminEmail).
-		Where("id = ?", eventID).
-		Exec(context.Background())
-	return err
+	ctx := context.Background()
+	return Bun.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		current := new(types.Event)
+		err := tx.NewSelect().Model(current).Where("id = ?", eventID).For("UPDATE").Scan(ctx)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrEventNotFound
+			}
+			return err
+		}
+		if st == "rejected" && current.PreviousVersion != nil {
+			return rejectEventEdit(ctx, tx, current, reason, adminEmail)
+		}
+
+		_, err = tx.NewUpdate().
+			Model((*types.Event)(nil)).
+			Set("status = ?", st).
+			Set("rejection_reason = NULLIF(?, '')", reason).
+			Set("reviewed_at = now()").
+			Set("reviewed_by_email = ?", adminEmail).
+			Set("previous_version = NULL").
+			Where("id = ?", eventID).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		return recordEventRevision(ctx, tx, eventID, st, adminEmail)
+	})
+}
+
+// rejectEventEdit turns down a pending edit of an approved event. The edit is
+// kept as a rejected revision carrying the reason, and the event goes back to
+// its approved version.
+func rejectEventEdit(ctx context.Context, tx bun.Tx, edit *types.Event, reason string, reviewerEmail string) error {
+	rejected := *edit
+	rejected.Status = "rejected"
+	rejected.RejectionReason = reason
+	if err := insertEventRevision(ctx, tx, &rejected, EventActionRejected, reviewerEmail); err != nil {
+		return err
+	}
+
+	restored := *edit.PreviousVersion
+	restored.ID = edit.ID
+	restored.Status = "approved"
+	restored.RejectionReason = ""
+	restored.PreviousVersion = nil
+	restored.ReviewedAt = time.Now()
+	restored.ReviewedByEmail = reviewerEmail
+	if err := dropDeletedLinks(ctx, tx, &restored); err != nil {
+		return err
+	}
+	if err := writeEventSnapshot(ctx, tx, &restored, "reviewed_at", "reviewed_by_email"); err != nil {
+		return err
+	}
+	if err := reattachThumbnail(ctx, tx, &restored); err != nil {
+		return err
+	}
+	return recordEventRevision(ctx, tx, edit.ID, EventActionRestored, reviewerEmail)
 }
 
 func SeedEventsTable() error {
@@ -335,34 +325,336 @@ func SeedEventsTable() error {
 		// Table already seeded
 		return nil
 	}
+	loc, err := types.LoadEventLocation(types.DefaultEventTimeZone)
+	if err != nil {
+		return err
+	}
+	start1 := time.Date(2024, 7, 1, 9, 0, 0, 0, loc)
+	start2 := time.Date(2024, 7, 15, 9, 0, 0, 0, loc)
 	events := []types.Event{
-		{Status: "approved", Name: "Event 1", Description: "Desc 1", DetailedDescription: "More details for Event 1", Location: "Croatia", Lat: 45.0, Lng: 16.0, Date: "2024-07-01", Category: "Skirmish", FacebookLink: "https://www.facebook.com/events/792766179793560"},
-		{Status: "approved", Name: "Event 2", Description: "Desc 2", DetailedDescription: "More details for Event 2", Location: "Croatia", Lat: 46.0, Lng: 17.0, Date: "2024-07-15", Category: "Skirmish", FacebookLink: "https://www.facebook.com/events/2075916069838446"},
+		{Status: "approved", Name: "Event 1", Description: "Desc 1", DetailedDescription: "More details for Event 1", Location: "Croatia", Lat: 45.0, Lng: 16.0, StartsAt: start1, EndsAt: start1.Add(6 * time.Hour), TimeZone: types.DefaultEventTimeZone, Category: "Skirmish", FacebookLink: "https://www.facebook.com/events/792766179793560"},
+		{Status: "approved", Name: "Event 2", Description: "Desc 2", DetailedDescription: "More details for Event 2", Location: "Croatia", Lat: 46.0, Lng: 17.0, StartsAt: start2, EndsAt: start2.Add(6 * time.Hour), TimeZone: types.DefaultEventTimeZone, Category: "Skirmish", FacebookLink: "https://www.facebook.com/events/2075916069838446"},
 	}
 	_, err = Bun.NewInsert().Model(&events).Exec(context.Background())
 	return err
 }
 
 func InsertEventToDB(event *types.Event) error {
-	_, err := Bun.NewInsert().Model(event).Exec(context.Background())
-	return err
+	return InsertEventWithinQuota(event, -1, time.Time{}, time.Time{})
 }
 
-func UpdateEventInDB(id string, event *types.Event) error {
-	_, err := Bun.NewUpdate().Model(event).Where("id = ?", id).Exec(context.Background())
-	return err
+var ErrEventQuotaExceeded = errors.New("event quota exceeded")
+
+// InsertEventWithinQuota inserts the event unless its creator already
+// submitted limit events between start and end. Inserts by the same creator
+// are serialized with an advisory lock so concurrent submissions cannot both
+// pass the count. A negative limit skips the check.
+func InsertEventWithinQuota(event *types.Event, limit int, start time.Time, end time.Time) error {
+	ctx := context.Background()
+	return Bun.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if limit >= 0 {
+			email := strings.TrimSpace(event.CreatorEmail)
+			if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext(?))`, email); err != nil {
+				return err
+			}
+			used, err := countEventsByCreatorInRange(ctx, tx, email, start, end)
+			if err != nil {
+				return err
+			}
+			if used >= limit {
+				return ErrEventQuotaExceeded
+			}
+		}
+		if _, err := tx.NewInsert().Model(event).Exec(ctx); err != nil {
+			return err
+		}
+		return insertEventRevision(ctx, tx, event, EventActionCreated, event.CreatorEmail)
+	})
+}
+
+// UpdateEventInDBColumns updates the given columns (all when empty) and
+// records a revision attributed to actorEmail. Every edit bumps sequence and
+// updated_at so calendar clients pick up the change for the same UID.
+func UpdateEventInDBColumns(id string, actorEmail string, event *types.Event, columns ...string) error {
+	ctx := context.Background()
+	return Bun.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		q := tx.NewUpdate().Model(event)
+		if len(columns) > 0 {
+			cols := make([]string, 0, len(columns)+2)
+			cols = append(cols, columns...)
+			q = q.Column(append(cols, "sequence", "updated_at")...)
+		}
+		_, err := q.
+			Value("sequence", "sequence + 1").
+			Value("updated_at", "now()").
+			Where("id = ?", id).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		if err := recordEventRevision(ctx, tx, id, EventActionUpdated, actorEmail); err != nil {
+			return err
+		}
+		// A raised (or removed) player cap frees spots for the waitlist.
+		if len(columns) == 0 || slices.Contains(columns, "max_players") {
+			locked := new(types.Event)
+			if err := tx.NewSelect().Model(locked).Where("id = ?", id).For("UPDATE").Scan(ctx); err != nil {
+				return err
+			}
+			return promoteWaitlist(ctx, tx, locked.ID, locked.MaxPlayers)
+		}
+		return nil
+	})
+}
+
+// DeleteEventFromDB removes the event, recording a final revision and
+// leaving a tombstone for published events (including ones with an edit in
+// review) so subscribed calendars receive a cancellation.
+func DeleteEventFromDB(id string, actorEmail string) error {
+	ctx := context.Background()
+	return Bun.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if err := recordEventRevision(ctx, tx, id, EventActionDeleted, actorEmail); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil
+			}
+			return err
+		}
+		event := new(types.Event)
+		if err := tx.NewSelect().Model(event).Where("id = ?", id).Scan(ctx); err != nil {
+			return err
+		}
+		// An edit in review is served from its approved snapshot, which is
+		// what subscribers have and what the cancellation must describe.
+		published := event
+		if event.PreviousVersion != nil {
+			published = event.PreviousVersion
+		} else if event.Status != "approved" {
+			published = nil
+		}
+		if published != nil {
+			tombstone := &DeletedEvent{
+				EventID:   event.ID,
+				Name:      published.Name,
+				StartsAt:  published.StartsAt,
+				EndsAt:    published.EndsAt,
+				Location:  published.Location,
+				Sequence:  event.Sequence + 1,
+				CreatedAt: event.CreatedAt,
+				DeletedAt: time.Now(),
+			}
+			if _, err := tx.NewInsert().
+				Model(tombstone).
+				On("CONFLICT (event_id) DO UPDATE").
+				Set("name = EXCLUDED.name").
+				Set("starts_at = EXCLUDED.starts_at").
+				Set("ends_at = EXCLUDED.ends_at").
+				Set("location = EXCLUDED.location").
+				Set("sequence = EXCLUDED.sequence").
+				Set("deleted_at = EXCLUDED.deleted_at").
+				Exec(ctx); err != nil {
+				return err
+			}
+		}
+		_, err := tx.NewDelete().Model((*types.Event)(nil)).Where("id = ?", id).Exec(ctx)
+		return err
+	})
 }
 
-func UpdateEventInDBColumns(id string, event *types.Event, columns ...string) error {
-	q := Bun.NewUpdate().Model(event)
-	if len(columns) > 0 {
-		q = q.Column(columns...)
+var (
+	ErrInvalidEventSort   = errors.New("invalid sort")
+	ErrInvalidEventCursor = errors.New("invalid cursor")
+)
+
+// EventListOptions narrows and pages the public events listing.
+// Zero values mean "no filter"; Limit <= 0 returns every matching row.
+type EventListOptions struct {
+	From     string
+	To       string
+	Category string
+	Query    string
+	Sort     string
+	Limit    int
+	Cursor   string
+}
+
+type eventSortSpec struct {
+	expr  string
+	cast  string
+	desc  bool
+	value func(e *types.Event) string
+	// valid reports whether a cursor value can be cast to the sort column.
+	valid func(v string) bool
+}
+
+func eventStartsAtCursorValue(e *types.Event) string {
+	if e.StartsAt.IsZero() {
+		return "infinity"
 	}
-	_, err := q.Where("id = ?", id).Exec(context.Background())
-	return err
+	return e.StartsAt.UTC().Format(time.RFC3339Nano)
 }
 
-func DeleteEventFromDB(id string) error {
-	_, err := Bun.NewDelete().Model((*types.Event)(nil)).Where("id = ?", id).Exec(context.Background())
-	return err
+func eventCreatedAtCursorValue(e *types.Event) string {
+	return e.CreatedAt.UTC().Format(time.RFC3339Nano)
+}
+
+func eventNameCursorValue(e *types.Event) string {
+	return e.Name
+}
+
+func validTimeCursorValue(v string) bool {
+	_, err := time.Parse(time.RFC3339Nano, v)
+	return err == nil
+}
+
+func validStartsAtCursorValue(v string) bool {
+	return v == "infinity" || validTimeCursorValue(v)
+}
+
+func validTextCursorValue(v string) bool {
+	return utf8.ValidString(v) && !strings.ContainsRune(v, 0)
+}
+
+var eventSorts = map[string]eventSortSpec{
+	"date":        {expr: "COALESCE(starts_at, 'infinity'::timestamptz)", cast: "timestamptz", value: eventStartsAtCursorValue, valid: validStartsAtCursorValue},
+	"-date":       {expr: "COALESCE(starts_at, 'infinity'::timestamptz)", cast: "timestamptz", desc: true, value: eventStartsAtCursorValue, valid: validStartsAtCursorValue},
+	"created_at":  {expr: "created_at", cast: "timestamptz", value: eventCreatedAtCursorValue, valid: validTimeCursorValue},
+	"-created_at": {expr: "created_at", cast: "timestamptz", desc: true, value: eventCreatedAtCursorValue, valid: validTimeCursorValue},
+	"name":        {expr: "name", cast: "text", value: eventNameCursorValue, valid: validTextCursorValue},
+	"-name":       {expr: "name", cast: "text", desc: true, value: eventNameCursorValue, valid: validTextCursorValue},
+}
+
+// ValidEventSort reports whether sort is accepted by ListEvents.
+func ValidEventSort(sort string) bool {
+	_, ok := eventSorts[sort]
+	return ok
+}
+
+type eventCursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    int    `json:"id"`
+}
+
+func encodeEventCursor(cur eventCursor) string {
+	raw, _ := json.Marshal(cur)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodeEventCursor parses a cursor issued for sortKey, rejecting values the
+// sort column could not be compared against.
+func decodeEventCursor(s string, sortKey string) (eventCursor, error) {
+	var cur eventCursor
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cur, ErrInvalidEventCursor
+	}
+	if err := json.Unmarshal(raw, &cur); err != nil || cur.ID <= 0 {
+		return cur, ErrInvalidEventCursor
+	}
+	spec, ok := eventSorts[cur.Sort]
+	if !ok || cur.Sort != sortKey || !spec.valid(cur.Value) {
+		return cur, ErrInvalidEventCursor
+	}
+	return cur, nil
+}
+
+// whereEventInDateRange keeps events overlapping the inclusive local-date
+// range [from, to] (YYYY-MM-DD, either may be empty), where days are counted
+// in the default event time zone.
+func whereEventInDateRange(q *bun.SelectQuery, from string, to string) *bun.SelectQuery {
+	if from = strings.TrimSpace(from); from != "" {
+		q = q.Where("COALESCE(ends_at, starts_at) >= (?::date)::timestamp AT TIME ZONE ?", from, types.DefaultEventTimeZone)
+	}
+	if to = strings.TrimSpace(to); to != "" {
+		q = q.Where("starts_at < (?::date + 1)::timestamp AT TIME ZONE ?", to, types.DefaultEventTimeZone)
+	}
+	return q
+}
+
+func escapeLike(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return r.Replace(s)
+}
+
+// wherePublished keeps the events the public can see: approved ones and ones
+// whose edit awaits review, which stay published in their approved version.
+func wherePublished(q *bun.SelectQuery) *bun.SelectQuery {
+	return q.WhereGroup(" AND ", func(sq *bun.SelectQuery) *bun.SelectQuery {
+		return sq.Where("status = ?", "approved").WhereOr("previous_version IS NOT NULL")
+	})
+}
+
+// publishEvents replaces events whose edit awaits review with their approved
+// version.
+func publishEvents(events []types.Event) {
+	for i := range events {
+		events[i] = *events[i].PublishedVersion()
+	}
+}
+
+// ListEvents returns published events matching opts using keyset pagination.
+// nextCursor is empty when there are no further pages.
+func ListEvents(opts EventListOptions) (events []types.Event, nextCursor string, err error) {
+	sortKey := strings.TrimSpace(opts.Sort)
+	if sortKey == "" {
+		sortKey = "date"
+	}
+	spec, ok := eventSorts[sortKey]
+	if !ok {
+		return nil, "", ErrInvalidEventSort
+	}
+
+	q := wherePublished(Bun.NewSelect().Model(&events))
+
+	q = whereEventInDateRange(q, opts.From, opts.To)
+	if cat := strings.TrimSpace(opts.Category); cat != "" {
+		q = q.Where("category = ?", cat)
+	}
+	if text := strings.TrimSpace(opts.Query); text != "" {
+		pattern := "%" + escapeLike(text) + "%"
+		q = q.WhereGroup(" AND ", func(sq *bun.SelectQuery) *bun.SelectQuery {
+			return sq.
+				Where("name ILIKE ?", pattern).
+				WhereOr("description ILIKE ?", pattern).
+				WhereOr("detailed_description ILIKE ?", pattern).
+				WhereOr("location ILIKE ?", pattern)
+		})
+	}
+
+	if c := strings.TrimSpace(opts.Cursor); c != "" {
+		cur, err := decodeEventCursor(c, sortKey)
+		if err != nil {
+			return nil, "", err
+		}
+		op := ">"
+		if spec.desc {
+			op = "<"
+		}
+		q = q.Where("("+spec.expr+", id) "+op+" (?::"+spec.cast+", ?)", cur.Value, cur.ID)
+	}
+
+	dir := "ASC"
+	if spec.desc {
+		dir = "DESC"
+	}
+	q = q.OrderExpr(spec.expr + " " + dir).OrderExpr("id " + dir)
+
+	if opts.Limit > 0 {
+		q = q.Limit(opts.Limit + 1)
+	}
+
+	if err := q.Scan(context.Background()); err != nil {
+		return nil, "", err
+	}
+	if events == nil {
+		events = []types.Event{}
+	}
+
+	if opts.Limit > 0 && len(events) > opts.Limit {
+		events = events[:opts.Limit]
+		last := &events[len(events)-1]
+		nextCursor = encodeEventCursor(eventCursor{Sort: sortKey, Value: spec.value(last), ID: last.ID})
+	}
+	publishEvents(events)
+	return events, nextCursor, nil
 }
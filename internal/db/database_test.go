@@ -0,0 +1,69 @@
+package db
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+func TestEventCursorRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		cur  eventCursor
+	}{
+		{"date", eventCursor{Sort: "date", Value: "2026-05-01T08:00:00Z", ID: 12}},
+		{"no start", eventCursor{Sort: "-date", Value: "infinity", ID: 1}},
+		{"name with symbols", eventCursor{Sort: "name", Value: "Šuma & \"Grad\" / 2", ID: 987654}},
+		{"empty value", eventCursor{Sort: "-name", Value: "", ID: 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeEventCursor(encodeEventCursor(tt.cur), tt.cur.Sort)
+			if err != nil {
+				t.Fatalf("decodeEventCursor: %v", err)
+			}
+			if got != tt.cur {
+				t.Errorf("got %+v, want %+v", got, tt.cur)
+			}
+		})
+	}
+}
+
+func TestEncodeEventCursorIsURLSafe(t *testing.T) {
+	s := encodeEventCursor(eventCursor{Sort: "name", Value: "??>>~~", ID: 1})
+	for _, r := range s {
+		if r == '+' || r == '/' || r == '=' {
+			t.Fatalf("cursor %q is not URL safe", s)
+		}
+	}
+}
+
+func TestDecodeEventCursorRejectsInvalid(t *testing.T) {
+	enc := func(raw string) string { return base64.RawURLEncoding.EncodeToString([]byte(raw)) }
+	tests := []struct {
+		name   string
+		sort   string
+		cursor string
+	}{
+		{"not base64", "date", "!!!"},
+		{"padded base64", "date", base64.URLEncoding.EncodeToString([]byte(`{"s":"date","v":"2026-05-01T08:00:00Z","id":1}`))},
+		{"not json", "date", enc("date:1")},
+		{"missing id", "name", enc(`{"s":"name","v":"x"}`)},
+		{"zero id", "name", enc(`{"s":"name","v":"x","id":0}`)},
+		{"negative id", "name", enc(`{"s":"name","v":"x","id":-5}`)},
+		{"wrong id type", "name", enc(`{"s":"name","v":"x","id":"7"}`)},
+		{"date not a timestamp", "date", enc(`{"s":"date","v":"xy","id":1}`)},
+		{"date without time", "-date", enc(`{"s":"-date","v":"2026-05-01","id":1}`)},
+		{"created_at infinity", "created_at", enc(`{"s":"created_at","v":"infinity","id":1}`)},
+		{"unknown sort", "lat", enc(`{"s":"lat","v":"45","id":1}`)},
+		{"different sort", "date", enc(`{"s":"name","v":"Šuma","id":1}`)},
+		{"name with NUL", "name", enc(`{"s":"name","v":"a\u0000b","id":1}`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeEventCursor(tt.cursor, tt.sort); !errors.Is(err, ErrInvalidEventCursor) {
+				t.Errorf("decodeEventCursor(%q, %q) error = %v, want ErrInvalidEventCursor", tt.cursor, tt.sort, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/MKolega/AirsoftHubCroatia/types"
+	"github.com/uptrace/bun"
+)
+
+// MaxEventMedia caps how many images and attachments one event can hold.
+const MaxEventMedia = 20
+
+var (
+	ErrMediaNotFound   = errors.New("media not found")
+	ErrMediaLimit      = errors.New("event media limit reached")
+	ErrInvalidMediaSet = errors.New("order must list every media item of the event once")
+)
+
+// GetEventMedia returns the event's media in display order, leaving out
+// media that awaits review unless withPending is set.
+func GetEventMedia(eventID int, withPending bool) ([]types.EventMedia, error) {
+	media := []types.EventMedia{}
+	q := Bun.NewSelect().
+		Model(&media).
+		Where("event_id = ?", eventID)
+	if !withPending {
+		q = q.Where("status = ?", "approved")
+	}
+	err := q.Order("position", "id").Scan(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return media, nil
+}
+
+// AddEventMedia appends media to the end of the event's list and attaches its
+// stored object to the event.
+func AddEventMedia(media *types.EventMedia) error {
+	ctx := context.Background()
+	return Bun.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		return addEventMedia(ctx, tx, media)
+	})
+}
+
+func addEventMedia(ctx context.Context, tx bun.Tx, media *types.EventMedia) error {
+	// Serialize concurrent uploads so the limit and positions hold.
+	err := tx.NewSelect().
+		Model((*types.Event)(nil)).
+		Column("id").
+		Where("id = ?", media.EventID).
+		For("UPDATE").
+		Scan(ctx, new(int))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrEventNotFound
+		}
+		return err
+	}
+	count, err := tx.NewSelect().
+		Model((*types.EventMedia)(nil)).
+		Where("event_id = ?", media.EventID).
+		Count(ctx)
+	if err != nil {
+		return err
+	}
+	if count >= MaxEventMedia {
+		return ErrMediaLimit
+	}
+
+	_, err = tx.NewInsert().
+		Model(media).
+		Value("position", "(SELECT COALESCE(MAX(position), 0) + 1 FROM event_media WHERE event_id = ?)", media.EventID).
+		Returning("*").
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = tx.NewUpdate().
+		Model((*types.StoredObject)(nil)).
+		Set("event_id = ?", media.EventID).
+		Where("key = ?", media.StorageKey).
+		Exec(ctx)
+	return err
+}
+
+// UpdateEventMediaCaption changes the caption of one media item; with review
+// set the item goes back to pending.
+func UpdateEventMediaCaption(eventID int, mediaID int, caption string, review bool) (*types.EventMedia, error) {
+	media := new(types.EventMedia)
+	q := Bun.NewUpdate().
+		Model(media).
+		Set("caption = ?", caption)
+	if review {
+		q = q.Set("status = ?", "pending")
+	}
+	res, err := q.
+		Where("id = ? AND event_id = ?", mediaID, eventID).
+		Returning("*").
+		Exec(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, ErrMediaNotFound
+	}
+	return media, nil
+}
+
+// DeleteEventMedia removes one media item and returns it so the caller can
+// delete the stored object.
+func DeleteEventMedia(eventID int, mediaID int) (*types.EventMedia, error) {
+	media := new(types.EventMedia)
+	res, err := Bun.NewDelete().
+		Model(media).
+		Where("id = ? AND event_id = ?", mediaID, eventID).
+		Returning("*").
+		Exec(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, ErrMediaNotFound
+	}
+	return media, nil
+}
+
+// ReorderEventMedia sets positions from ids, which must contain every media
+// item of the event exactly once.
+func ReorderEventMedia(eventID int, ids []int) error {
+	ctx := context.Background()
+	return Bun.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		var existing []int
+		err := tx.NewSelect().
+			Model((*types.EventMedia)(nil)).
+			Column("id").
+			Where("event_id = ?", eventID).
+			For("UPDATE").
+			Scan(ctx, &existing)
+		if err != nil {
+			return err
+		}
+		if len(existing) != len(ids) {
+			return ErrInvalidMediaSet
+		}
+		want := make(map[int]bool, len(existing))
+		for _, id := range existing {
+			want[id] = true
+		}
+		for _, id := range ids {
+			if !want[id] {
+				return ErrInvalidMediaSet
+			}
+			delete(want, id)
+		}
+
+		for i, id := range ids {
+			_, err := tx.NewUpdate().
+				Model((*types.EventMedia)(nil)).
+				Set("position = ?", i+1).
+				Where("id = ?", id).
+				Exec(ctx)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetPendingEventMedia lists media awaiting review, oldest first, with the
+// name of its event.
+func GetPendingEventMedia() ([]types.EventMedia, error) {
+	media := []types.EventMedia{}
+	err := Bun.NewSelect().
+		Model(&media).
+		ColumnExpr("?TableAlias.*").
+		ColumnExpr("e.name AS event_name").
+		Join("JOIN events AS e ON e.id = ?TableAlias.event_id").
+		Where("?TableAlias.status = ?", "pending").
+		OrderExpr("?TableAlias.created_at, ?TableAlias.id").
+		Scan(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return media, nil
+}
+
+// ApproveEventMedia publishes a pending media item.
+func ApproveEventMedia(mediaID int, reviewedByEmail string) error {
+	res, err := Bun.NewUpdate().
+		Model((*types.EventMedia)(nil)).
+		Set("status = ?", "approved").
+		Set("reviewed_at = now()").
+		Set("reviewed_by_email = ?", strings.TrimSpace(reviewedByEmail)).
+		Where("id = ?", mediaID).
+		Where("status = ?", "pending").
+		Exec(context.Background())
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrMediaNotFound
+	}
+	return nil
+}
+
+// RejectEventMedia removes a pending media item and returns it so the caller
+// can delete the stored object.
+func RejectEventMedia(mediaID int) (*types.EventMedia, error) {
+	media := new(types.EventMedia)
+	res, err := Bun.NewDelete().
+		Model(media).
+		Where("id = ?", mediaID).
+		Where("status = ?", "pending").
+		Returning("*").
+		Exec(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, ErrMediaNotFound
+	}
+	return media, nil
+}
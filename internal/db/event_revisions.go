@@ -0,0 +1,189 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/MKolega/AirsoftHubCroatia/types"
+	"github.com/uptrace/bun"
+)
+
+// Actions recorded in event_revisions.
+const (
+	EventActionCreated  = "created"
+	EventActionUpdated  = "updated"
+	EventActionApproved = "approved"
+	EventActionRejected = "rejected"
+	EventActionPending  = "pending"
+	EventActionDeleted  = "deleted"
+	EventActionRestored = "restored"
+)
+
+var ErrRevisionNotFound = errors.New("revision not found")
+
+// eventContentColumns are the fields a restore copies back from a snapshot.
+var eventContentColumns = []string{
+	"name", "description", "detailed_description", "location",
+	"starts_at", "ends_at", "time_zone",
+	"lat", "lng", "field_id", "club_id", "category", "facebook_link", "thumbnail", "max_players",
+	"thumbnail_variants", "thumbnail_blurhash", "thumbnail_color",
+	"status", "rejection_reason",
+}
+
+// insertEventRevision appends a revision of event. The event row is locked
+// first so concurrent writers of one event number their revisions in turn;
+// idb must be a transaction.
+func insertEventRevision(ctx context.Context, idb bun.IDB, event *types.Event, action string, actorEmail string) error {
+	if err := lockEventRow(ctx, idb, event.ID); err != nil {
+		return err
+	}
+	snapshot := *event
+	snapshot.PreviousVersion = nil
+	rev := &types.EventRevision{
+		EventID:    event.ID,
+		Action:     action,
+		ActorEmail: strings.TrimSpace(actorEmail),
+		Snapshot:   snapshot,
+	}
+	_, err := idb.NewInsert().
+		Model(rev).
+		Value("revision", "(SELECT COALESCE(MAX(revision), 0) + 1 FROM event_revisions WHERE event_id = ?)", event.ID).
+		Exec(ctx)
+	return err
+}
+
+// lockEventRow takes the row lock of the event until the transaction ends.
+func lockEventRow(ctx context.Context, idb bun.IDB, eventID any) error {
+	var id int
+	return idb.NewSelect().
+		Model((*types.Event)(nil)).
+		Column("id").
+		Where("id = ?", eventID).
+		For("UPDATE").
+		Scan(ctx, &id)
+}
+
+// recordEventRevision snapshots the current row of eventID, locking it.
+func recordEventRevision(ctx context.Context, idb bun.IDB, eventID any, action string, actorEmail string) error {
+	event := new(types.Event)
+	if err := idb.NewSelect().Model(event).Where("id = ?", eventID).For("UPDATE").Limit(1).Scan(ctx); err != nil {
+		return err
+	}
+	return insertEventRevision(ctx, idb, event, action, actorEmail)
+}
+
+func GetEventRevisions(eventID int) ([]types.EventRevision, error) {
+	revisions := []types.EventRevision{}
+	err := Bun.NewSelect().
+		Model(&revisions).
+		Where("event_id = ?", eventID).
+		Order("revision DESC").
+		Scan(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// dropDeletedLinks clears the thumbnail, field and club of a snapshot that no
+// longer exist.
+func dropDeletedLinks(ctx context.Context, idb bun.IDB, event *types.Event) error {
+	if err := dropDeletedThumbnail(ctx, idb, event); err != nil {
+		return err
+	}
+	if err := dropDeletedField(ctx, idb, event); err != nil {
+		return err
+	}
+	return dropDeletedClub(ctx, idb, event)
+}
+
+// writeEventSnapshot overwrites the existing event row with the content of
+// snapshot (plus the extra columns) and lets the waitlist fill any freed
+// spots.
+func writeEventSnapshot(ctx context.Context, tx bun.Tx, snapshot *types.Event, extra ...string) error {
+	columns := append(append([]string{}, eventContentColumns...), "previous_version", "sequence", "updated_at")
+	_, err := tx.NewUpdate().
+		Model(snapshot).
+		Column(append(columns, extra...)...).
+		Value("sequence", "sequence + 1").
+		Value("updated_at", "now()").
+		WherePK().
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+	return promoteWaitlist(ctx, tx, snapshot.ID, snapshot.MaxPlayers)
+}
+
+// RestoreEventRevision puts the event back into the state captured by the
+// given revision, re-creating it if it was deleted, and records the restore.
+func RestoreEventRevision(eventID int, revision int, actorEmail string) (*types.Event, error) {
+	ctx := context.Background()
+	restored := new(types.Event)
+
+	err := Bun.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		rev := new(types.EventRevision)
+		err := tx.NewSelect().
+			Model(rev).
+			Where("event_id = ? AND revision = ?", eventID, revision).
+			Limit(1).
+			Scan(ctx)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrRevisionNotFound
+			}
+			return err
+		}
+		*restored = rev.Snapshot
+		restored.ID = eventID
+		restored.PreviousVersion = nil
+		if err := dropDeletedLinks(ctx, tx, restored); err != nil {
+			return err
+		}
+
+		exists, err := tx.NewSelect().
+			Model((*types.Event)(nil)).
+			Where("id = ?", eventID).
+			For("UPDATE").
+			Exists(ctx)
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			if err := writeEventSnapshot(ctx, tx, restored); err != nil {
+				return err
+			}
+		} else {
+			// Continue the calendar SEQUENCE past the cancellation so
+			// subscribers see the event come back.
+			var seq int
+			err = tx.NewSelect().
+				Table("deleted_events").
+				ColumnExpr("sequence").
+				Where("event_id = ?", eventID).
+				Scan(ctx, &seq)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return err
+			}
+			restored.Sequence = max(restored.Sequence, seq) + 1
+			if _, err := tx.NewInsert().Model(restored).Value("updated_at", "now()").Exec(ctx); err != nil {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx, `DELETE FROM deleted_events WHERE event_id = ?`, eventID); err != nil {
+				return err
+			}
+		}
+
+		if err := reattachThumbnail(ctx, tx, restored); err != nil {
+			return err
+		}
+		return recordEventRevision(ctx, tx, eventID, EventActionRestored, actorEmail)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return GetEventByID(eventID)
+}
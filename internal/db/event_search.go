@@ -0,0 +1,94 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"github.com/MKolega/AirsoftHubCroatia/types"
+)
+
+// Search uses the hr_unaccent text search configuration from migration 0019.
+const searchConfig = "hr_unaccent"
+
+const maxSearchTerms = 8
+
+// Highlight markers wrap matched words in ts_headline output. They are
+// private-use runes so the caller can escape the text before turning them
+// into markup.
+const (
+	HighlightStart = "\uE000"
+	HighlightStop  = "\uE001"
+)
+
+const (
+	highlightSel = `StartSel="` + HighlightStart + `", StopSel="` + HighlightStop + `"`
+	// The name is highlighted whole; descriptions are cut to the matches.
+	nameHeadlineOptions    = highlightSel + ", HighlightAll=true"
+	snippetHeadlineOptions = highlightSel + `, MaxWords=30, MinWords=12, MaxFragments=2, FragmentDelimiter=" … "`
+)
+
+// EventSearchOptions describes a full-text search over published events.
+type EventSearchOptions struct {
+	Query    string
+	From     string
+	To       string
+	Category string
+	Limit    int
+}
+
+// searchTSQuery turns user input into a tsquery that requires every word as a
+// prefix, e.g. "Čakovec cqb" -> "Čakovec:* & cqb:*". Anything but letters and
+// digits is dropped so the input cannot inject tsquery operators. Prefixes
+// stand in for stemming, which Postgres does not offer for Croatian.
+func searchTSQuery(input string) string {
+	words := strings.FieldsFunc(input, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) > maxSearchTerms {
+		words = words[:maxSearchTerms]
+	}
+	terms := make([]string, len(words))
+	for i, w := range words {
+		terms[i] = w + ":*"
+	}
+	return strings.Join(terms, " & ")
+}
+
+// SearchEvents returns published events matching opts.Query, best match first.
+func SearchEvents(opts EventSearchOptions) ([]types.EventSearchResult, error) {
+	results := []types.EventSearchResult{}
+	tsquery := searchTSQuery(opts.Query)
+	if tsquery == "" {
+		return results, nil
+	}
+
+	q := Bun.NewSelect().
+		Model(&results).
+		ColumnExpr("?TableColumns").
+		ColumnExpr("ts_rank_cd(search_vector, query) AS rank").
+		// Highlights quote the published text, not an edit awaiting review.
+		ColumnExpr("ts_headline(?, COALESCE(previous_version->>'name', name), query, ?) AS name_highlight", searchConfig, nameHeadlineOptions).
+		ColumnExpr("ts_headline(?, concat_ws(' ', COALESCE(previous_version->>'description', description), COALESCE(previous_version->>'detailed_description', detailed_description)), query, ?) AS snippet", searchConfig, snippetHeadlineOptions).
+		TableExpr("to_tsquery(?, ?) AS query", searchConfig, tsquery).
+		Where("search_vector @@ query")
+	q = wherePublished(q)
+
+	q = whereEventInDateRange(q, opts.From, opts.To)
+	if cat := strings.TrimSpace(opts.Category); cat != "" {
+		q = q.Where("category = ?", cat)
+	}
+
+	q = q.OrderExpr("rank DESC").OrderExpr("starts_at DESC NULLS LAST").OrderExpr("id DESC")
+	if opts.Limit > 0 {
+		q = q.Limit(opts.Limit)
+	}
+
+	if err := q.Scan(context.Background()); err != nil {
+		return nil, err
+	}
+	for i := range results {
+		results[i].Event = *results[i].PublishedVersion()
+	}
+	return results, nil
+}
@@ -0,0 +1,29 @@
+package db
+
+import "testing"
+
+func TestSearchTSQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"empty", "", ""},
+		{"only punctuation", " !&|:*() ", ""},
+		{"single word", "cqb", "cqb:*"},
+		{"keeps diacritics", "Čakovec cqb", "Čakovec:* & cqb:*"},
+		{"digits", "24h milsim", "24h:* & milsim:*"},
+		{"operators dropped", "a&b | !c:*", "a:* & b:* & c:*"},
+		{"quotes and backslashes dropped", `'Vukovi' \ "Split"`, "Vukovi:* & Split:*"},
+		{"whitespace collapsed", "  night \t\n game  ", "night:* & game:*"},
+		{"hyphenated", "Zagreb-Sesvete", "Zagreb:* & Sesvete:*"},
+		{"capped at eight terms", "a b c d e f g h i j", "a:* & b:* & c:* & d:* & e:* & f:* & g:* & h:*"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := searchTSQuery(tt.input); got != tt.want {
+				t.Errorf("searchTSQuery(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
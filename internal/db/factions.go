@@ -0,0 +1,177 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/MKolega/AirsoftHubCroatia/types"
+	"github.com/uptrace/bun"
+)
+
+var (
+	ErrFactionNotFound  = errors.New("faction not found")
+	ErrFactionFull      = errors.New("faction is full")
+	ErrFactionNameTaken = errors.New("faction name already used for this event")
+)
+
+// checkFactionCapacity verifies the faction belongs to the event and has room
+// for userID. Callers must hold the event row lock.
+func checkFactionCapacity(ctx context.Context, idb bun.IDB, eventID int, factionID int, userID int) error {
+	faction := new(types.EventFaction)
+	err := idb.NewSelect().
+		Model(faction).
+		Where("id = ? AND event_id = ?", factionID, eventID).
+		Limit(1).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrFactionNotFound
+		}
+		return err
+	}
+	if faction.MaxPlayers <= 0 {
+		return nil
+	}
+	taken, err := idb.NewSelect().
+		Model((*eventRegistration)(nil)).
+		Where("event_id = ? AND faction_id = ? AND user_id <> ?", eventID, factionID, userID).
+		Count(ctx)
+	if err != nil {
+		return err
+	}
+	if taken >= faction.MaxPlayers {
+		return ErrFactionFull
+	}
+	return nil
+}
+
+// GetEventFactions returns the event's factions in display order with the
+// number of players (registered or waitlisted) who picked each.
+func GetEventFactions(eventID int) ([]types.EventFaction, error) {
+	return eventFactions(context.Background(), Bun, eventID)
+}
+
+func eventFactions(ctx context.Context, idb bun.IDB, eventID int) ([]types.EventFaction, error) {
+	factions := []types.EventFaction{}
+	err := idb.NewSelect().
+		Model(&factions).
+		ColumnExpr("event_faction.*").
+		ColumnExpr("(SELECT count(*) FROM event_registrations AS er WHERE er.faction_id = event_faction.id) AS player_count").
+		Where("event_id = ?", eventID).
+		Order("position", "id").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return factions, nil
+}
+
+func factionNameTaken(ctx context.Context, idb bun.IDB, eventID int, name string, excludeID int) (bool, error) {
+	count, err := idb.NewSelect().
+		Model((*types.EventFaction)(nil)).
+		Where("event_id = ?", eventID).
+		Where("lower(name) = ?", strings.ToLower(name)).
+		Where("id <> ?", excludeID).
+		Count(ctx)
+	return count > 0, err
+}
+
+func CreateEventFaction(faction *types.EventFaction) error {
+	ctx := context.Background()
+	return Bun.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		taken, err := factionNameTaken(ctx, tx, faction.EventID, faction.Name, 0)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return ErrFactionNameTaken
+		}
+		_, err = tx.NewInsert().
+			Model(faction).
+			Value("position", "(SELECT COALESCE(MAX(position), 0) + 1 FROM event_factions WHERE event_id = ?)", faction.EventID).
+			Exec(ctx)
+		return err
+	})
+}
+
+func UpdateEventFaction(faction *types.EventFaction) error {
+	ctx := context.Background()
+	return Bun.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		taken, err := factionNameTaken(ctx, tx, faction.EventID, faction.Name, faction.ID)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return ErrFactionNameTaken
+		}
+		res, err := tx.NewUpdate().
+			Model(faction).
+			Column("name", "max_players").
+			Where("id = ? AND event_id = ?", faction.ID, faction.EventID).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return ErrFactionNotFound
+		}
+		return nil
+	})
+}
+
+// DeleteEventFaction removes the faction; its players become unassigned.
+func DeleteEventFaction(eventID int, factionID int) error {
+	res, err := Bun.NewDelete().
+		Model((*types.EventFaction)(nil)).
+		Where("id = ? AND event_id = ?", factionID, eventID).
+		Exec(context.Background())
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrFactionNotFound
+	}
+	return nil
+}
+
+// ApplyFactionBalance recomputes faction assignments under the event lock.
+// plan receives the event's factions and full roster read inside the same
+// transaction and returns userID -> factionID (0 to unassign); players it
+// leaves out keep their faction.
+func ApplyFactionBalance(eventID int, plan func(factions []types.EventFaction, roster []types.RosterEntry) (map[int]int, error)) error {
+	ctx := context.Background()
+	return Bun.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if _, err := lockOpenEvent(ctx, tx, eventID); err != nil {
+			return err
+		}
+		factions, err := eventFactions(ctx, tx, eventID)
+		if err != nil {
+			return err
+		}
+		roster, err := eventRoster(ctx, tx, eventID)
+		if err != nil {
+			return err
+		}
+		assignments, err := plan(factions, roster)
+		if err != nil {
+			return err
+		}
+		for userID, factionID := range assignments {
+			var faction any
+			if factionID > 0 {
+				faction = factionID
+			}
+			if _, err := tx.NewUpdate().
+				Model((*eventRegistration)(nil)).
+				Set("faction_id = ?", faction).
+				Set("updated_at = now()").
+				Where("event_id = ? AND user_id = ?", eventID, userID).
+				Exec(ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
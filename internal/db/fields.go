@@ -0,0 +1,308 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/MKolega/AirsoftHubCroatia/types"
+	"github.com/uptrace/bun"
+)
+
+// MaxFieldPhotos caps how many photos one field can hold.
+const MaxFieldPhotos = 10
+
+var (
+	ErrFieldNotFound      = errors.New("field not found")
+	ErrFieldNameTaken     = errors.New("field name already used")
+	ErrFieldPhotoLimit    = errors.New("field photo limit reached")
+	ErrFieldPhotoNotFound = errors.New("field photo not found")
+)
+
+// fieldEditableColumns are the columns a field edit writes.
+var fieldEditableColumns = []string{
+	"name", "lat", "lng", "address", "county", "surface_type",
+	"owner_club", "rules", "parking_notes",
+}
+
+func GetFieldByID(id int) (*types.Field, error) {
+	field := new(types.Field)
+	err := Bun.NewSelect().Model(field).Where("id = ?", id).Limit(1).Scan(context.Background())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrFieldNotFound
+		}
+		return nil, err
+	}
+	return field, nil
+}
+
+// ListFields returns approved fields by name, optionally filtered by a name
+// or address fragment and by county.
+func ListFields(query string, county string) ([]types.Field, error) {
+	fields := []types.Field{}
+	q := Bun.NewSelect().Model(&fields).Where("status = ?", "approved")
+	if text := strings.TrimSpace(query); text != "" {
+		pattern := "%" + escapeLike(text) + "%"
+		q = q.WhereGroup(" AND ", func(sq *bun.SelectQuery) *bun.SelectQuery {
+			return sq.Where("name ILIKE ?", pattern).WhereOr("address ILIKE ?", pattern)
+		})
+	}
+	if county != "" {
+		q = q.Where("county = ?", county)
+	}
+	if err := q.Order("name").Scan(context.Background()); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+func GetPendingFields() ([]types.Field, error) {
+	fields := []types.Field{}
+	err := Bun.NewSelect().Model(&fields).Where("status = ?", "pending").Order("created_at").Scan(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+func fieldNameTaken(ctx context.Context, idb bun.IDB, name string, excludeID int) (bool, error) {
+	count, err := idb.NewSelect().
+		Model((*types.Field)(nil)).
+		Where("lower(name) = lower(?)", name).
+		Where("status <> ?", "rejected").
+		Where("id <> ?", excludeID).
+		Count(ctx)
+	return count > 0, err
+}
+
+func CreateField(field *types.Field) error {
+	if field.Photos == nil {
+		field.Photos = []string{}
+	}
+	ctx := context.Background()
+	return Bun.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		taken, err := fieldNameTaken(ctx, tx, field.Name, 0)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return ErrFieldNameTaken
+		}
+		_, err = tx.NewInsert().Model(field).Returning("*").Exec(ctx)
+		return err
+	})
+}
+
+// UpdateField writes the editable columns plus any extra ones (e.g. status).
+func UpdateField(field *types.Field, extra ...string) error {
+	ctx := context.Background()
+	return Bun.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		taken, err := fieldNameTaken(ctx, tx, field.Name, field.ID)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return ErrFieldNameTaken
+		}
+		columns := append(append([]string{}, fieldEditableColumns...), extra...)
+		res, err := tx.NewUpdate().
+			Model(field).
+			Column(append(columns, "updated_at")...).
+			Value("updated_at", "now()").
+			WherePK().
+			Returning("*").
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return ErrFieldNotFound
+		}
+		return nil
+	})
+}
+
+func DeleteField(id int) error {
+	res, err := Bun.NewDelete().Model((*types.Field)(nil)).Where("id = ?", id).Exec(context.Background())
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrFieldNotFound
+	}
+	return nil
+}
+
+func ReviewField(id int, status string, reviewedByEmail string, rejectionReason string) error {
+	if status != "approved" && status != "rejected" {
+		return fmt.Errorf("invalid status")
+	}
+	var reason any
+	if r := strings.TrimSpace(rejectionReason); r != "" && status == "rejected" {
+		reason = r
+	}
+	ctx := context.Background()
+	return Bun.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		field := new(types.Field)
+		err := tx.NewSelect().Model(field).Where("id = ?", id).For("UPDATE").Scan(ctx)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrFieldNotFound
+			}
+			return err
+		}
+		if status == "approved" {
+			taken, err := fieldNameTaken(ctx, tx, field.Name, field.ID)
+			if err != nil {
+				return err
+			}
+			if taken {
+				return ErrFieldNameTaken
+			}
+		}
+		_, err = tx.NewUpdate().
+			Model((*types.Field)(nil)).
+			Set("status = ?", status).
+			Set("rejection_reason = ?", reason).
+			Set("reviewed_at = now()").
+			Set("reviewed_by_email = ?", strings.TrimSpace(reviewedByEmail)).
+			Where("id = ?", id).
+			Exec(ctx)
+		return err
+	})
+}
+
+// GetFieldEvents returns published events held at the field: upcoming ones
+// soonest first, or past ones most recent first.
+func GetFieldEvents(fieldID int, upcoming bool, limit int) ([]types.Event, error) {
+	return linkedEvents("field_id", fieldID, upcoming, limit)
+}
+
+// linkedEvents returns the published events whose column equals id, ordered
+// like GetFieldEvents.
+func linkedEvents(column string, id int, upcoming bool, limit int) ([]types.Event, error) {
+	events := []types.Event{}
+	q := Bun.NewSelect().
+		Model(&events).
+		Where("? = ?", bun.Ident(column), id)
+	q = wherePublished(q)
+	if upcoming {
+		q = q.Where("COALESCE(ends_at, starts_at, 'infinity'::timestamptz) >= now()").
+			OrderExpr("starts_at ASC NULLS LAST")
+	} else {
+		q = q.Where("COALESCE(ends_at, starts_at) < now()").
+			OrderExpr("starts_at DESC")
+	}
+	q = q.OrderExpr("id ASC")
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	if err := q.Scan(context.Background()); err != nil {
+		return nil, err
+	}
+	publishEvents(events)
+	return events, nil
+}
+
+// AddFieldPhoto appends url to the field's photos and attaches the stored
+// object under key to the field.
+func AddFieldPhoto(fieldID int, url string, key string) (*types.Field, error) {
+	field := new(types.Field)
+	ctx := context.Background()
+	err := Bun.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		err := tx.NewSelect().Model(field).Where("id = ?", fieldID).For("UPDATE").Scan(ctx)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrFieldNotFound
+			}
+			return err
+		}
+		if len(field.Photos) >= MaxFieldPhotos {
+			return ErrFieldPhotoLimit
+		}
+		field.Photos = append(field.Photos, url)
+		if _, err := tx.NewUpdate().Model(field).Column("photos").WherePK().Exec(ctx); err != nil {
+			return err
+		}
+		_, err = tx.NewUpdate().
+			Model((*types.StoredObject)(nil)).
+			Set("field_id = ?", fieldID).
+			Where("key = ?", key).
+			Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return field, nil
+}
+
+// RemoveFieldPhoto drops the photo at index, detaches its stored object and
+// returns the object's key so the caller can delete it.
+func RemoveFieldPhoto(fieldID int, index int) (*types.Field, []string, error) {
+	field := new(types.Field)
+	var keys []string
+	ctx := context.Background()
+	err := Bun.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		err := tx.NewSelect().Model(field).Where("id = ?", fieldID).For("UPDATE").Scan(ctx)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrFieldNotFound
+			}
+			return err
+		}
+		if index < 0 || index >= len(field.Photos) {
+			return ErrFieldPhotoNotFound
+		}
+		url := field.Photos[index]
+		field.Photos = append(field.Photos[:index], field.Photos[index+1:]...)
+		if _, err := tx.NewUpdate().Model(field).Column("photos").WherePK().Exec(ctx); err != nil {
+			return err
+		}
+		_, err = tx.NewUpdate().
+			Model((*types.StoredObject)(nil)).
+			Set("field_id = NULL").
+			Where("field_id = ?", fieldID).
+			Where("url = ?", url).
+			Where("deleted_at IS NULL").
+			Returning("key").
+			Exec(ctx, &keys)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return field, keys, nil
+}
+
+// FieldStoredObjectKeys lists the live objects attached to a field.
+func FieldStoredObjectKeys(fieldID int) ([]string, error) {
+	var keys []string
+	err := Bun.NewSelect().
+		Model((*types.StoredObject)(nil)).
+		Column("key").
+		Where("field_id = ?", fieldID).
+		Where("deleted_at IS NULL").
+		Scan(context.Background(), &keys)
+	return keys, err
+}
+
+// dropDeletedField unlinks a restored snapshot from a field that no longer
+// exists.
+func dropDeletedField(ctx context.Context, idb bun.IDB, event *types.Event) error {
+	if event.FieldID == 0 {
+		return nil
+	}
+	exists, err := idb.NewSelect().
+		Model((*types.Field)(nil)).
+		Where("id = ?", event.FieldID).
+		Exists(ctx)
+	if err != nil || exists {
+		return err
+	}
+	event.FieldID = 0
+	return nil
+}
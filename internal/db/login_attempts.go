@@ -0,0 +1,118 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// LoginPolicy controls how failed sign-ins for one email are throttled. The
+// first FreeAttempts failures cost nothing; after that each failure doubles
+// the wait from BaseDelay up to MaxDelay, and reaching LockoutThreshold
+// locks the email for LockoutDuration. Counts reset after Window without a
+// failure.
+type LoginPolicy struct {
+	FreeAttempts     int
+	BaseDelay        time.Duration
+	MaxDelay         time.Duration
+	LockoutThreshold int
+	LockoutDuration  time.Duration
+	Window           time.Duration
+}
+
+type loginAttempt struct {
+	bun.BaseModel `bun:"table:login_attempts,alias:la"`
+
+	Email         string    `bun:"email,pk"`
+	FailedCount   int       `bun:"failed_count,notnull"`
+	LastFailedAt  time.Time `bun:"last_failed_at,notnull"`
+	NextAttemptAt time.Time `bun:"next_attempt_at,nullzero"`
+	LockedAt      time.Time `bun:"locked_at,nullzero"`
+}
+
+// ReserveLoginAttempt checks whether email may try to sign in and, if so,
+// counts the attempt as failed before the password is checked. Check and
+// count share one locked row, so parallel guesses cannot all pass the check
+// before the first failure is recorded; a successful sign-in clears the
+// reservation with ClearLoginFailures. It returns the time before which
+// sign-ins are refused (zero when this attempt may go ahead) and whether the
+// reserved attempt locks the email if it fails.
+func ReserveLoginAttempt(email string, policy LoginPolicy) (time.Time, bool, error) {
+	ctx := context.Background()
+	var blockedUntil time.Time
+	locks := false
+	err := Bun.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if _, err := tx.NewInsert().
+			Model(&loginAttempt{Email: email, LastFailedAt: time.Now()}).
+			On("CONFLICT (email) DO NOTHING").
+			Exec(ctx); err != nil {
+			return err
+		}
+		attempt := new(loginAttempt)
+		if err := tx.NewSelect().
+			Model(attempt).
+			Where("email = ?", email).
+			For("UPDATE").
+			Scan(ctx); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if attempt.NextAttemptAt.After(now) {
+			blockedUntil = attempt.NextAttemptAt
+			return nil
+		}
+		if now.Sub(attempt.LastFailedAt) > policy.Window {
+			attempt.FailedCount = 0
+			attempt.LockedAt = time.Time{}
+		}
+		attempt.FailedCount++
+		attempt.LastFailedAt = now
+		attempt.NextAttemptAt = time.Time{}
+
+		switch {
+		case attempt.FailedCount >= policy.LockoutThreshold:
+			attempt.NextAttemptAt = now.Add(policy.LockoutDuration)
+			locks = attempt.FailedCount == policy.LockoutThreshold
+			if locks {
+				attempt.LockedAt = now
+			}
+		case attempt.FailedCount > policy.FreeAttempts:
+			delay := policy.BaseDelay << (attempt.FailedCount - policy.FreeAttempts - 1)
+			if delay <= 0 || delay > policy.MaxDelay {
+				delay = policy.MaxDelay
+			}
+			attempt.NextAttemptAt = now.Add(delay)
+		}
+
+		_, err := tx.NewUpdate().
+			Model(attempt).
+			Column("failed_count", "last_failed_at", "next_attempt_at", "locked_at").
+			WherePK().
+			Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return blockedUntil, locks, nil
+}
+
+// ClearLoginFailures forgets failed sign-ins for email, lifting any lockout.
+// Old rows for other emails are pruned on the way.
+func ClearLoginFailures(email string, window time.Duration) error {
+	ctx := context.Background()
+	if _, err := Bun.NewDelete().
+		Model((*loginAttempt)(nil)).
+		Where("email = ?", email).
+		Exec(ctx); err != nil {
+		return err
+	}
+	_, err := Bun.NewDelete().
+		Model((*loginAttempt)(nil)).
+		Where("last_failed_at < ?", time.Now().Add(-window)).
+		Where("next_attempt_at IS NULL OR next_attempt_at < now()").
+		Exec(ctx)
+	return err
+}
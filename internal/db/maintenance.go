@@ -0,0 +1,39 @@
+package db
+
+import (
+	"context"
+
+	"github.com/MKolega/AirsoftHubCroatia/types"
+)
+
+func GetMaintenanceSettings() (*types.MaintenanceSettings, error) {
+	settings := new(types.MaintenanceSettings)
+	err := Bun.NewSelect().
+		Model(settings).
+		Where("id = 1").
+		Limit(1).
+		Scan(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+func SaveMaintenanceSettings(settings *types.MaintenanceSettings) error {
+	settings.ID = 1
+	_, err := Bun.NewInsert().
+		Model(settings).
+		On("CONFLICT (id) DO UPDATE").
+		Set("enabled = EXCLUDED.enabled").
+		Set("starts_at = EXCLUDED.starts_at").
+		Set("ends_at = EXCLUDED.ends_at").
+		Set("message = EXCLUDED.message").
+		Set("eta = EXCLUDED.eta").
+		Set("allow_reads = EXCLUDED.allow_reads").
+		Set("allowed_routes = EXCLUDED.allowed_routes").
+		Set("updated_by_email = EXCLUDED.updated_by_email").
+		Set("updated_at = now()").
+		Returning("updated_at").
+		Exec(context.Background())
+	return err
+}
@@ -0,0 +1,156 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/MKolega/AirsoftHubCroatia/internal/db/migrations"
+	"github.com/uptrace/bun/migrate"
+)
+
+// ErrSchemaNewer is returned when the database has migrations applied that
+// this binary does not know about, i.e. it was migrated by a newer release.
+var ErrSchemaNewer = errors.New("database schema is newer than this binary")
+
+// ErrBaselineRollback is returned when rolling back would run the baseline
+// migration's down without force.
+var ErrBaselineRollback = errors.New("refusing to roll back the baseline migration, which drops all data")
+
+// baselineMigration is the name of the migration creating the core tables.
+const baselineMigration = "0001"
+
+func newMigrator() *migrate.Migrator {
+	return migrate.NewMigrator(
+		Bun,
+		migrations.Migrations,
+		migrate.WithTableName("schema_migrations"),
+		migrate.WithLocksTableName("schema_migrations_locks"),
+		migrate.WithMarkAppliedOnSuccess(true),
+	)
+}
+
+func checkNotNewer(ctx context.Context, m *migrate.Migrator) error {
+	missing, err := m.MissingMigrations(ctx)
+	if err != nil {
+		return err
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(missing))
+	for _, mig := range missing {
+		names = append(names, mig.Name)
+	}
+	return fmt.Errorf("%w (unknown migrations: %s)", ErrSchemaNewer, strings.Join(names, ", "))
+}
+
+func withMigrationLock(ctx context.Context, m *migrate.Migrator, fn func() error) error {
+	if err := m.Lock(ctx); err != nil {
+		return err
+	}
+	defer m.Unlock(ctx) //nolint:errcheck
+	return fn()
+}
+
+// MigrateUp applies all pending migrations as one group.
+func MigrateUp(ctx context.Context) (*migrate.MigrationGroup, error) {
+	m := newMigrator()
+	if err := m.Init(ctx); err != nil {
+		return nil, err
+	}
+	var group *migrate.MigrationGroup
+	err := withMigrationLock(ctx, m, func() error {
+		if err := checkNotNewer(ctx, m); err != nil {
+			return err
+		}
+		var err error
+		group, err = m.Migrate(ctx)
+		return err
+	})
+	return group, err
+}
+
+// MigrateDown rolls back the most recently applied migration and returns it,
+// or nil when nothing is applied. The baseline creates the core tables, so
+// rolling it back drops every user and event; it is refused unless force is
+// set.
+func MigrateDown(ctx context.Context, force bool) (*migrate.Migration, error) {
+	m := newMigrator()
+	if err := m.Init(ctx); err != nil {
+		return nil, err
+	}
+	var rolledBack *migrate.Migration
+	err := withMigrationLock(ctx, m, func() error {
+		if err := checkNotNewer(ctx, m); err != nil {
+			return err
+		}
+		all, err := m.MigrationsWithStatus(ctx)
+		if err != nil {
+			return err
+		}
+		applied := all.Applied()
+		if len(applied) == 0 {
+			return nil
+		}
+		last := applied[0]
+		if last.Name == baselineMigration && !force {
+			return ErrBaselineRollback
+		}
+		if last.Down != nil {
+			if err := last.Down(ctx, m.DB(), nil); err != nil {
+				return err
+			}
+		}
+		if err := m.MarkUnapplied(ctx, &last); err != nil {
+			return err
+		}
+		rolledBack = &last
+		return nil
+	})
+	return rolledBack, err
+}
+
+// MigrationStatus lists known migrations with their applied state, plus any
+// applied migrations this binary does not know about.
+func MigrationStatus(ctx context.Context) (known migrate.MigrationSlice, unknown migrate.MigrationSlice, err error) {
+	m := newMigrator()
+	if err := m.Init(ctx); err != nil {
+		return nil, nil, err
+	}
+	known, err = m.MigrationsWithStatus(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	unknown, err = m.MissingMigrations(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return known, unknown, nil
+}
+
+// EnsureSchema refuses to continue against a newer schema and, when
+// autoMigrate is set, applies pending migrations. Otherwise pending
+// migrations are reported as an error.
+func EnsureSchema(ctx context.Context, autoMigrate bool) error {
+	if autoMigrate {
+		_, err := MigrateUp(ctx)
+		return err
+	}
+	m := newMigrator()
+	if err := m.Init(ctx); err != nil {
+		return err
+	}
+	if err := checkNotNewer(ctx, m); err != nil {
+		return err
+	}
+	all, err := m.MigrationsWithStatus(ctx)
+	if err != nil {
+		return err
+	}
+	if pending := all.Unapplied(); len(pending) > 0 {
+		return fmt.Errorf("database has %d pending migration(s); run `api migrate up`", len(pending))
+	}
+	return nil
+}
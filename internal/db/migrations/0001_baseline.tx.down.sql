@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS events;
+--bun:split
+DROP TABLE IF EXISTS event_saves;
+--bun:split
+DROP TABLE IF EXISTS users;
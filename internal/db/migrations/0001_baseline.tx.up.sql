@@ -0,0 +1,90 @@
+-- Baseline schema. Written to be idempotent so databases created by the
+-- pre-migration bootstrap code (which ran these statements on every boot)
+-- are brought to the same state.
+
+CREATE TABLE IF NOT EXISTS users (
+	id SERIAL PRIMARY KEY,
+	email TEXT UNIQUE NOT NULL,
+	username TEXT,
+	airsoft_club TEXT,
+	is_admin BOOLEAN NOT NULL DEFAULT false,
+	is_maintenance_user BOOLEAN NOT NULL DEFAULT false,
+	password_hash TEXT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+--bun:split
+ALTER TABLE users ADD COLUMN IF NOT EXISTS username TEXT;
+--bun:split
+ALTER TABLE users ADD COLUMN IF NOT EXISTS airsoft_club TEXT;
+--bun:split
+ALTER TABLE users ADD COLUMN IF NOT EXISTS is_admin BOOLEAN NOT NULL DEFAULT false;
+--bun:split
+ALTER TABLE users ADD COLUMN IF NOT EXISTS is_maintenance_user BOOLEAN NOT NULL DEFAULT false;
+--bun:split
+-- Case-insensitive uniqueness for usernames (ignores empty usernames)
+CREATE UNIQUE INDEX IF NOT EXISTS users_username_unique_idx ON users (lower(username)) WHERE username IS NOT NULL AND username <> '';
+--bun:split
+
+CREATE TABLE IF NOT EXISTS event_saves (
+	user_id INTEGER NOT NULL,
+	event_id INTEGER NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	PRIMARY KEY(user_id, event_id)
+);
+--bun:split
+
+CREATE TABLE IF NOT EXISTS events (
+	id SERIAL PRIMARY KEY,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	status TEXT NOT NULL DEFAULT 'approved',
+	rejection_reason TEXT,
+	reviewed_at TIMESTAMPTZ,
+	reviewed_by_email TEXT,
+	name TEXT NOT NULL,
+	description TEXT,
+	detailed_description TEXT,
+	creator_email TEXT,
+	lat DOUBLE PRECISION,
+	lng DOUBLE PRECISION,
+	location TEXT,
+	date DATE,
+	category TEXT DEFAULT 'Skirmish',
+	facebook_link TEXT,
+	thumbnail TEXT
+);
+--bun:split
+ALTER TABLE events ADD COLUMN IF NOT EXISTS category TEXT;
+--bun:split
+ALTER TABLE events ALTER COLUMN category SET DEFAULT 'Skirmish';
+--bun:split
+UPDATE events SET category='Skirmish' WHERE category IS NULL OR category='';
+--bun:split
+ALTER TABLE events ADD COLUMN IF NOT EXISTS facebook_link TEXT;
+--bun:split
+ALTER TABLE events ADD COLUMN IF NOT EXISTS detailed_description TEXT;
+--bun:split
+ALTER TABLE events ADD COLUMN IF NOT EXISTS creator_email TEXT;
+--bun:split
+ALTER TABLE events ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ;
+--bun:split
+ALTER TABLE events ALTER COLUMN created_at SET DEFAULT now();
+--bun:split
+UPDATE events SET created_at=now() WHERE created_at IS NULL;
+--bun:split
+ALTER TABLE events ALTER COLUMN created_at SET NOT NULL;
+--bun:split
+ALTER TABLE events ADD COLUMN IF NOT EXISTS thumbnail TEXT;
+--bun:split
+ALTER TABLE events ADD COLUMN IF NOT EXISTS status TEXT;
+--bun:split
+ALTER TABLE events ALTER COLUMN status SET DEFAULT 'approved';
+--bun:split
+UPDATE events SET status='approved' WHERE status IS NULL OR status='';
+--bun:split
+ALTER TABLE events ALTER COLUMN status SET NOT NULL;
+--bun:split
+ALTER TABLE events ADD COLUMN IF NOT EXISTS rejection_reason TEXT;
+--bun:split
+ALTER TABLE events ADD COLUMN IF NOT EXISTS reviewed_at TIMESTAMPTZ;
+--bun:split
+ALTER TABLE events ADD COLUMN IF NOT EXISTS reviewed_by_email TEXT;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS events_lat_lng_idx;
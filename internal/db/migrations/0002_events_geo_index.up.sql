@@ -0,0 +1,2 @@
+-- Backs the nearby/bbox search prefilter.
+CREATE INDEX IF NOT EXISTS events_lat_lng_idx ON events (lat, lng) WHERE status = 'approved' AND lat IS NOT NULL AND lng IS NOT NULL;
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS deleted_events;
+--bun:split
+DROP INDEX IF EXISTS users_calendar_token_unique_idx;
+--bun:split
+ALTER TABLE users DROP COLUMN IF EXISTS calendar_token;
+--bun:split
+ALTER TABLE events DROP COLUMN IF EXISTS sequence;
+--bun:split
+ALTER TABLE events DROP COLUMN IF EXISTS updated_at;
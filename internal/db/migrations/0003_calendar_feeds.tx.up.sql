@@ -0,0 +1,18 @@
+ALTER TABLE events ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ;
+--bun:split
+ALTER TABLE events ADD COLUMN IF NOT EXISTS sequence INTEGER NOT NULL DEFAULT 0;
+--bun:split
+ALTER TABLE users ADD COLUMN IF NOT EXISTS calendar_token TEXT;
+--bun:split
+CREATE UNIQUE INDEX IF NOT EXISTS users_calendar_token_unique_idx ON users (calendar_token) WHERE calendar_token IS NOT NULL;
+--bun:split
+-- Tombstones for deleted approved events so calendar feeds can publish a cancellation.
+CREATE TABLE IF NOT EXISTS deleted_events (
+	event_id INTEGER PRIMARY KEY,
+	name TEXT,
+	date DATE,
+	location TEXT,
+	sequence INTEGER NOT NULL DEFAULT 0,
+	created_at TIMESTAMPTZ,
+	deleted_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
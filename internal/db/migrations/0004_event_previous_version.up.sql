@@ -0,0 +1,3 @@
+-- Last approved version of an event that a non-admin edited, kept until the
+-- edit is reviewed so moderators can see what changed.
+ALTER TABLE events ADD COLUMN IF NOT EXISTS previous_version JSONB;
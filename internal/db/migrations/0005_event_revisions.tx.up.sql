@@ -0,0 +1,12 @@
+-- Append-only audit trail: a full snapshot of the event after every mutation
+-- (before it, for deletes).
+CREATE TABLE IF NOT EXISTS event_revisions (
+	id BIGSERIAL PRIMARY KEY,
+	event_id INTEGER NOT NULL,
+	revision INTEGER NOT NULL,
+	action TEXT NOT NULL,
+	actor_email TEXT,
+	snapshot JSONB NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	UNIQUE (event_id, revision)
+);
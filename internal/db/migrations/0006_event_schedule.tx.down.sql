@@ -0,0 +1,21 @@
+ALTER TABLE deleted_events ADD COLUMN IF NOT EXISTS date DATE;
+--bun:split
+UPDATE deleted_events SET date = (starts_at AT TIME ZONE 'Europe/Zagreb')::date WHERE starts_at IS NOT NULL;
+--bun:split
+ALTER TABLE deleted_events DROP COLUMN IF EXISTS ends_at;
+--bun:split
+ALTER TABLE deleted_events DROP COLUMN IF EXISTS starts_at;
+--bun:split
+DROP INDEX IF EXISTS events_starts_at_idx;
+--bun:split
+ALTER TABLE events ADD COLUMN IF NOT EXISTS date DATE;
+--bun:split
+UPDATE events SET date = (starts_at AT TIME ZONE time_zone)::date WHERE starts_at IS NOT NULL;
+--bun:split
+ALTER TABLE events DROP CONSTRAINT IF EXISTS events_ends_after_starts;
+--bun:split
+ALTER TABLE events DROP COLUMN IF EXISTS time_zone;
+--bun:split
+ALTER TABLE events DROP COLUMN IF EXISTS ends_at;
+--bun:split
+ALTER TABLE events DROP COLUMN IF EXISTS starts_at;
@@ -0,0 +1,35 @@
+-- Replace the bare events.date with UTC start/end timestamps plus the
+-- event's IANA time zone. Existing dated rows are assumed to start at 09:00
+-- local time and last the default length of their category (see
+-- allowedEventCategories in handlers).
+ALTER TABLE events ADD COLUMN IF NOT EXISTS starts_at TIMESTAMPTZ;
+--bun:split
+ALTER TABLE events ADD COLUMN IF NOT EXISTS ends_at TIMESTAMPTZ;
+--bun:split
+ALTER TABLE events ADD COLUMN IF NOT EXISTS time_zone TEXT NOT NULL DEFAULT 'Europe/Zagreb';
+--bun:split
+UPDATE events
+SET starts_at = (date + time '09:00') AT TIME ZONE time_zone,
+	ends_at = ((date + time '09:00') AT TIME ZONE time_zone) + CASE category
+		WHEN '24h' THEN interval '24 hours'
+		WHEN '12h' THEN interval '12 hours'
+		ELSE interval '6 hours'
+	END
+WHERE date IS NOT NULL AND starts_at IS NULL;
+--bun:split
+ALTER TABLE events ADD CONSTRAINT events_ends_after_starts CHECK (ends_at IS NULL OR starts_at IS NULL OR ends_at > starts_at);
+--bun:split
+ALTER TABLE events DROP COLUMN IF EXISTS date;
+--bun:split
+CREATE INDEX IF NOT EXISTS events_starts_at_idx ON events (starts_at) WHERE status = 'approved';
+--bun:split
+ALTER TABLE deleted_events ADD COLUMN IF NOT EXISTS starts_at TIMESTAMPTZ;
+--bun:split
+ALTER TABLE deleted_events ADD COLUMN IF NOT EXISTS ends_at TIMESTAMPTZ;
+--bun:split
+UPDATE deleted_events
+SET starts_at = (date + time '09:00') AT TIME ZONE 'Europe/Zagreb',
+	ends_at = ((date + time '09:00') AT TIME ZONE 'Europe/Zagreb') + interval '6 hours'
+WHERE date IS NOT NULL AND starts_at IS NULL;
+--bun:split
+ALTER TABLE deleted_events DROP COLUMN IF EXISTS date;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS event_registrations;
+--bun:split
+ALTER TABLE events DROP COLUMN IF EXISTS max_players;
@@ -0,0 +1,12 @@
+ALTER TABLE events ADD COLUMN IF NOT EXISTS max_players INTEGER CHECK (max_players IS NULL OR max_players > 0);
+--bun:split
+CREATE TABLE IF NOT EXISTS event_registrations (
+	event_id INTEGER NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+	user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	status TEXT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	PRIMARY KEY (event_id, user_id)
+);
+--bun:split
+CREATE INDEX IF NOT EXISTS event_registrations_event_status_idx ON event_registrations (event_id, status, created_at);
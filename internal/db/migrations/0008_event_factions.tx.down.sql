@@ -0,0 +1,3 @@
+ALTER TABLE event_registrations DROP COLUMN IF EXISTS faction_id;
+--bun:split
+DROP TABLE IF EXISTS event_factions;
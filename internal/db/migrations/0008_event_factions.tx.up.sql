@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS event_factions (
+	id SERIAL PRIMARY KEY,
+	event_id INTEGER NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+	name TEXT NOT NULL,
+	max_players INTEGER CHECK (max_players IS NULL OR max_players > 0),
+	position INTEGER NOT NULL DEFAULT 0,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+--bun:split
+CREATE UNIQUE INDEX IF NOT EXISTS event_factions_event_name_unique_idx ON event_factions (event_id, lower(name));
+--bun:split
+ALTER TABLE event_registrations ADD COLUMN IF NOT EXISTS faction_id INTEGER REFERENCES event_factions(id) ON DELETE SET NULL;
+--bun:split
+CREATE INDEX IF NOT EXISTS event_registrations_faction_idx ON event_registrations (faction_id) WHERE faction_id IS NOT NULL;
@@ -0,0 +1,20 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMPTZ;
+--bun:split
+-- Accounts created before verification existed keep working.
+UPDATE users SET email_verified_at = created_at WHERE email_verified_at IS NULL;
+--bun:split
+-- Single-use tokens mailed for email verification and password resets. Only
+-- the SHA-256 of the token is stored.
+CREATE TABLE IF NOT EXISTS user_tokens (
+	id SERIAL PRIMARY KEY,
+	user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	purpose TEXT NOT NULL,
+	token_hash TEXT NOT NULL,
+	expires_at TIMESTAMPTZ NOT NULL,
+	used_at TIMESTAMPTZ,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+--bun:split
+CREATE UNIQUE INDEX IF NOT EXISTS user_tokens_token_hash_unique_idx ON user_tokens (token_hash);
+--bun:split
+CREATE INDEX IF NOT EXISTS user_tokens_user_purpose_idx ON user_tokens (user_id, purpose);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS revoked_tokens;
+--bun:split
+DROP TABLE IF EXISTS sessions;
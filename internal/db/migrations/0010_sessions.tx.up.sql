@@ -0,0 +1,26 @@
+-- One row per signed-in device. Refresh tokens rotate on every use; the
+-- previous hash is kept to detect reuse of a stolen token.
+CREATE TABLE IF NOT EXISTS sessions (
+	id TEXT PRIMARY KEY,
+	user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	refresh_token_hash TEXT NOT NULL,
+	previous_token_hash TEXT,
+	user_agent TEXT NOT NULL DEFAULT '',
+	ip TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	last_used_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	expires_at TIMESTAMPTZ NOT NULL,
+	revoked_at TIMESTAMPTZ
+);
+--bun:split
+CREATE UNIQUE INDEX IF NOT EXISTS sessions_refresh_token_hash_unique_idx ON sessions (refresh_token_hash);
+--bun:split
+CREATE INDEX IF NOT EXISTS sessions_previous_token_hash_idx ON sessions (previous_token_hash) WHERE previous_token_hash IS NOT NULL;
+--bun:split
+CREATE INDEX IF NOT EXISTS sessions_user_id_idx ON sessions (user_id);
+--bun:split
+-- Access token ids (jti) revoked before their natural expiry.
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+	jti TEXT PRIMARY KEY,
+	expires_at TIMESTAMPTZ NOT NULL
+);
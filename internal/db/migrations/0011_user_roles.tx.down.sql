@@ -0,0 +1,9 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS is_admin BOOLEAN NOT NULL DEFAULT false;
+--bun:split
+ALTER TABLE users ADD COLUMN IF NOT EXISTS is_maintenance_user BOOLEAN NOT NULL DEFAULT false;
+--bun:split
+UPDATE users SET is_admin = true WHERE id IN (SELECT user_id FROM user_roles WHERE role = 'admin');
+--bun:split
+UPDATE users SET is_maintenance_user = true WHERE id IN (SELECT user_id FROM user_roles WHERE role = 'maintenance');
+--bun:split
+DROP TABLE IF EXISTS user_roles;
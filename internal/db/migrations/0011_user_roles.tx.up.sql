@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS user_roles (
+	user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	role TEXT NOT NULL CHECK (role IN ('admin', 'moderator', 'organizer', 'maintenance')),
+	granted_by_email TEXT NOT NULL DEFAULT '',
+	granted_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	PRIMARY KEY (user_id, role)
+);
+--bun:split
+CREATE INDEX IF NOT EXISTS user_roles_role_idx ON user_roles (role);
+--bun:split
+INSERT INTO user_roles (user_id, role, granted_by_email)
+SELECT id, 'admin', 'migration' FROM users WHERE is_admin
+ON CONFLICT DO NOTHING;
+--bun:split
+INSERT INTO user_roles (user_id, role, granted_by_email)
+SELECT id, 'maintenance', 'migration' FROM users WHERE is_maintenance_user
+ON CONFLICT DO NOTHING;
+--bun:split
+ALTER TABLE users DROP COLUMN IF EXISTS is_admin;
+--bun:split
+ALTER TABLE users DROP COLUMN IF EXISTS is_maintenance_user;
@@ -0,0 +1,16 @@
+-- Single-row table holding the maintenance switch (id is always 1).
+CREATE TABLE IF NOT EXISTS maintenance_settings (
+	id INTEGER PRIMARY KEY CHECK (id = 1),
+	enabled BOOLEAN NOT NULL DEFAULT false,
+	starts_at TIMESTAMPTZ,
+	ends_at TIMESTAMPTZ,
+	message TEXT NOT NULL DEFAULT '',
+	eta TIMESTAMPTZ,
+	allow_reads BOOLEAN NOT NULL DEFAULT false,
+	allowed_routes TEXT[] NOT NULL DEFAULT '{}',
+	updated_by_email TEXT NOT NULL DEFAULT '',
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	CHECK (ends_at IS NULL OR starts_at IS NULL OR ends_at > starts_at)
+);
+--bun:split
+INSERT INTO maintenance_settings (id) VALUES (1) ON CONFLICT (id) DO NOTHING;
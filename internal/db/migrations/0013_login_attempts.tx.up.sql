@@ -0,0 +1,11 @@
+-- Failed sign-ins per normalized email, tracked for unknown emails too so
+-- throttling does not reveal which accounts exist.
+CREATE TABLE IF NOT EXISTS login_attempts (
+	email TEXT PRIMARY KEY,
+	failed_count INTEGER NOT NULL DEFAULT 0,
+	last_failed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	next_attempt_at TIMESTAMPTZ,
+	locked_at TIMESTAMPTZ
+);
+--bun:split
+CREATE INDEX IF NOT EXISTS login_attempts_last_failed_at_idx ON login_attempts (last_failed_at);
@@ -0,0 +1,7 @@
+-- Fixed-window request counters shared by all API replicas when
+-- RATE_LIMIT_BACKEND=postgres.
+CREATE UNLOGGED TABLE IF NOT EXISTS rate_limit_counters (
+	key TEXT PRIMARY KEY,
+	window_start TIMESTAMPTZ NOT NULL,
+	count INTEGER NOT NULL
+);
@@ -0,0 +1,5 @@
+ALTER TABLE events DROP COLUMN IF EXISTS thumbnail_color;
+--bun:split
+ALTER TABLE events DROP COLUMN IF EXISTS thumbnail_blurhash;
+--bun:split
+ALTER TABLE events DROP COLUMN IF EXISTS thumbnail_variants;
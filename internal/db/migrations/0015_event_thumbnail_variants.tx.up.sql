@@ -0,0 +1,6 @@
+-- Resized thumbnail renditions (variant name -> URL) and load placeholders.
+ALTER TABLE events ADD COLUMN IF NOT EXISTS thumbnail_variants JSONB;
+--bun:split
+ALTER TABLE events ADD COLUMN IF NOT EXISTS thumbnail_blurhash TEXT;
+--bun:split
+ALTER TABLE events ADD COLUMN IF NOT EXISTS thumbnail_color TEXT;
@@ -0,0 +1,16 @@
+-- Every object written to upload storage, so replaced or deleted files can be
+-- removed and the bucket reconciled against the database.
+CREATE TABLE IF NOT EXISTS stored_objects (
+	key TEXT PRIMARY KEY,
+	url TEXT NOT NULL,
+	event_id INTEGER REFERENCES events(id) ON DELETE SET NULL,
+	kind TEXT NOT NULL,
+	content_type TEXT NOT NULL DEFAULT '',
+	size_bytes BIGINT NOT NULL DEFAULT 0,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	deleted_at TIMESTAMPTZ
+);
+--bun:split
+CREATE INDEX IF NOT EXISTS stored_objects_event_idx ON stored_objects (event_id) WHERE deleted_at IS NULL;
+--bun:split
+CREATE INDEX IF NOT EXISTS stored_objects_url_idx ON stored_objects (url);
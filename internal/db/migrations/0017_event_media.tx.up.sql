@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS event_media (
+	id SERIAL PRIMARY KEY,
+	event_id INTEGER NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+	type TEXT NOT NULL CHECK (type IN ('image', 'pdf')),
+	caption TEXT NOT NULL DEFAULT '',
+	storage_key TEXT NOT NULL UNIQUE,
+	url TEXT NOT NULL,
+	content_type TEXT NOT NULL,
+	size_bytes BIGINT NOT NULL DEFAULT 0,
+	position INTEGER NOT NULL DEFAULT 0,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+--bun:split
+CREATE INDEX IF NOT EXISTS event_media_event_position_idx ON event_media (event_id, position);
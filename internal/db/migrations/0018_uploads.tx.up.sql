@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS uploads (
+	id TEXT PRIMARY KEY,
+	user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	event_id INTEGER NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+	storage_key TEXT NOT NULL UNIQUE,
+	content_type TEXT NOT NULL,
+	size_bytes BIGINT NOT NULL,
+	caption TEXT NOT NULL DEFAULT '',
+	expires_at TIMESTAMPTZ NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	finalized_at TIMESTAMPTZ,
+	media_id INTEGER REFERENCES event_media(id) ON DELETE SET NULL
+);
+--bun:split
+CREATE INDEX IF NOT EXISTS uploads_user_id_idx ON uploads (user_id);
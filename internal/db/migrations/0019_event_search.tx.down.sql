@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS events_search_vector_idx;
+--bun:split
+DROP TRIGGER IF EXISTS events_search_vector_update ON events;
+--bun:split
+DROP FUNCTION IF EXISTS events_search_vector_update();
+--bun:split
+ALTER TABLE events DROP COLUMN IF EXISTS search_vector;
+--bun:split
+DROP FUNCTION IF EXISTS events_search_vector(TEXT, TEXT, TEXT, TEXT);
+--bun:split
+DROP TEXT SEARCH CONFIGURATION IF EXISTS hr_unaccent;
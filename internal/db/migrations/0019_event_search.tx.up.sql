@@ -0,0 +1,41 @@
+-- Full-text search over events. Postgres has no Croatian dictionary, so the
+-- "hr_unaccent" configuration folds case and diacritics (Čakovec -> cakovec)
+-- without stemming; searches match word prefixes instead.
+CREATE EXTENSION IF NOT EXISTS unaccent;
+--bun:split
+DO $$
+BEGIN
+	IF NOT EXISTS (SELECT 1 FROM pg_ts_config WHERE cfgname = 'hr_unaccent') THEN
+		CREATE TEXT SEARCH CONFIGURATION hr_unaccent (COPY = simple);
+		ALTER TEXT SEARCH CONFIGURATION hr_unaccent
+			ALTER MAPPING FOR hword, hword_part, word WITH unaccent, simple;
+	END IF;
+END
+$$;
+--bun:split
+CREATE OR REPLACE FUNCTION events_search_vector(name TEXT, description TEXT, detailed_description TEXT, location TEXT)
+RETURNS tsvector LANGUAGE sql STABLE AS $$
+	SELECT setweight(to_tsvector('hr_unaccent', coalesce(name, '')), 'A') ||
+		setweight(to_tsvector('hr_unaccent', coalesce(location, '')), 'B') ||
+		setweight(to_tsvector('hr_unaccent', coalesce(description, '')), 'B') ||
+		setweight(to_tsvector('hr_unaccent', coalesce(detailed_description, '')), 'C')
+$$;
+--bun:split
+ALTER TABLE events ADD COLUMN IF NOT EXISTS search_vector tsvector;
+--bun:split
+CREATE OR REPLACE FUNCTION events_search_vector_update() RETURNS trigger LANGUAGE plpgsql AS $$
+BEGIN
+	NEW.search_vector := events_search_vector(NEW.name, NEW.description, NEW.detailed_description, NEW.location);
+	RETURN NEW;
+END
+$$;
+--bun:split
+DROP TRIGGER IF EXISTS events_search_vector_update ON events;
+--bun:split
+CREATE TRIGGER events_search_vector_update
+	BEFORE INSERT OR UPDATE OF name, description, detailed_description, location ON events
+	FOR EACH ROW EXECUTE FUNCTION events_search_vector_update();
+--bun:split
+UPDATE events SET search_vector = events_search_vector(name, description, detailed_description, location);
+--bun:split
+CREATE INDEX IF NOT EXISTS events_search_vector_idx ON events USING GIN (search_vector);
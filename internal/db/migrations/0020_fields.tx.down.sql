@@ -0,0 +1,5 @@
+ALTER TABLE stored_objects DROP COLUMN IF EXISTS field_id;
+--bun:split
+ALTER TABLE events DROP COLUMN IF EXISTS field_id;
+--bun:split
+DROP TABLE IF EXISTS fields;
@@ -0,0 +1,34 @@
+-- Airsoft fields (venues). Events may link one; its name and coordinates
+-- are then copied onto the event.
+CREATE TABLE IF NOT EXISTS fields (
+	id SERIAL PRIMARY KEY,
+	status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+	rejection_reason TEXT,
+	reviewed_at TIMESTAMPTZ,
+	reviewed_by_email TEXT,
+	name TEXT NOT NULL,
+	lat DOUBLE PRECISION NOT NULL,
+	lng DOUBLE PRECISION NOT NULL,
+	address TEXT NOT NULL DEFAULT '',
+	county TEXT NOT NULL DEFAULT '',
+	surface_type TEXT NOT NULL DEFAULT '',
+	owner_club TEXT NOT NULL DEFAULT '',
+	photos JSONB NOT NULL DEFAULT '[]',
+	rules TEXT NOT NULL DEFAULT '',
+	parking_notes TEXT NOT NULL DEFAULT '',
+	creator_email TEXT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	updated_at TIMESTAMPTZ
+);
+--bun:split
+CREATE UNIQUE INDEX IF NOT EXISTS fields_name_unique_idx ON fields (lower(name)) WHERE status <> 'rejected';
+--bun:split
+CREATE INDEX IF NOT EXISTS fields_status_idx ON fields (status);
+--bun:split
+ALTER TABLE events ADD COLUMN IF NOT EXISTS field_id INTEGER REFERENCES fields(id) ON DELETE SET NULL;
+--bun:split
+CREATE INDEX IF NOT EXISTS events_field_idx ON events (field_id) WHERE field_id IS NOT NULL;
+--bun:split
+ALTER TABLE stored_objects ADD COLUMN IF NOT EXISTS field_id INTEGER REFERENCES fields(id) ON DELETE SET NULL;
+--bun:split
+CREATE INDEX IF NOT EXISTS stored_objects_field_idx ON stored_objects (field_id) WHERE deleted_at IS NULL;
@@ -0,0 +1,7 @@
+ALTER TABLE stored_objects DROP COLUMN IF EXISTS club_id;
+--bun:split
+ALTER TABLE events DROP COLUMN IF EXISTS club_id;
+--bun:split
+DROP TABLE IF EXISTS club_members;
+--bun:split
+DROP TABLE IF EXISTS clubs;
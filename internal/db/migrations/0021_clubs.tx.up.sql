@@ -0,0 +1,74 @@
+-- Clubs replace the free-text users.airsoft_club, which now mirrors the name
+-- of the user's club.
+CREATE TABLE IF NOT EXISTS clubs (
+	id SERIAL PRIMARY KEY,
+	slug TEXT NOT NULL,
+	name TEXT NOT NULL,
+	description TEXT NOT NULL DEFAULT '',
+	logo TEXT,
+	home_field_id INTEGER REFERENCES fields(id) ON DELETE SET NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	updated_at TIMESTAMPTZ
+);
+--bun:split
+CREATE UNIQUE INDEX IF NOT EXISTS clubs_slug_unique_idx ON clubs (slug);
+--bun:split
+CREATE UNIQUE INDEX IF NOT EXISTS clubs_name_unique_idx ON clubs (lower(name));
+--bun:split
+CREATE TABLE IF NOT EXISTS club_members (
+	club_id INTEGER NOT NULL REFERENCES clubs(id) ON DELETE CASCADE,
+	user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	role TEXT NOT NULL DEFAULT 'member' CHECK (role IN ('owner', 'officer', 'member')),
+	status TEXT NOT NULL CHECK (status IN ('active', 'invited', 'requested')),
+	invited_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	joined_at TIMESTAMPTZ,
+	PRIMARY KEY (club_id, user_id)
+);
+--bun:split
+-- A player belongs to at most one club.
+CREATE UNIQUE INDEX IF NOT EXISTS club_members_one_club_idx ON club_members (user_id) WHERE status = 'active';
+--bun:split
+ALTER TABLE events ADD COLUMN IF NOT EXISTS club_id INTEGER REFERENCES clubs(id) ON DELETE SET NULL;
+--bun:split
+CREATE INDEX IF NOT EXISTS events_club_idx ON events (club_id) WHERE club_id IS NOT NULL;
+--bun:split
+ALTER TABLE stored_objects ADD COLUMN IF NOT EXISTS club_id INTEGER REFERENCES clubs(id) ON DELETE SET NULL;
+--bun:split
+CREATE INDEX IF NOT EXISTS stored_objects_club_idx ON stored_objects (club_id) WHERE deleted_at IS NULL;
+--bun:split
+-- Turn the free-text club names into clubs. Spellings that differ only in
+-- case or surrounding spaces become one club named after the most common
+-- one; placeholders for "no club" are skipped.
+WITH names AS (
+	SELECT lower(btrim(airsoft_club)) AS key,
+		mode() WITHIN GROUP (ORDER BY btrim(airsoft_club)) AS name
+	FROM users
+	WHERE btrim(COALESCE(airsoft_club, '')) <> ''
+		AND lower(btrim(airsoft_club)) NOT IN ('no club/freelancer', 'no club', 'freelancer', 'none', 'bez kluba', '-', 'n/a')
+	GROUP BY 1
+), slugs AS (
+	SELECT key, name,
+		COALESCE(NULLIF(btrim(left(regexp_replace(lower(unaccent(name)), '[^a-z0-9]+', '-', 'g'), 60), '-'), ''), 'club') AS base
+	FROM names
+)
+INSERT INTO clubs (slug, name)
+SELECT CASE
+		WHEN row_number() OVER (PARTITION BY base ORDER BY key) = 1 THEN base
+		ELSE base || '-' || left(md5(key), 6)
+	END,
+	name
+FROM slugs
+ON CONFLICT DO NOTHING;
+--bun:split
+-- Migrated clubs start without an owner; a moderator assigns one.
+INSERT INTO club_members (club_id, user_id, role, status, joined_at)
+SELECT c.id, u.id, 'member', 'active', u.created_at
+FROM users AS u
+JOIN clubs AS c ON lower(c.name) = lower(btrim(u.airsoft_club))
+ON CONFLICT DO NOTHING;
+--bun:split
+UPDATE users AS u
+SET airsoft_club = c.name
+FROM clubs AS c
+WHERE lower(c.name) = lower(btrim(u.airsoft_club)) AND u.airsoft_club <> c.name;
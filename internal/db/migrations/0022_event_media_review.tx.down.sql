@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS event_media_pending_idx;
+--bun:split
+ALTER TABLE event_media DROP COLUMN IF EXISTS reviewed_by_email;
+--bun:split
+ALTER TABLE event_media DROP COLUMN IF EXISTS reviewed_at;
+--bun:split
+ALTER TABLE event_media DROP COLUMN IF EXISTS status;
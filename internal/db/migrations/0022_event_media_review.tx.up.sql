@@ -0,0 +1,11 @@
+-- Media added to a published event waits for moderation. Existing media was
+-- already public and stays approved.
+ALTER TABLE event_media ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'approved' CHECK (status IN ('pending', 'approved'));
+--bun:split
+ALTER TABLE event_media ALTER COLUMN status SET DEFAULT 'pending';
+--bun:split
+ALTER TABLE event_media ADD COLUMN IF NOT EXISTS reviewed_at TIMESTAMPTZ;
+--bun:split
+ALTER TABLE event_media ADD COLUMN IF NOT EXISTS reviewed_by_email TEXT;
+--bun:split
+CREATE INDEX IF NOT EXISTS event_media_pending_idx ON event_media (created_at) WHERE status = 'pending';
@@ -0,0 +1,3 @@
+-- Per-session key the next refresh token is derived from, so a token
+-- presented twice within the rotation grace window yields the same successor.
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS rotation_key TEXT;
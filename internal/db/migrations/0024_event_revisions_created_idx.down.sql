@@ -0,0 +1 @@
+DROP INDEX IF EXISTS event_revisions_created_by_idx;
@@ -0,0 +1,3 @@
+-- Backs the daily submission quota, which counts created revisions so
+-- deleting an event does not give the submission back.
+CREATE INDEX IF NOT EXISTS event_revisions_created_by_idx ON event_revisions (actor_email, created_at) WHERE action = 'created';
@@ -0,0 +1,2 @@
+-- The free-text club names reset by the up migration are not restored.
+SELECT 1;
@@ -0,0 +1,7 @@
+-- airsoft_club is derived from club membership; free-text names typed by
+-- players outside a club are reset.
+UPDATE users AS u
+SET airsoft_club = COALESCE(
+	(SELECT c.name FROM club_members AS m JOIN clubs AS c ON c.id = m.club_id
+		WHERE m.user_id = u.id AND m.status = 'active'),
+	'No Club/Freelancer');
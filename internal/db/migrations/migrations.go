@@ -0,0 +1,21 @@
+// Package migrations holds the numbered SQL migrations embedded into the API
+// binary. Files are named NNNN_description.[tx.]up.sql / .down.sql; the
+// ".tx" variants run inside a single transaction.
+package migrations
+
+import (
+	"embed"
+
+	"github.com/uptrace/bun/migrate"
+)
+
+//go:embed *.sql
+var sqlMigrations embed.FS
+
+var Migrations = migrate.NewMigrations()
+
+func init() {
+	if err := Migrations.Discover(sqlMigrations); err != nil {
+		panic(err)
+	}
+}
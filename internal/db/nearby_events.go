@@ -0,0 +1,93 @@
+package db
+
+import (
+	"context"
+	"math"
+	"strings"
+
+	"github.com/MKolega/AirsoftHubCroatia/types"
+)
+
+const earthRadiusKm = 6371.0
+
+// BoundingBox is a map viewport in degrees.
+type BoundingBox struct {
+	MinLat float64
+	MinLng float64
+	MaxLat float64
+	MaxLng float64
+}
+
+// NearbyOptions describes a location search. When BBox is set the search is
+// limited to the viewport, otherwise to RadiusKm around Lat/Lng. Distance is
+// always measured from Lat/Lng.
+type NearbyOptions struct {
+	Lat      float64
+	Lng      float64
+	RadiusKm float64
+	BBox     *BoundingBox
+	From     string
+	To       string
+	Category string
+	Limit    int
+}
+
+// Great-circle distance in km from (?, ?) to the row's lat/lng.
+const haversineExpr = `? * 2 * asin(LEAST(1, sqrt(
+	power(sin(radians(lat - ?) / 2), 2) +
+	cos(radians(?)) * cos(radians(lat)) * power(sin(radians(lng - ?) / 2), 2)
+)))`
+
+// radiusBounds returns a lat/lng box enclosing the circle so the
+// events_lat_lng_idx index can prune rows before the exact distance check.
+func radiusBounds(lat, lng, radiusKm float64) BoundingBox {
+	dLat := radiusKm / earthRadiusKm * 180 / math.Pi
+	minLat := math.Max(lat-dLat, -90)
+	maxLat := math.Min(lat+dLat, 90)
+
+	cosLat := math.Cos(lat * math.Pi / 180)
+	if cosLat < 1e-6 || maxLat >= 90 || minLat <= -90 {
+		return BoundingBox{MinLat: minLat, MinLng: -180, MaxLat: maxLat, MaxLng: 180}
+	}
+	dLng := radiusKm / (earthRadiusKm * cosLat) * 180 / math.Pi
+	return BoundingBox{MinLat: minLat, MinLng: lng - dLng, MaxLat: maxLat, MaxLng: lng + dLng}
+}
+
+func GetNearbyEvents(opts NearbyOptions) ([]types.NearbyEvent, error) {
+	box := opts.BBox
+	if box == nil {
+		b := radiusBounds(opts.Lat, opts.Lng, opts.RadiusKm)
+		box = &b
+	}
+
+	events := []types.NearbyEvent{}
+	q := Bun.NewSelect().
+		Model(&events).
+		ColumnExpr("?TableColumns").
+		ColumnExpr(haversineExpr+" AS distance_km", earthRadiusKm, opts.Lat, opts.Lat, opts.Lng).
+		Where("lat IS NOT NULL AND lng IS NOT NULL").
+		Where("lat BETWEEN ? AND ?", box.MinLat, box.MaxLat).
+		Where("lng BETWEEN ? AND ?", box.MinLng, box.MaxLng)
+
+	if opts.BBox == nil {
+		q = q.Where(haversineExpr+" <= ?", earthRadiusKm, opts.Lat, opts.Lat, opts.Lng, opts.RadiusKm)
+	}
+	q = wherePublished(q)
+	q = whereEventInDateRange(q, opts.From, opts.To)
+	if cat := strings.TrimSpace(opts.Category); cat != "" {
+		q = q.Where("category = ?", cat)
+	}
+
+	q = q.OrderExpr("distance_km ASC").OrderExpr("id ASC")
+	if opts.Limit > 0 {
+		q = q.Limit(opts.Limit)
+	}
+
+	if err := q.Scan(context.Background()); err != nil {
+		return nil, err
+	}
+	for i := range events {
+		events[i].Event = *events[i].PublishedVersion()
+	}
+	return events, nil
+}
@@ -0,0 +1,241 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/MKolega/AirsoftHubCroatia/types"
+	"github.com/uptrace/bun"
+)
+
+const (
+	RegistrationRegistered = "registered"
+	RegistrationWaitlisted = "waitlisted"
+)
+
+var ErrRegistrationClosed = errors.New("registration closed")
+
+type eventRegistration struct {
+	bun.BaseModel `bun:"table:event_registrations,alias:er"`
+
+	EventID   int       `bun:"event_id,pk"`
+	UserID    int       `bun:"user_id,pk"`
+	Status    string    `bun:"status,notnull"`
+	FactionID int       `bun:"faction_id,nullzero"`
+	CreatedAt time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+	UpdatedAt time.Time `bun:"updated_at,nullzero,notnull,default:current_timestamp"`
+}
+
+// lockOpenEvent locks a published event row so capacity checks and
+// promotions for the same event are serialized. An event whose edit awaits
+// review is still published in its approved version.
+func lockOpenEvent(ctx context.Context, tx bun.Tx, eventID int) (*types.Event, error) {
+	return lockEvent(ctx, tx, eventID, true)
+}
+
+// lockEvent locks the event row, optionally only while it is published.
+func lockEvent(ctx context.Context, tx bun.Tx, eventID int, published bool) (*types.Event, error) {
+	event := new(types.Event)
+	q := tx.NewSelect().
+		Model(event).
+		Where("id = ?", eventID)
+	if published {
+		q = wherePublished(q)
+	}
+	err := q.For("UPDATE").
+		Limit(1).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrEventNotFound
+		}
+		return nil, err
+	}
+	return event, nil
+}
+
+func countRegistrations(ctx context.Context, idb bun.IDB, eventID int, status string) (int, error) {
+	return idb.NewSelect().
+		Model((*eventRegistration)(nil)).
+		Where("event_id = ? AND status = ?", eventID, status).
+		Count(ctx)
+}
+
+// promoteWaitlist moves the longest-waiting players into free spots.
+// Callers must hold the event row lock.
+func promoteWaitlist(ctx context.Context, idb bun.IDB, eventID int, maxPlayers int) error {
+	q := idb.NewSelect().
+		Model((*eventRegistration)(nil)).
+		Column("user_id").
+		Where("event_id = ? AND status = ?", eventID, RegistrationWaitlisted).
+		Order("created_at", "user_id")
+
+	if maxPlayers > 0 {
+		registered, err := countRegistrations(ctx, idb, eventID, RegistrationRegistered)
+		if err != nil {
+			return err
+		}
+		free := maxPlayers - registered
+		if free <= 0 {
+			return nil
+		}
+		q = q.Limit(free)
+	}
+
+	_, err := idb.NewUpdate().
+		Model((*eventRegistration)(nil)).
+		Set("status = ?", RegistrationRegistered).
+		Set("updated_at = now()").
+		Where("event_id = ?", eventID).
+		Where("user_id IN (?)", q).
+		Exec(ctx)
+	return err
+}
+
+func registrationStatus(ctx context.Context, idb bun.IDB, event *types.Event, userID int) (*types.RegistrationStatus, error) {
+	out := &types.RegistrationStatus{MaxPlayers: event.MaxPlayers}
+
+	var err error
+	if out.RegisteredCount, err = countRegistrations(ctx, idb, event.ID, RegistrationRegistered); err != nil {
+		return nil, err
+	}
+	if out.WaitlistCount, err = countRegistrations(ctx, idb, event.ID, RegistrationWaitlisted); err != nil {
+		return nil, err
+	}
+
+	reg := new(eventRegistration)
+	err = idb.NewSelect().Model(reg).Where("event_id = ? AND user_id = ?", event.ID, userID).Limit(1).Scan(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return out, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	out.Status = reg.Status
+	out.FactionID = reg.FactionID
+
+	if reg.Status == RegistrationWaitlisted {
+		ahead, err := idb.NewSelect().
+			Model((*eventRegistration)(nil)).
+			Where("event_id = ? AND status = ?", event.ID, RegistrationWaitlisted).
+			Where("(created_at, user_id) < (?, ?)", reg.CreatedAt, reg.UserID).
+			Count(ctx)
+		if err != nil {
+			return nil, err
+		}
+		out.Position = ahead + 1
+	}
+	return out, nil
+}
+
+// RegisterForEvent signs the user up, or puts them on the waitlist when the
+// event is full. factionID (0 for none) picks a side; registering again only
+// changes the faction.
+func RegisterForEvent(eventID int, userID int, factionID int) (*types.RegistrationStatus, error) {
+	ctx := context.Background()
+	var status *types.RegistrationStatus
+
+	err := Bun.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		event, err := lockOpenEvent(ctx, tx, eventID)
+		if err != nil {
+			return err
+		}
+		end := event.EndsAt
+		if end.IsZero() {
+			end = event.StartsAt
+		}
+		if !end.IsZero() && !end.After(time.Now()) {
+			return ErrRegistrationClosed
+		}
+
+		st := RegistrationRegistered
+		if event.MaxPlayers > 0 {
+			registered, err := countRegistrations(ctx, tx, eventID, RegistrationRegistered)
+			if err != nil {
+				return err
+			}
+			if registered >= event.MaxPlayers {
+				st = RegistrationWaitlisted
+			}
+		}
+
+		if factionID > 0 {
+			if err := checkFactionCapacity(ctx, tx, eventID, factionID, userID); err != nil {
+				return err
+			}
+		}
+
+		row := &eventRegistration{EventID: eventID, UserID: userID, Status: st, FactionID: factionID}
+		q := tx.NewInsert().Model(row)
+		if factionID > 0 {
+			q = q.On("CONFLICT (event_id, user_id) DO UPDATE").
+				Set("faction_id = EXCLUDED.faction_id").
+				Set("updated_at = now()")
+		} else {
+			q = q.On("CONFLICT (event_id, user_id) DO NOTHING")
+		}
+		if _, err := q.Exec(ctx); err != nil {
+			return err
+		}
+
+		status, err = registrationStatus(ctx, tx, event, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// UnregisterFromEvent removes the user's registration and promotes from the
+// waitlist if that freed a spot. Players can cancel whatever the event's
+// review state.
+func UnregisterFromEvent(eventID int, userID int) error {
+	ctx := context.Background()
+	return Bun.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		event, err := lockEvent(ctx, tx, eventID, false)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.NewDelete().
+			Model((*eventRegistration)(nil)).
+			Where("event_id = ? AND user_id = ?", eventID, userID).
+			Exec(ctx); err != nil {
+			return err
+		}
+		return promoteWaitlist(ctx, tx, eventID, event.MaxPlayers)
+	})
+}
+
+func GetRegistrationStatus(event *types.Event, userID int) (*types.RegistrationStatus, error) {
+	return registrationStatus(context.Background(), Bun, event, userID)
+}
+
+// GetEventRoster lists registered players first, then the waitlist, each in
+// sign-up order.
+func GetEventRoster(eventID int) ([]types.RosterEntry, error) {
+	return eventRoster(context.Background(), Bun, eventID)
+}
+
+func eventRoster(ctx context.Context, idb bun.IDB, eventID int) ([]types.RosterEntry, error) {
+	roster := []types.RosterEntry{}
+	err := idb.NewSelect().
+		Model((*eventRegistration)(nil)).
+		ColumnExpr("er.user_id, er.status, er.created_at AS registered_at").
+		ColumnExpr("COALESCE(u.username, '') AS username").
+		ColumnExpr("COALESCE(u.airsoft_club, '') AS airsoft_club").
+		ColumnExpr("COALESCE(er.faction_id, 0) AS faction_id").
+		ColumnExpr("COALESCE(f.name, '') AS faction").
+		Join("JOIN users AS u ON u.id = er.user_id").
+		Join("LEFT JOIN event_factions AS f ON f.id = er.faction_id").
+		Where("er.event_id = ?", eventID).
+		OrderExpr("er.status = ? DESC", RegistrationRegistered).
+		OrderExpr("er.created_at, er.user_id").
+		Scan(ctx, &roster)
+	if err != nil {
+		return nil, err
+	}
+	return roster, nil
+}
@@ -0,0 +1,182 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/MKolega/AirsoftHubCroatia/internal/config"
+	"github.com/MKolega/AirsoftHubCroatia/types"
+	"github.com/uptrace/bun"
+)
+
+var (
+	ErrRoleNotGranted = errors.New("user does not have this role")
+	ErrLastAdmin      = errors.New("cannot remove the last admin")
+)
+
+type userRole struct {
+	bun.BaseModel `bun:"table:user_roles,alias:ur"`
+
+	UserID         int       `bun:"user_id,pk"`
+	Role           string    `bun:"role,pk"`
+	GrantedByEmail string    `bun:"granted_by_email,notnull"`
+	GrantedAt      time.Time `bun:"granted_at,nullzero,notnull,default:current_timestamp"`
+}
+
+func grantRole(ctx context.Context, idb bun.IDB, userID int, role string, grantedBy string) error {
+	_, err := idb.NewInsert().
+		Model(&userRole{UserID: userID, Role: role, GrantedByEmail: grantedBy}).
+		On("CONFLICT (user_id, role) DO NOTHING").
+		Exec(ctx)
+	return err
+}
+
+// GrantRole gives the user a role. Granting a role twice is a no-op.
+func GrantRole(userID int, role string, grantedBy string) error {
+	return grantRole(context.Background(), Bun, userID, role, grantedBy)
+}
+
+// RevokeRole takes a role away. The last admin cannot be demoted so the site
+// never ends up without one.
+func RevokeRole(userID int, role string) error {
+	ctx := context.Background()
+	return Bun.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if role == types.RoleAdmin {
+			// Serialize admin demotions so two admins cannot remove each other.
+			if _, err := tx.ExecContext(ctx, `LOCK TABLE user_roles IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+				return err
+			}
+			admins, err := tx.NewSelect().
+				Model((*userRole)(nil)).
+				Where("role = ?", types.RoleAdmin).
+				Count(ctx)
+			if err != nil {
+				return err
+			}
+			if admins <= 1 {
+				has, err := tx.NewSelect().
+					Model((*userRole)(nil)).
+					Where("user_id = ? AND role = ?", userID, role).
+					Exists(ctx)
+				if err != nil {
+					return err
+				}
+				if has {
+					return ErrLastAdmin
+				}
+			}
+		}
+
+		res, err := tx.NewDelete().
+			Model((*userRole)(nil)).
+			Where("user_id = ? AND role = ?", userID, role).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return ErrRoleNotGranted
+		}
+		return nil
+	})
+}
+
+// ListUsers returns up to 100 users with their roles, optionally filtered by
+// an email/username substring and by role.
+func ListUsers(query string, role string) ([]types.User, error) {
+	users := []types.User{}
+	q := selectUsersWithRoles(&users).
+		OrderExpr("?TableAlias.email").
+		Limit(100)
+	if query = strings.TrimSpace(query); query != "" {
+		pattern := "%" + escapeLike(query) + "%"
+		q = q.Where("(?TableAlias.email ILIKE ? OR ?TableAlias.username ILIKE ?)", pattern, pattern)
+	}
+	if role != "" {
+		q = q.Where("EXISTS (SELECT 1 FROM user_roles AS ur WHERE ur.user_id = ?TableAlias.id AND ur.role = ?)", role)
+	}
+	if err := q.Scan(context.Background()); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func adminCount(ctx context.Context, idb bun.IDB) (int, error) {
+	return idb.NewSelect().
+		Model((*userRole)(nil)).
+		Where("role = ?", types.RoleAdmin).
+		Count(ctx)
+}
+
+// BootstrapAdminsFromEnv grants admin to the ADMIN_EMAILS accounts, but only
+// while nobody holds the admin role. After that, roles are managed through
+// the admin API.
+func BootstrapAdminsFromEnv() error {
+	ctx := context.Background()
+	count, err := adminCount(ctx, Bun)
+	if err != nil || count > 0 {
+		return err
+	}
+	return Bun.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		for _, email := range bootstrapAdminEmails() {
+			var ids []int
+			if err := tx.NewSelect().
+				Model((*types.User)(nil)).
+				Column("id").
+				Where("lower(email) = ?", email).
+				Scan(ctx, &ids); err != nil {
+				return err
+			}
+			for _, id := range ids {
+				if err := grantRole(ctx, tx, id, types.RoleAdmin, "bootstrap"); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// BootstrapAdminOnRegister grants admin to a newly registered ADMIN_EMAILS
+// account if the site has no admin yet.
+func BootstrapAdminOnRegister(user *types.User) error {
+	email := strings.ToLower(strings.TrimSpace(user.Email))
+	listed := false
+	for _, e := range bootstrapAdminEmails() {
+		if e == email {
+			listed = true
+			break
+		}
+	}
+	if !listed {
+		return nil
+	}
+
+	ctx := context.Background()
+	return Bun.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if _, err := tx.ExecContext(ctx, `LOCK TABLE user_roles IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+			return err
+		}
+		count, err := adminCount(ctx, tx)
+		if err != nil || count > 0 {
+			return err
+		}
+		if err := grantRole(ctx, tx, user.ID, types.RoleAdmin, "bootstrap"); err != nil {
+			return err
+		}
+		user.Roles = append(user.Roles, types.RoleAdmin)
+		return nil
+	})
+}
+
+func bootstrapAdminEmails() []string {
+	var emails []string
+	for _, p := range strings.Split(config.GetEnv("ADMIN_EMAILS", ""), ",") {
+		if email := strings.ToLower(strings.TrimSpace(p)); email != "" {
+			emails = append(emails, email)
+		}
+	}
+	return emails
+}
@@ -14,17 +14,6 @@ type eventSave struct {
 	CreatedAt time.Time `bun:"created_at,notnull"`
 }
 
-func CreateSavedEventsTable() error {
-	query := `CREATE TABLE IF NOT EXISTS event_saves (
-			user_id INTEGER NOT NULL,
-			event_id INTEGER NOT NULL,
-			created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
-			PRIMARY KEY(user_id, event_id)
-		);`
-	_, err := Bun.ExecContext(context.Background(), query)
-	return err
-}
-
 func SaveEvent(userID int, eventID int) error {
 	row := &eventSave{UserID: userID, EventID: eventID}
 	_, err := Bun.NewInsert().
@@ -65,14 +54,15 @@ func GetSavedEventsForUser(userID int) ([]types.Event, error) {
 	}
 
 	var events []types.Event
-	err = Bun.NewSelect().
+	q := Bun.NewSelect().
 		Model(&events).
-		Where("id IN (?)", bun.In(ids)).
-		Where("status = ?", "approved").
-		Order("date").
+		Where("id IN (?)", bun.In(ids))
+	err = wherePublished(q).
+		Order("starts_at").
 		Scan(context.Background())
 	if err != nil {
 		return nil, err
 	}
+	publishEvents(events)
 	return events, nil
 }
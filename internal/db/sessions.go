@@ -0,0 +1,245 @@
+package db
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"github.com/MKolega/AirsoftHubCroatia/types"
+	"github.com/uptrace/bun"
+)
+
+var (
+	ErrInvalidSession  = errors.New("session is invalid or expired")
+	ErrSessionNotFound = errors.New("session not found")
+)
+
+type revokedToken struct {
+	bun.BaseModel `bun:"table:revoked_tokens,alias:rt"`
+
+	JTI       string    `bun:"jti,pk"`
+	ExpiresAt time.Time `bun:"expires_at,notnull"`
+}
+
+// rotationGrace is how long a just-rotated refresh token keeps working.
+// Browser tabs sharing one session refresh at about the same moment; the
+// late ones get the same successor token instead of tripping reuse detection.
+const rotationGrace = 10 * time.Second
+
+// CreateSession starts a session and returns it with the raw refresh token.
+func CreateSession(userID int, userAgent string, ip string, ttl time.Duration) (*types.Session, string, error) {
+	id, err := newRandomToken(16)
+	if err != nil {
+		return nil, "", err
+	}
+	raw, err := newRandomToken(32)
+	if err != nil {
+		return nil, "", err
+	}
+	key, err := newRandomToken(32)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255]
+	}
+
+	session := &types.Session{
+		ID:               id,
+		UserID:           userID,
+		RefreshTokenHash: hashToken(raw),
+		RotationKey:      key,
+		UserAgent:        userAgent,
+		IP:               ip,
+		ExpiresAt:        time.Now().Add(ttl),
+	}
+	if _, err := Bun.NewInsert().Model(session).Exec(context.Background()); err != nil {
+		return nil, "", err
+	}
+	return session, raw, nil
+}
+
+// nextRefreshToken derives the token that replaces raw. It is keyed per
+// session so it cannot be computed from a stolen token alone.
+func nextRefreshToken(key string, raw string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(raw))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// RotateSession swaps a refresh token for a new one and extends the session.
+// The previous token is honoured for rotationGrace and yields the same new
+// token; presenting it later revokes the whole session, since either the
+// client or an attacker holds a stolen copy.
+func RotateSession(raw string, ttl time.Duration) (*types.Session, string, error) {
+	hash := hashToken(raw)
+
+	ctx := context.Background()
+	session := new(types.Session)
+	next := ""
+	reused := false
+	err := Bun.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		err := tx.NewSelect().
+			Model(session).
+			Where("refresh_token_hash = ?", hash).
+			For("UPDATE").
+			Limit(1).
+			Scan(ctx)
+		if errors.Is(err, sql.ErrNoRows) {
+			err = tx.NewSelect().
+				Model(session).
+				Where("previous_token_hash = ? AND revoked_at IS NULL", hash).
+				For("UPDATE").
+				Limit(1).
+				Scan(ctx)
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrInvalidSession
+			}
+			if err != nil {
+				return err
+			}
+			if session.RotationKey != "" && time.Since(session.LastUsedAt) < rotationGrace {
+				next = nextRefreshToken(session.RotationKey, raw)
+				if hashToken(next) == session.RefreshTokenHash && session.ExpiresAt.After(time.Now()) {
+					return nil
+				}
+				return ErrInvalidSession
+			}
+			reused = true
+			_, err = tx.NewUpdate().
+				Model((*types.Session)(nil)).
+				Set("revoked_at = now()").
+				Where("id = ?", session.ID).
+				Exec(ctx)
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		if !session.RevokedAt.IsZero() || !session.ExpiresAt.After(time.Now()) {
+			return ErrInvalidSession
+		}
+
+		if session.RotationKey == "" {
+			// Sessions created before rotation keys existed get one now.
+			if session.RotationKey, err = newRandomToken(32); err != nil {
+				return err
+			}
+		}
+		next = nextRefreshToken(session.RotationKey, raw)
+		session.PreviousTokenHash = session.RefreshTokenHash
+		session.RefreshTokenHash = hashToken(next)
+		session.LastUsedAt = time.Now()
+		session.ExpiresAt = time.Now().Add(ttl)
+		_, err = tx.NewUpdate().
+			Model(session).
+			Column("refresh_token_hash", "previous_token_hash", "rotation_key", "last_used_at", "expires_at").
+			WherePK().
+			Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	// Committed outside the error path so the revocation sticks.
+	if reused {
+		return nil, "", ErrInvalidSession
+	}
+	return session, next, nil
+}
+
+// RevokeSessionByRefreshToken ends the session a refresh token belongs to.
+func RevokeSessionByRefreshToken(raw string) error {
+	_, err := Bun.NewUpdate().
+		Model((*types.Session)(nil)).
+		Set("revoked_at = now()").
+		Where("refresh_token_hash = ? AND revoked_at IS NULL", hashToken(raw)).
+		Exec(context.Background())
+	return err
+}
+
+// RevokeSession ends one of the user's sessions.
+func RevokeSession(userID int, sessionID string) error {
+	res, err := Bun.NewUpdate().
+		Model((*types.Session)(nil)).
+		Set("revoked_at = now()").
+		Where("id = ? AND user_id = ?", sessionID, userID).
+		Where("revoked_at IS NULL").
+		Exec(context.Background())
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// RevokeOtherSessions ends every session of the user except keepID.
+func RevokeOtherSessions(userID int, keepID string) error {
+	_, err := Bun.NewUpdate().
+		Model((*types.Session)(nil)).
+		Set("revoked_at = now()").
+		Where("user_id = ? AND id <> ?", userID, keepID).
+		Where("revoked_at IS NULL").
+		Exec(context.Background())
+	return err
+}
+
+func revokeUserSessions(ctx context.Context, idb bun.IDB, userID int) error {
+	_, err := idb.NewUpdate().
+		Model((*types.Session)(nil)).
+		Set("revoked_at = now()").
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Exec(ctx)
+	return err
+}
+
+// ListActiveSessions returns the user's unrevoked, unexpired sessions, most
+// recently used first.
+func ListActiveSessions(userID int) ([]types.Session, error) {
+	sessions := []types.Session{}
+	err := Bun.NewSelect().
+		Model(&sessions).
+		Where("user_id = ?", userID).
+		Where("revoked_at IS NULL AND expires_at > now()").
+		Order("last_used_at DESC").
+		Scan(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// RevokeAccessToken adds an access token id to the revocation list until the
+// token would have expired anyway.
+func RevokeAccessToken(jti string, expiresAt time.Time) error {
+	ctx := context.Background()
+	if _, err := Bun.NewDelete().
+		Model((*revokedToken)(nil)).
+		Where("expires_at < now()").
+		Exec(ctx); err != nil {
+		return err
+	}
+	_, err := Bun.NewInsert().
+		Model(&revokedToken{JTI: jti, ExpiresAt: expiresAt}).
+		On("CONFLICT (jti) DO NOTHING").
+		Exec(ctx)
+	return err
+}
+
+// AccessTokenRevoked reports whether the token id was revoked or its session
+// has ended.
+func AccessTokenRevoked(jti string, sessionID string) (bool, error) {
+	var revoked bool
+	err := Bun.NewRaw(
+		`SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = ?)
+			OR NOT EXISTS (SELECT 1 FROM sessions WHERE id = ? AND revoked_at IS NULL AND expires_at > now())`,
+		jti, sessionID,
+	).Scan(context.Background(), &revoked)
+	return revoked, err
+}
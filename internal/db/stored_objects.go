@@ -0,0 +1,154 @@
+package db
+
+import (
+	"context"
+
+	"github.com/MKolega/AirsoftHubCroatia/types"
+	"github.com/uptrace/bun"
+)
+
+// Stored object kinds.
+const (
+	ObjectKindThumbnail  = "thumbnail"
+	ObjectKindMedia      = "media"
+	ObjectKindFieldPhoto = "field_photo"
+	ObjectKindClubLogo   = "club_logo"
+)
+
+// TrackStoredObjects records freshly uploaded objects before they are
+// attached to an event, so a failed request still leaves a row for GC.
+func TrackStoredObjects(objects []types.StoredObject) error {
+	if len(objects) == 0 {
+		return nil
+	}
+	_, err := Bun.NewInsert().
+		Model(&objects).
+		On("CONFLICT (key) DO NOTHING").
+		Exec(context.Background())
+	return err
+}
+
+// AttachStoredObjects ties uploaded objects to the event that now uses them.
+func AttachStoredObjects(eventID int, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	_, err := Bun.NewUpdate().
+		Model((*types.StoredObject)(nil)).
+		Set("event_id = ?", eventID).
+		Where("key IN (?)", bun.In(keys)).
+		Where("deleted_at IS NULL").
+		Exec(context.Background())
+	return err
+}
+
+// DetachEventObjects unlinks the event's objects of the given kind whose URL
+// is not in keep and returns their keys so the caller can delete them.
+func DetachEventObjects(eventID int, kind string, keep []string) ([]string, error) {
+	var keys []string
+	q := Bun.NewUpdate().
+		Model((*types.StoredObject)(nil)).
+		Set("event_id = NULL").
+		Where("event_id = ?", eventID).
+		Where("kind = ?", kind).
+		Where("deleted_at IS NULL")
+	if len(keep) > 0 {
+		q = q.Where("url NOT IN (?)", bun.In(keep))
+	}
+	_, err := q.Returning("key").Exec(context.Background(), &keys)
+	return keys, err
+}
+
+// EventStoredObjectKeys lists the live objects attached to an event.
+func EventStoredObjectKeys(eventID int) ([]string, error) {
+	var keys []string
+	err := Bun.NewSelect().
+		Model((*types.StoredObject)(nil)).
+		Column("key").
+		Where("event_id = ?", eventID).
+		Where("deleted_at IS NULL").
+		Scan(context.Background(), &keys)
+	return keys, err
+}
+
+// MarkStoredObjectsDeleted records that the objects were removed from storage.
+func MarkStoredObjectsDeleted(keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	_, err := Bun.NewUpdate().
+		Model((*types.StoredObject)(nil)).
+		Set("event_id = NULL").
+		Set("field_id = NULL").
+		Set("club_id = NULL").
+		Set("deleted_at = now()").
+		Where("key IN (?)", bun.In(keys)).
+		Exec(context.Background())
+	return err
+}
+
+// ListStoredObjects returns every tracked object, including deleted ones.
+func ListStoredObjects() ([]types.StoredObject, error) {
+	objects := []types.StoredObject{}
+	err := Bun.NewSelect().Model(&objects).Scan(context.Background())
+	return objects, err
+}
+
+// ThumbnailURLIndex maps every thumbnail URL referenced by an event to its
+// id. GC uses it to adopt objects uploaded before tracking existed.
+func ThumbnailURLIndex() (map[string]int, error) {
+	var events []types.Event
+	err := Bun.NewSelect().
+		Model(&events).
+		Column("id", "thumbnail", "thumbnail_variants").
+		Where("thumbnail IS NOT NULL AND thumbnail <> ''").
+		Scan(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	urls := make(map[string]int, len(events))
+	for _, e := range events {
+		for _, u := range e.ThumbnailURLs() {
+			urls[u] = e.ID
+		}
+	}
+	return urls, nil
+}
+
+// dropDeletedThumbnail clears a restored snapshot's thumbnail when its
+// objects were already removed from storage.
+func dropDeletedThumbnail(ctx context.Context, idb bun.IDB, event *types.Event) error {
+	urls := event.ThumbnailURLs()
+	if len(urls) == 0 {
+		return nil
+	}
+	gone, err := idb.NewSelect().
+		Model((*types.StoredObject)(nil)).
+		Where("url IN (?)", bun.In(urls)).
+		Where("deleted_at IS NOT NULL").
+		Exists(ctx)
+	if err != nil || !gone {
+		return err
+	}
+	event.Thumbnail = ""
+	event.ThumbnailVariants = nil
+	event.ThumbnailBlurhash = ""
+	event.ThumbnailColor = ""
+	return nil
+}
+
+// reattachThumbnail links the objects behind a restored thumbnail to the
+// event again.
+func reattachThumbnail(ctx context.Context, idb bun.IDB, event *types.Event) error {
+	urls := event.ThumbnailURLs()
+	if len(urls) == 0 {
+		return nil
+	}
+	_, err := idb.NewUpdate().
+		Model((*types.StoredObject)(nil)).
+		Set("event_id = ?", event.ID).
+		Where("url IN (?)", bun.In(urls)).
+		Where("deleted_at IS NULL").
+		Exec(ctx)
+	return err
+}
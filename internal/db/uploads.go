@@ -0,0 +1,74 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/MKolega/AirsoftHubCroatia/types"
+	"github.com/uptrace/bun"
+)
+
+var (
+	ErrUploadNotFound  = errors.New("upload not found")
+	ErrUploadFinalized = errors.New("upload already finalized")
+)
+
+// CreateUpload records a pending direct upload.
+func CreateUpload(upload *types.Upload) error {
+	_, err := Bun.NewInsert().
+		Model(upload).
+		Returning("*").
+		Exec(context.Background())
+	return err
+}
+
+// GetUpload returns an upload by ID.
+func GetUpload(id string) (*types.Upload, error) {
+	upload := new(types.Upload)
+	err := Bun.NewSelect().
+		Model(upload).
+		Where("id = ?", id).
+		Scan(context.Background())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUploadNotFound
+		}
+		return nil, err
+	}
+	return upload, nil
+}
+
+// FinalizeUpload adds media to the upload's event and marks the upload
+// finalized, so a retried finalize cannot add the same file twice.
+func FinalizeUpload(uploadID string, media *types.EventMedia) error {
+	ctx := context.Background()
+	return Bun.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		upload := new(types.Upload)
+		err := tx.NewSelect().
+			Model(upload).
+			Where("id = ?", uploadID).
+			For("UPDATE").
+			Scan(ctx)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrUploadNotFound
+			}
+			return err
+		}
+		if !upload.FinalizedAt.IsZero() {
+			return ErrUploadFinalized
+		}
+
+		if err := addEventMedia(ctx, tx, media); err != nil {
+			return err
+		}
+		_, err = tx.NewUpdate().
+			Model((*types.Upload)(nil)).
+			Set("finalized_at = now()").
+			Set("media_id = ?", media.ID).
+			Where("id = ?", uploadID).
+			Exec(ctx)
+		return err
+	})
+}
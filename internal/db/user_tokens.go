@@ -0,0 +1,144 @@
+package db
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+const (
+	TokenVerifyEmail   = "verify_email"
+	TokenResetPassword = "reset_password"
+)
+
+var ErrInvalidToken = errors.New("token is invalid or expired")
+
+type userToken struct {
+	bun.BaseModel `bun:"table:user_tokens,alias:ut"`
+
+	ID        int       `bun:"id,pk,autoincrement"`
+	UserID    int       `bun:"user_id,notnull"`
+	Purpose   string    `bun:"purpose,notnull"`
+	TokenHash string    `bun:"token_hash,notnull"`
+	ExpiresAt time.Time `bun:"expires_at,notnull"`
+	UsedAt    time.Time `bun:"used_at,nullzero"`
+	CreatedAt time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp"`
+}
+
+func hashToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+// newRandomToken returns n random bytes encoded for use in URLs.
+func newRandomToken(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// CreateUserToken issues a new token for purpose and returns the raw value to
+// mail out. Older unused tokens for the same purpose stop working.
+func CreateUserToken(userID int, purpose string, ttl time.Duration) (string, error) {
+	raw, err := newRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	ctx := context.Background()
+	err = Bun.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if _, err := tx.NewDelete().
+			Model((*userToken)(nil)).
+			Where("user_id = ? AND purpose = ?", userID, purpose).
+			Where("used_at IS NULL").
+			Exec(ctx); err != nil {
+			return err
+		}
+		_, err := tx.NewInsert().
+			Model(&userToken{
+				UserID:    userID,
+				Purpose:   purpose,
+				TokenHash: hashToken(raw),
+				ExpiresAt: time.Now().Add(ttl),
+			}).
+			Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return raw, nil
+}
+
+// consumeUserToken marks an unexpired, unused token as used and returns its
+// user id.
+func consumeUserToken(ctx context.Context, idb bun.IDB, purpose string, raw string) (int, error) {
+	var userID int
+	err := idb.NewUpdate().
+		Model((*userToken)(nil)).
+		Set("used_at = now()").
+		Where("token_hash = ? AND purpose = ?", hashToken(raw), purpose).
+		Where("used_at IS NULL AND expires_at > now()").
+		Returning("user_id").
+		Scan(ctx, &userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrInvalidToken
+		}
+		return 0, err
+	}
+	return userID, nil
+}
+
+// VerifyEmailWithToken consumes a verification token and marks the owner's
+// email as verified.
+func VerifyEmailWithToken(raw string) error {
+	ctx := context.Background()
+	return Bun.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		userID, err := consumeUserToken(ctx, tx, TokenVerifyEmail, raw)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx,
+			`UPDATE users SET email_verified_at = COALESCE(email_verified_at, now()) WHERE id = ?`,
+			userID,
+		)
+		return err
+	})
+}
+
+// ResetPasswordWithToken consumes a reset token and stores the new password
+// hash. Completing a reset also proves the email address, so it is marked
+// verified, lifts any sign-in lockout and signs the user out everywhere.
+func ResetPasswordWithToken(raw string, passwordHash string) error {
+	ctx := context.Background()
+	return Bun.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		userID, err := consumeUserToken(ctx, tx, TokenResetPassword, raw)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx,
+			`UPDATE users SET password_hash = ?, email_verified_at = COALESCE(email_verified_at, now()) WHERE id = ?`,
+			passwordHash, userID,
+		)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx,
+			`DELETE FROM login_attempts WHERE email = (SELECT lower(email) FROM users WHERE id = ?)`,
+			userID,
+		); err != nil {
+			return err
+		}
+		return revokeUserSessions(ctx, tx, userID)
+	})
+}
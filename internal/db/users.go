@@ -5,48 +5,12 @@ import (
 	"errors"
 	"strings"
 
-	"github.com/MKolega/AirsoftHubCroatia/internal/config"
 	"github.com/MKolega/AirsoftHubCroatia/types"
+	"github.com/uptrace/bun"
 )
 
 var ErrUserNotFound = errors.New("user not found")
 
-func CreateUsersTable() error {
-	query := `CREATE TABLE IF NOT EXISTS users (
-			id SERIAL PRIMARY KEY,
-			email TEXT UNIQUE NOT NULL,
-			username TEXT,
-			airsoft_club TEXT,
-			is_admin BOOLEAN NOT NULL DEFAULT false,
-			is_maintenance_user BOOLEAN NOT NULL DEFAULT false,
-			password_hash TEXT NOT NULL,
-			created_at TIMESTAMPTZ NOT NULL DEFAULT now()
-		);`
-	if _, err := Bun.ExecContext(context.Background(), query); err != nil {
-		return err
-	}
-	if _, err := Bun.ExecContext(context.Background(), `ALTER TABLE users ADD COLUMN IF NOT EXISTS username TEXT;`); err != nil {
-		return err
-	}
-	if _, err := Bun.ExecContext(context.Background(), `ALTER TABLE users ADD COLUMN IF NOT EXISTS airsoft_club TEXT;`); err != nil {
-		return err
-	}
-	if _, err := Bun.ExecContext(context.Background(), `ALTER TABLE users ADD COLUMN IF NOT EXISTS is_admin BOOLEAN NOT NULL DEFAULT false;`); err != nil {
-		return err
-	}
-	if _, err := Bun.ExecContext(context.Background(), `ALTER TABLE users ADD COLUMN IF NOT EXISTS is_maintenance_user BOOLEAN NOT NULL DEFAULT false;`); err != nil {
-		return err
-	}
-	// Case-insensitive uniqueness for usernames (ignores empty usernames)
-	if _, err := Bun.ExecContext(
-		context.Background(),
-		`CREATE UNIQUE INDEX IF NOT EXISTS users_username_unique_idx ON users (lower(username)) WHERE username IS NOT NULL AND username <> '';`,
-	); err != nil {
-		return err
-	}
-	return nil
-}
-
 func UsernameTaken(username string, excludeUserID int) (bool, error) {
 	uname := strings.ToLower(strings.TrimSpace(username))
 	if uname == "" {
@@ -75,57 +39,39 @@ func UpdateUserProfile(userID int, username string, airsoftClub string) error {
 	return err
 }
 
-func PromoteAdminsFromEnv() error {
-	raw := strings.TrimSpace(config.GetEnv("ADMIN_EMAILS", ""))
-	if raw == "" {
-		return nil
-	}
-
-	parts := strings.Split(raw, ",")
-	for _, p := range parts {
-		email := strings.ToLower(strings.TrimSpace(p))
-		if email == "" {
-			continue
-		}
-		if _, err := Bun.ExecContext(
-			context.Background(),
-			`UPDATE users SET is_admin=true WHERE lower(email)=?`,
-			email,
-		); err != nil {
-			return err
-		}
-	}
-
-	return nil
+// selectUsersWithRoles selects users with their granted roles filled in.
+func selectUsersWithRoles(model any) *bun.SelectQuery {
+	return Bun.NewSelect().
+		Model(model).
+		ColumnExpr("?TableAlias.*").
+		ColumnExpr("ARRAY(SELECT ur.role FROM user_roles AS ur WHERE ur.user_id = ?TableAlias.id ORDER BY ur.role) AS roles")
 }
 
-func PromoteMaintenanceUsersFromEnv() error {
-	raw := strings.TrimSpace(config.GetEnv("MAINTENANCE_USER_EMAILS", ""))
-	if raw == "" {
-		return nil
+func GetUserByEmail(email string) (*types.User, error) {
+	user := new(types.User)
+	err := selectUsersWithRoles(user).Where("email = ?", email).Limit(1).Scan(context.Background())
+	if err != nil {
+		return nil, ErrUserNotFound
 	}
+	return user, nil
+}
 
-	parts := strings.Split(raw, ",")
-	for _, p := range parts {
-		email := strings.ToLower(strings.TrimSpace(p))
-		if email == "" {
-			continue
-		}
-		if _, err := Bun.ExecContext(
-			context.Background(),
-			`UPDATE users SET is_maintenance_user=true WHERE lower(email)=?`,
-			email,
-		); err != nil {
-			return err
-		}
+// GetUserByUsername looks a user up by username, ignoring case.
+func GetUserByUsername(username string) (*types.User, error) {
+	user := new(types.User)
+	err := selectUsersWithRoles(user).
+		Where("lower(?TableAlias.username) = lower(?)", strings.TrimSpace(username)).
+		Limit(1).
+		Scan(context.Background())
+	if err != nil {
+		return nil, ErrUserNotFound
 	}
-
-	return nil
+	return user, nil
 }
 
-func GetUserByEmail(email string) (*types.User, error) {
+func GetUserByID(id int) (*types.User, error) {
 	user := new(types.User)
-	err := Bun.NewSelect().Model(user).Where("email = ?", email).Limit(1).Scan(context.Background())
+	err := selectUsersWithRoles(user).Where("?TableAlias.id = ?", id).Limit(1).Scan(context.Background())
 	if err != nil {
 		return nil, ErrUserNotFound
 	}
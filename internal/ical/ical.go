@@ -0,0 +1,166 @@
+// Package ical renders RFC 5545 calendars for the public and per-user feeds.
+package ical
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	dateFormat     = "20060102"
+	dateTimeFormat = "20060102T150405Z"
+	maxLineOctets  = 75
+)
+
+type Calendar struct {
+	ProdID string
+	Name   string
+	Events []Event
+}
+
+// Event is a single VEVENT. When AllDay is set Start/End are rendered as
+// dates and End is exclusive (the day after the last day).
+type Event struct {
+	UID          string
+	Sequence     int
+	Summary      string
+	Description  string
+	Location     string
+	URL          string
+	Categories   []string
+	Lat          float64
+	Lng          float64
+	HasGeo       bool
+	AllDay       bool
+	Start        time.Time
+	End          time.Time
+	Created      time.Time
+	LastModified time.Time
+	Cancelled    bool
+}
+
+var textEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+	"\r", `\n`,
+)
+
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+// writeLine writes a content line folded at 75 octets without splitting
+// UTF-8 sequences, terminated by CRLF.
+func writeLine(b *bytes.Buffer, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines start with a space which counts towards the limit.
+		limit = maxLineOctets - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+func writeProp(b *bytes.Buffer, name, value string) {
+	writeLine(b, name+":"+value)
+}
+
+func writeText(b *bytes.Buffer, name, value string) {
+	if strings.TrimSpace(value) == "" {
+		return
+	}
+	writeProp(b, name, escapeText(value))
+}
+
+func formatUTC(t time.Time) string {
+	return t.UTC().Format(dateTimeFormat)
+}
+
+func writeEvent(b *bytes.Buffer, e Event, stamp time.Time) {
+	writeProp(b, "BEGIN", "VEVENT")
+	writeProp(b, "UID", e.UID)
+	writeProp(b, "DTSTAMP", formatUTC(stamp))
+	writeProp(b, "SEQUENCE", strconv.Itoa(e.Sequence))
+	if e.AllDay {
+		writeProp(b, "DTSTART;VALUE=DATE", e.Start.Format(dateFormat))
+		if !e.End.IsZero() {
+			writeProp(b, "DTEND;VALUE=DATE", e.End.Format(dateFormat))
+		}
+	} else {
+		writeProp(b, "DTSTART", formatUTC(e.Start))
+		if !e.End.IsZero() {
+			writeProp(b, "DTEND", formatUTC(e.End))
+		}
+	}
+	writeText(b, "SUMMARY", e.Summary)
+	writeText(b, "DESCRIPTION", e.Description)
+	writeText(b, "LOCATION", e.Location)
+	if e.HasGeo {
+		writeProp(b, "GEO", strconv.FormatFloat(e.Lat, 'f', 6, 64)+";"+strconv.FormatFloat(e.Lng, 'f', 6, 64))
+	}
+	// URL values are not escaped, so anything that could break the line is dropped.
+	if u := strings.TrimSpace(e.URL); u != "" && !strings.ContainsFunc(u, unicode.IsControl) {
+		writeProp(b, "URL", u)
+	}
+	if len(e.Categories) > 0 {
+		cats := make([]string, 0, len(e.Categories))
+		for _, c := range e.Categories {
+			if c = strings.TrimSpace(c); c != "" {
+				cats = append(cats, escapeText(c))
+			}
+		}
+		if len(cats) > 0 {
+			writeProp(b, "CATEGORIES", strings.Join(cats, ","))
+		}
+	}
+	if !e.Created.IsZero() {
+		writeProp(b, "CREATED", formatUTC(e.Created))
+	}
+	if !e.LastModified.IsZero() {
+		writeProp(b, "LAST-MODIFIED", formatUTC(e.LastModified))
+	}
+	if e.Cancelled {
+		writeProp(b, "STATUS", "CANCELLED")
+	} else {
+		writeProp(b, "STATUS", "CONFIRMED")
+	}
+	writeProp(b, "END", "VEVENT")
+}
+
+// Write renders cal as a VCALENDAR stream.
+func Write(w io.Writer, cal Calendar) error {
+	var b bytes.Buffer
+	stamp := time.Now()
+
+	writeProp(&b, "BEGIN", "VCALENDAR")
+	writeProp(&b, "VERSION", "2.0")
+	writeProp(&b, "PRODID", cal.ProdID)
+	writeProp(&b, "CALSCALE", "GREGORIAN")
+	writeProp(&b, "METHOD", "PUBLISH")
+	if cal.Name != "" {
+		writeText(&b, "X-WR-CALNAME", cal.Name)
+		writeText(&b, "NAME", cal.Name)
+	}
+	for _, e := range cal.Events {
+		writeEvent(&b, e, stamp)
+	}
+	writeProp(&b, "END", "VCALENDAR")
+
+	_, err := w.Write(b.Bytes())
+	return err
+}
@@ -0,0 +1,104 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestEscapeText(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", ""},
+		{"Plain text", "Plain text"},
+		{`C:\games`, `C:\\games`},
+		{"Zagreb; Split, Rijeka", `Zagreb\; Split\, Rijeka`},
+		{"one\ntwo", `one\ntwo`},
+		{"one\r\ntwo", `one\ntwo`},
+		{"one\rtwo", `one\ntwo`},
+		{"\\;\n", `\\\;\n`},
+		{"Šibenik: 10:00", "Šibenik: 10:00"},
+	}
+	for _, tt := range tests {
+		if got := escapeText(tt.in); got != tt.want {
+			t.Errorf("escapeText(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWriteLineFolds(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+	}{
+		{"empty", ""},
+		{"short", "SUMMARY:Sunday game"},
+		{"exactly 75", "DESCRIPTION:" + strings.Repeat("a", 63)},
+		{"76", "DESCRIPTION:" + strings.Repeat("a", 64)},
+		{"long ascii", "DESCRIPTION:" + strings.Repeat("abcdefghij", 30)},
+		{"two-byte runes", "LOCATION:" + strings.Repeat("čćđšž", 40)},
+		{"four-byte runes", "SUMMARY:" + strings.Repeat("🎯", 50)},
+		{"mixed", "DESCRIPTION:x" + strings.Repeat("ž🎯a", 40)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			writeLine(&b, tt.line)
+			out := b.String()
+			if !strings.HasSuffix(out, "\r\n") {
+				t.Fatalf("output %q does not end with CRLF", out)
+			}
+
+			physical := strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n")
+			for i, l := range physical {
+				if len(l) > maxLineOctets {
+					t.Errorf("line %d is %d octets, want at most %d", i, len(l), maxLineOctets)
+				}
+				if i > 0 && !strings.HasPrefix(l, " ") {
+					t.Errorf("continuation line %d %q does not start with a space", i, l)
+				}
+				if !utf8.ValidString(l) {
+					t.Errorf("line %d %q splits a UTF-8 sequence", i, l)
+				}
+			}
+			if len(tt.line) <= maxLineOctets && len(physical) != 1 {
+				t.Errorf("folded a %d-octet line into %d lines", len(tt.line), len(physical))
+			}
+
+			if unfolded := strings.ReplaceAll(strings.TrimSuffix(out, "\r\n"), "\r\n ", ""); unfolded != tt.line {
+				t.Errorf("unfolded = %q, want %q", unfolded, tt.line)
+			}
+		})
+	}
+}
+
+func TestWriteEventDropsURLWithControlCharacters(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://facebook.com/events/1", "URL:https://facebook.com/events/1\r\n"},
+		{"https://x.test/\r\nEND:VEVENT\r\nBEGIN:VEVENT", ""},
+		{"https://x.test/\nATTACH:https://evil.test", ""},
+		{"https://x.test/\x00", ""},
+	}
+	for _, tt := range tests {
+		var b bytes.Buffer
+		writeEvent(&b, Event{UID: "1@test", URL: tt.url, Start: time.Unix(0, 0)}, time.Unix(0, 0))
+		out := b.String()
+		if got := strings.Count(out, "BEGIN:VEVENT"); got != 1 {
+			t.Errorf("URL %q: %d VEVENTs written, want 1", tt.url, got)
+		}
+		hasURL := strings.Contains(out, "\r\nURL:")
+		if tt.want == "" && hasURL {
+			t.Errorf("URL %q was written", tt.url)
+		}
+		if tt.want != "" && !strings.Contains(out, tt.want) {
+			t.Errorf("URL %q: output %q does not contain %q", tt.url, out, tt.want)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/MKolega/AirsoftHubCroatia/internal/config"
+)
+
+// Message is a plain-text email.
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+var (
+	defaultOnce sync.Once
+	defaultVal  Mailer
+	defaultErr  error
+)
+
+// Default returns the mailer selected by MAILER ("smtp" or "log"). When MAILER
+// is unset, SMTP is used if SMTP_HOST is set and messages are logged
+// otherwise.
+func Default() (Mailer, error) {
+	defaultOnce.Do(func() {
+		kind := strings.ToLower(strings.TrimSpace(config.GetEnv("MAILER", "")))
+		if kind == "" {
+			kind = "log"
+			if strings.TrimSpace(config.GetEnv("SMTP_HOST", "")) != "" {
+				kind = "smtp"
+			}
+		}
+
+		switch kind {
+		case "log":
+			defaultVal = LogMailer{}
+		case "smtp":
+			defaultVal, defaultErr = NewSMTPMailerFromEnv()
+		default:
+			defaultErr = errors.New("MAILER must be \"smtp\" or \"log\"")
+		}
+	})
+	return defaultVal, defaultErr
+}
+
+// Send delivers msg with the default mailer.
+func Send(ctx context.Context, msg Message) error {
+	m, err := Default()
+	if err != nil {
+		return err
+	}
+	return m.Send(ctx, msg)
+}
+
+// LogMailer writes messages to the server log instead of sending them. Meant
+// for local development.
+type LogMailer struct{}
+
+func (LogMailer) Send(_ context.Context, msg Message) error {
+	log.Printf("mailer: to=%s subject=%q\n%s", msg.To, msg.Subject, msg.Text)
+	return nil
+}
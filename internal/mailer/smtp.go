@@ -0,0 +1,103 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/MKolega/AirsoftHubCroatia/internal/config"
+)
+
+// SMTPMailer sends mail through an SMTP relay. STARTTLS is used when the
+// server offers it; authentication is skipped when Username is empty, which
+// is what local stand-ins like MailHog expect.
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+func NewSMTPMailerFromEnv() (*SMTPMailer, error) {
+	m := &SMTPMailer{
+		Host:     strings.TrimSpace(config.GetEnv("SMTP_HOST", "")),
+		Port:     strings.TrimSpace(config.GetEnv("SMTP_PORT", "587")),
+		Username: strings.TrimSpace(config.GetEnv("SMTP_USERNAME", "")),
+		Password: config.GetEnv("SMTP_PASSWORD", ""),
+		From:     strings.TrimSpace(config.GetEnv("MAIL_FROM", "")),
+	}
+	if m.Host == "" {
+		return nil, errors.New("SMTP_HOST is required for the smtp mailer")
+	}
+	if m.From == "" {
+		return nil, errors.New("MAIL_FROM is required for the smtp mailer")
+	}
+	if _, err := mail.ParseAddress(m.From); err != nil {
+		return nil, fmt.Errorf("MAIL_FROM is invalid: %w", err)
+	}
+	return m, nil
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	from, err := mail.ParseAddress(m.From)
+	if err != nil {
+		return err
+	}
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+
+	body, err := m.buildMessage(from, to, msg)
+	if err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(net.JoinHostPort(m.Host, m.Port), auth, from.Address, []string{to.Address}, body)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (m *SMTPMailer) buildMessage(from, to *mail.Address, msg Message) ([]byte, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	domain := from.Address[strings.LastIndex(from.Address, "@")+1:]
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from.String())
+	fmt.Fprintf(&b, "To: %s\r\n", to.String())
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&b, "Message-ID: <%s@%s>\r\n", hex.EncodeToString(id), domain)
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	text := strings.ReplaceAll(msg.Text, "\r\n", "\n")
+	b.WriteString(strings.ReplaceAll(text, "\n", "\r\n"))
+	return b.Bytes(), nil
+}
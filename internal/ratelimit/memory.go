@@ -0,0 +1,56 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type memoryCounter struct {
+	start time.Time
+	end   time.Time
+	count int
+}
+
+// memoryPruneInterval bounds how often Allow sweeps expired counters.
+const memoryPruneInterval = time.Minute
+
+// Memory keeps counters in process memory. Limits reset on restart and are
+// per replica.
+type Memory struct {
+	mu        sync.Mutex
+	counters  map[string]*memoryCounter
+	lastPrune time.Time
+}
+
+func NewMemory() *Memory {
+	return &Memory{counters: make(map[string]*memoryCounter)}
+}
+
+func (m *Memory) Allow(_ context.Context, policy Policy, key string) (Result, error) {
+	now := time.Now()
+	start := windowStart(now, policy.Window)
+	k := policy.Name + ":" + key
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ctr := m.counters[k]
+	if ctr == nil || !ctr.start.Equal(start) {
+		ctr = &memoryCounter{start: start, end: start.Add(policy.Window)}
+		m.counters[k] = ctr
+	}
+	ctr.count++
+
+	// Sweep expired windows now and then instead of on every request.
+	if now.Sub(m.lastPrune) >= memoryPruneInterval {
+		for key, c := range m.counters {
+			if !now.Before(c.end) {
+				delete(m.counters, key)
+			}
+		}
+		m.lastPrune = now
+	}
+
+	return newResult(policy, ctr.count, start.Add(policy.Window).Sub(now)), nil
+}
@@ -0,0 +1,47 @@
+package ratelimit
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// Postgres keeps counters in the rate_limit_counters table, one row per key.
+type Postgres struct {
+	db   bun.IDB
+	hits atomic.Uint64
+}
+
+func NewPostgres(db bun.IDB) *Postgres {
+	return &Postgres{db: db}
+}
+
+func (p *Postgres) Allow(ctx context.Context, policy Policy, key string) (Result, error) {
+	now := time.Now()
+	start := windowStart(now, policy.Window)
+
+	var count int
+	err := p.db.NewRaw(
+		`INSERT INTO rate_limit_counters (key, window_start, count) VALUES (?, ?, 1)
+		ON CONFLICT (key) DO UPDATE SET
+			count = CASE WHEN rate_limit_counters.window_start = EXCLUDED.window_start
+				THEN rate_limit_counters.count + 1 ELSE 1 END,
+			window_start = EXCLUDED.window_start
+		RETURNING count`,
+		policy.Name+":"+key, start,
+	).Scan(ctx, &count)
+	if err != nil {
+		return Result{}, err
+	}
+
+	// Prune stale keys now and then instead of on every request.
+	if p.hits.Add(1)%1000 == 0 {
+		_, _ = p.db.NewRaw(
+			`DELETE FROM rate_limit_counters WHERE window_start < now() - interval '2 days'`,
+		).Exec(ctx)
+	}
+
+	return newResult(policy, count, start.Add(policy.Window).Sub(now)), nil
+}
@@ -0,0 +1,80 @@
+// Package ratelimit counts requests per key in fixed windows. Counters can
+// live in process memory, in Postgres or in Redis; the shared backends keep
+// limits consistent across API replicas and restarts.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/MKolega/AirsoftHubCroatia/internal/config"
+	"github.com/uptrace/bun"
+)
+
+// Policy allows Limit requests per Window for each key.
+type Policy struct {
+	Name   string
+	Limit  int
+	Window time.Duration
+}
+
+// Result describes the state of a key after a request was counted.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is the time until the current window ends.
+	Reset time.Duration
+}
+
+type Limiter interface {
+	// Allow counts one request for key under policy.
+	Allow(ctx context.Context, policy Policy, key string) (Result, error)
+}
+
+// windowStart aligns windows to the epoch so every replica agrees on them.
+func windowStart(now time.Time, window time.Duration) time.Time {
+	return now.Truncate(window)
+}
+
+func newResult(policy Policy, count int, reset time.Duration) Result {
+	remaining := policy.Limit - count
+	if remaining < 0 {
+		remaining = 0
+	}
+	if reset < 0 {
+		reset = 0
+	}
+	return Result{
+		Allowed:   count <= policy.Limit,
+		Limit:     policy.Limit,
+		Remaining: remaining,
+		Reset:     reset,
+	}
+}
+
+// FromEnv builds the limiter selected by RATE_LIMIT_BACKEND: "memory"
+// (default), "postgres" (uses idb) or "redis" (uses REDIS_URL).
+func FromEnv(idb bun.IDB) (Limiter, error) {
+	backend := strings.ToLower(strings.TrimSpace(config.GetEnv("RATE_LIMIT_BACKEND", "memory")))
+	switch backend {
+	case "", "memory":
+		return NewMemory(), nil
+	case "postgres":
+		if idb == nil {
+			return nil, errors.New("RATE_LIMIT_BACKEND=postgres needs a database connection")
+		}
+		return NewPostgres(idb), nil
+	case "redis":
+		url := strings.TrimSpace(config.GetEnv("REDIS_URL", ""))
+		if url == "" {
+			return nil, errors.New("RATE_LIMIT_BACKEND=redis needs REDIS_URL")
+		}
+		return NewRedis(url)
+	default:
+		return nil, fmt.Errorf("unknown RATE_LIMIT_BACKEND %q (memory, postgres, redis)", backend)
+	}
+}
@@ -0,0 +1,40 @@
+package ratelimit
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis keeps one expiring counter per key and window.
+type Redis struct {
+	client *redis.Client
+}
+
+func NewRedis(url string) (*Redis, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, err
+	}
+	return &Redis{client: redis.NewClient(opts)}, nil
+}
+
+func (r *Redis) Allow(ctx context.Context, policy Policy, key string) (Result, error) {
+	now := time.Now()
+	start := windowStart(now, policy.Window)
+	k := "ratelimit:" + policy.Name + ":" + key + ":" + strconv.FormatInt(start.Unix(), 10)
+
+	var incr *redis.IntCmd
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(ctx, k)
+		pipe.Expire(ctx, k, policy.Window+time.Second)
+		return nil
+	})
+	if err != nil {
+		return Result{}, err
+	}
+
+	return newResult(policy, int(incr.Val()), start.Add(policy.Window).Sub(now)), nil
+}
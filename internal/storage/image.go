@@ -0,0 +1,284 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+
+	// Decoders for the accepted upload types.
+	_ "image/gif"
+	_ "image/png"
+
+	"github.com/buckket/go-blurhash"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// maxImagePixels rejects decompression bombs before decoding. A decoded
+// image is held several times over (source, flattened RGBA, rotated copy),
+// so 16 MP already needs close to 200 MB.
+const maxImagePixels = 16_000_000
+
+// decodeSlots bounds how many uploads are decoded at once, keeping peak memory
+// independent of the number of concurrent uploads.
+var decodeSlots = make(chan struct{}, 2)
+
+const variantJPEGQuality = 82
+
+// imageVariant is one fixed-size rendition generated on upload. Crop variants
+// fill Width x Height exactly; the others are scaled down to fit inside it.
+type imageVariant struct {
+	Name   string
+	Width  int
+	Height int
+	Crop   bool
+}
+
+// ThumbnailVariantDetail is the variant stored as the event's thumbnail URL.
+const ThumbnailVariantDetail = "detail"
+
+var thumbnailVariants = []imageVariant{
+	{Name: "card", Width: 640, Height: 360, Crop: true},
+	{Name: ThumbnailVariantDetail, Width: 1600, Height: 1600},
+	{Name: "og", Width: 1200, Height: 630, Crop: true},
+}
+
+type encodedVariant struct {
+	Name string
+	Data []byte
+}
+
+type processedImage struct {
+	Variants []encodedVariant
+	Blurhash string
+	Color    string
+}
+
+// processImage decodes an upload, applies its EXIF orientation and renders
+// every variant as a fresh JPEG. Nothing from the original file is copied, so
+// EXIF (including GPS), XMP and ICC metadata never reach storage.
+func processImage(data []byte, variants []imageVariant) (*processedImage, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, &UploadError{Kind: UploadErrInvalid, Message: "Invalid image", Err: err}
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxImagePixels {
+		return nil, &UploadError{Kind: UploadErrInvalid, Message: "Image dimensions too large", Err: nil}
+	}
+
+	decodeSlots <- struct{}{}
+	defer func() { <-decodeSlots }()
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, &UploadError{Kind: UploadErrInvalid, Message: "Invalid image", Err: err}
+	}
+	img := applyOrientation(flatten(src), jpegOrientation(data))
+
+	out := &processedImage{Variants: make([]encodedVariant, 0, len(variants))}
+	for _, v := range variants {
+		var resized image.Image
+		if v.Crop {
+			resized = resizeCover(img, v.Width, v.Height)
+		} else {
+			resized = resizeFit(img, v.Width, v.Height)
+		}
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, resized, &jpeg.Options{Quality: variantJPEGQuality}); err != nil {
+			return nil, &UploadError{Kind: UploadErrInternal, Message: "Failed to process image", Err: err}
+		}
+		out.Variants = append(out.Variants, encodedVariant{Name: v.Name, Data: buf.Bytes()})
+	}
+
+	tiny := resizeFit(img, 32, 32)
+	out.Blurhash, err = blurhash.Encode(4, 3, tiny)
+	if err != nil {
+		return nil, &UploadError{Kind: UploadErrInternal, Message: "Failed to process image", Err: err}
+	}
+	out.Color = averageColor(tiny)
+	return out, nil
+}
+
+// flatten draws the image onto white so transparent areas do not turn black
+// in JPEG output.
+func flatten(src image.Image) *image.RGBA {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Over)
+	return dst
+}
+
+// applyOrientation undoes the camera rotation recorded in EXIF tag 0x0112.
+// Flips and the half turn are done in place; quarter turns swap the
+// dimensions and need a new image.
+func applyOrientation(src *image.RGBA, orientation int) *image.RGBA {
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	switch orientation {
+	case 2: // mirrored horizontally
+		for y := 0; y < h; y++ {
+			for x := 0; x < w/2; x++ {
+				swapPixels(src, x, y, w-1-x, y)
+			}
+		}
+		return src
+	case 3: // rotated 180
+		for i := 0; i < w*h/2; i++ {
+			x, y := i%w, i/w
+			swapPixels(src, x, y, w-1-x, h-1-y)
+		}
+		return src
+	case 4: // mirrored vertically
+		for y := 0; y < h/2; y++ {
+			for x := 0; x < w; x++ {
+				swapPixels(src, x, y, x, h-1-y)
+			}
+		}
+		return src
+	case 5, 6, 7, 8:
+	default:
+		return src
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, h, w))
+	for y := 0; y < w; y++ {
+		for x := 0; x < h; x++ {
+			var sx, sy int
+			switch orientation {
+			case 5: // transposed
+				sx, sy = y, x
+			case 6: // rotated 90 CW
+				sx, sy = y, h-1-x
+			case 7: // transversed
+				sx, sy = w-1-y, h-1-x
+			case 8: // rotated 90 CCW
+				sx, sy = w-1-y, x
+			}
+			si := src.PixOffset(sx, sy)
+			di := dst.PixOffset(x, y)
+			copy(dst.Pix[di:di+4], src.Pix[si:si+4])
+		}
+	}
+	return dst
+}
+
+func swapPixels(img *image.RGBA, x1, y1, x2, y2 int) {
+	i := img.PixOffset(x1, y1)
+	j := img.PixOffset(x2, y2)
+	for k := 0; k < 4; k++ {
+		img.Pix[i+k], img.Pix[j+k] = img.Pix[j+k], img.Pix[i+k]
+	}
+}
+
+// jpegOrientation reads the EXIF orientation from a JPEG's APP1 segment and
+// returns 1 (upright) when there is none.
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		if marker == 0xDA || marker == 0xD9 {
+			return 1
+		}
+		size := int(binary.BigEndian.Uint16(data[i+2:]))
+		if size < 2 || i+2+size > len(data) {
+			return 1
+		}
+		seg := data[i+4 : i+2+size]
+		if marker == 0xE1 && len(seg) > 6 && string(seg[:6]) == "Exif\x00\x00" {
+			return tiffOrientation(seg[6:])
+		}
+		i += 2 + size
+	}
+	return 1
+}
+
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[ifd:]))
+	for n := 0; n < count; n++ {
+		entry := ifd + 2 + n*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			return int(order.Uint16(tiff[entry+8:]))
+		}
+	}
+	return 1
+}
+
+// resizeCover scales the image to fill w x h and crops the overflow evenly.
+func resizeCover(src *image.RGBA, w int, h int) image.Image {
+	sw, sh := src.Rect.Dx(), src.Rect.Dy()
+	crop := src.Rect
+	if sw*h > sh*w {
+		cw := sh * w / h
+		crop.Min.X += (sw - cw) / 2
+		crop.Max.X = crop.Min.X + cw
+	} else {
+		ch := sw * h / w
+		crop.Min.Y += (sh - ch) / 2
+		crop.Max.Y = crop.Min.Y + ch
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, crop, draw.Src, nil)
+	return dst
+}
+
+// resizeFit scales the image down to fit inside w x h; it never upscales.
+func resizeFit(src *image.RGBA, w int, h int) image.Image {
+	sw, sh := src.Rect.Dx(), src.Rect.Dy()
+	if sw <= w && sh <= h {
+		return src
+	}
+	dw, dh := w, sh*w/sw
+	if dh > h {
+		dw, dh = sw*h/sh, h
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, max(dw, 1), max(dh, 1)))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, src.Rect, draw.Src, nil)
+	return dst
+}
+
+// averageColor returns the mean color as "#rrggbb" for use as a placeholder
+// background.
+func averageColor(img image.Image) string {
+	b := img.Bounds()
+	var r, g, bl, n uint64
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			cr, cg, cb, _ := img.At(x, y).RGBA()
+			r += uint64(cr >> 8)
+			g += uint64(cg >> 8)
+			bl += uint64(cb >> 8)
+			n++
+		}
+	}
+	if n == 0 {
+		return ""
+	}
+	return fmt.Sprintf("#%02x%02x%02x", r/n, g/n, bl/n)
+}
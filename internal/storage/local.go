@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/MKolega/AirsoftHubCroatia/internal/config"
+)
+
+// LocalStorage keeps objects on disk under Dir. The API serves Dir at
+// /uploads, so BaseURL is normally "/uploads".
+type LocalStorage struct {
+	Dir     string
+	BaseURL string
+}
+
+// NewLocalStorageFromEnv reads UPLOADS_DIR (default "uploads") and
+// UPLOADS_BASE_URL (default "/uploads") and makes sure the directory exists.
+func NewLocalStorageFromEnv() (*LocalStorage, error) {
+	dir := strings.TrimSpace(config.GetEnv("UPLOADS_DIR", "uploads"))
+	baseURL := strings.TrimRight(strings.TrimSpace(config.GetEnv("UPLOADS_BASE_URL", "/uploads")), "/")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, &UploadError{Kind: UploadErrNotConfigured, Message: "Uploads directory is not writable", Err: err}
+	}
+	return &LocalStorage{Dir: dir, BaseURL: baseURL}, nil
+}
+
+func (s *LocalStorage) path(key string) (string, error) {
+	if !filepath.IsLocal(filepath.FromSlash(key)) {
+		return "", &UploadError{Kind: UploadErrInternal, Message: "Invalid storage key " + key}
+	}
+	return filepath.Join(s.Dir, filepath.FromSlash(key)), nil
+}
+
+// Put writes to a temporary file first so readers never see partial objects.
+func (s *LocalStorage) Put(_ context.Context, key string, body io.Reader, _ string) error {
+	dst, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(dst), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dst)
+}
+
+func (s *LocalStorage) Delete(_ context.Context, key string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *LocalStorage) URL(key string) string {
+	return s.BaseURL + "/" + key
+}
+
+func (s *LocalStorage) Get(_ context.Context, key string) (io.ReadCloser, Object, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, Object{}, err
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, Object{}, ErrObjectNotFound
+		}
+		return nil, Object{}, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, Object{}, err
+	}
+	return f, Object{Key: key, URL: s.URL(key), Size: info.Size(), ModifiedAt: info.ModTime()}, nil
+}
+
+func (s *LocalStorage) List(ctx context.Context, prefix string, fn func(Object) error) error {
+	return filepath.WalkDir(s.Dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(s.Dir, p)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return fn(Object{Key: key, URL: s.URL(key), Size: info.Size(), ModifiedAt: info.ModTime()})
+	})
+}
+
+type publicFS struct {
+	http.FileSystem
+}
+
+// PublicFS wraps the file system serving local uploads so that files under
+// IncomingPrefix are not found.
+func PublicFS(fsys http.FileSystem) http.FileSystem {
+	return publicFS{fsys}
+}
+
+func (f publicFS) Open(name string) (http.File, error) {
+	if strings.HasPrefix(path.Clean("/"+name)+"/", "/"+IncomingPrefix) {
+		return nil, fs.ErrNotExist
+	}
+	return f.FileSystem.Open(name)
+}
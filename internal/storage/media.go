@@ -0,0 +1,211 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+)
+
+// MediaPrefix is the key prefix for event gallery images and attachments.
+const MediaPrefix = "media/"
+
+// Media types accepted for event galleries.
+const (
+	MediaTypeImage = "image"
+	MediaTypePDF   = "pdf"
+)
+
+// FieldPhotoPrefix is the key prefix for field photos.
+const FieldPhotoPrefix = "fields/"
+
+// ClubLogoPrefix is the key prefix for club logos.
+const ClubLogoPrefix = "clubs/"
+
+// IncomingPrefix is the key prefix for direct uploads awaiting finalize.
+// These raw files still carry their metadata, so they are never linked to or
+// served.
+const IncomingPrefix = "incoming/"
+
+const (
+	maxMediaImageBytes = 5 << 20
+	maxMediaPDFBytes   = 10 << 20
+
+	// Direct uploads skip the API request body, so they may be larger.
+	maxDirectImageBytes = 20 << 20
+	maxDirectPDFBytes   = 25 << 20
+)
+
+var mediaImageVariant = []imageVariant{{Name: "full", Width: 1600, Height: 1600}}
+
+// Media is a stored gallery image or attachment.
+type Media struct {
+	Type   string
+	Object Object
+}
+
+func mediaLimit(detected string, imageBytes int64, pdfBytes int64) (int64, bool) {
+	if detected == "application/pdf" {
+		return pdfBytes, true
+	}
+	_, _, ok := allowedImageExtAndType(detected)
+	return imageBytes, ok
+}
+
+// encodeMedia re-encodes images so their metadata is dropped; PDFs are kept
+// unchanged.
+func encodeMedia(detected string, data []byte) (*Media, []byte, error) {
+	if detected == "application/pdf" {
+		return &Media{Type: MediaTypePDF, Object: Object{ContentType: "application/pdf"}}, data, nil
+	}
+	processed, err := processImage(data, mediaImageVariant)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &Media{Type: MediaTypeImage, Object: Object{ContentType: "image/jpeg"}}, processed.Variants[0].Data, nil
+}
+
+func putMedia(ctx context.Context, store Storage, prefix string, media *Media, data []byte) error {
+	ext := ".jpg"
+	if media.Type == MediaTypePDF {
+		ext = ".pdf"
+	}
+	key, err := NewKey(prefix, ext)
+	if err != nil {
+		return &UploadError{Kind: UploadErrInternal, Message: "Failed to store file", Err: err}
+	}
+	if err := store.Put(ctx, key, bytes.NewReader(data), media.Object.ContentType); err != nil {
+		return &UploadError{Kind: UploadErrInternal, Message: "Failed to store file", Err: err}
+	}
+	media.Object.Key = key
+	media.Object.URL = store.URL(key)
+	media.Object.Size = int64(len(data))
+	return nil
+}
+
+// UploadMedia stores an event image or PDF sent through the API.
+func UploadMedia(ctx context.Context, fileHeader *multipart.FileHeader) (*Media, error) {
+	store, err := Default()
+	if err != nil {
+		return nil, err
+	}
+
+	detected, data, err := readUpload(fileHeader, "File", func(detected string) (int64, bool) {
+		return mediaLimit(detected, maxMediaImageBytes, maxMediaPDFBytes)
+	})
+	if err != nil {
+		return nil, err
+	}
+	media, data, err := encodeMedia(detected, data)
+	if err != nil {
+		return nil, err
+	}
+	if err := putMedia(ctx, store, MediaPrefix, media, data); err != nil {
+		return nil, err
+	}
+	return media, nil
+}
+
+// UploadFieldPhoto stores a re-encoded field photo. Only images are accepted.
+func UploadFieldPhoto(ctx context.Context, fileHeader *multipart.FileHeader) (*Object, error) {
+	return uploadImage(ctx, fileHeader, FieldPhotoPrefix, "Photo")
+}
+
+// UploadClubLogo stores a re-encoded club logo. Only images are accepted.
+func UploadClubLogo(ctx context.Context, fileHeader *multipart.FileHeader) (*Object, error) {
+	return uploadImage(ctx, fileHeader, ClubLogoPrefix, "Logo")
+}
+
+func uploadImage(ctx context.Context, fileHeader *multipart.FileHeader, prefix string, label string) (*Object, error) {
+	store, err := Default()
+	if err != nil {
+		return nil, err
+	}
+
+	detected, data, err := readUpload(fileHeader, label, func(detected string) (int64, bool) {
+		_, _, ok := allowedImageExtAndType(detected)
+		return maxMediaImageBytes, ok
+	})
+	if err != nil {
+		return nil, err
+	}
+	media, data, err := encodeMedia(detected, data)
+	if err != nil {
+		return nil, err
+	}
+	if err := putMedia(ctx, store, prefix, media, data); err != nil {
+		return nil, err
+	}
+	return &media.Object, nil
+}
+
+// DirectUploadKey validates a client's declared type and size for a direct
+// upload and returns a fresh key for it.
+func DirectUploadKey(contentType string, size int64) (string, error) {
+	maxBytes, ok := mediaLimit(contentType, maxDirectImageBytes, maxDirectPDFBytes)
+	if !ok {
+		return "", &UploadError{Kind: UploadErrUnsupportedType, Message: fmt.Sprintf("Unsupported file type: %s", contentType), Err: nil}
+	}
+	if size <= 0 {
+		return "", &UploadError{Kind: UploadErrInvalid, Message: "Invalid file size", Err: nil}
+	}
+	if size > maxBytes {
+		return "", &UploadError{Kind: UploadErrTooLarge, Message: fmt.Sprintf("File too large (max %dMB)", maxBytes>>20), Err: nil}
+	}
+	ext, _, ok := allowedImageExtAndType(contentType)
+	if !ok {
+		ext = ".pdf"
+	}
+	return NewKey(IncomingPrefix, ext)
+}
+
+// FinalizeDirectUpload checks the object a client uploaded to key against the
+// declared content type and returns the media to attach. The file is stored
+// again under MediaPrefix, images re-encoded, and the caller should delete
+// the original.
+func FinalizeDirectUpload(ctx context.Context, key string, declaredType string) (*Media, error) {
+	store, err := Default()
+	if err != nil {
+		return nil, err
+	}
+	body, obj, err := store.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, ErrObjectNotFound) {
+			return nil, &UploadError{Kind: UploadErrInvalid, Message: "File has not been uploaded", Err: err}
+		}
+		return nil, &UploadError{Kind: UploadErrInternal, Message: "Failed to read upload", Err: err}
+	}
+	defer body.Close()
+
+	detected, head, err := sniffContentType(body)
+	if err != nil {
+		return nil, &UploadError{Kind: UploadErrInternal, Message: "Failed to read upload", Err: err}
+	}
+	maxBytes, ok := mediaLimit(detected, maxDirectImageBytes, maxDirectPDFBytes)
+	if !ok || (detected == "application/pdf") != (declaredType == "application/pdf") {
+		return nil, &UploadError{Kind: UploadErrUnsupportedType, Message: fmt.Sprintf("Unsupported file type: %s", detected), Err: nil}
+	}
+	tooLarge := &UploadError{Kind: UploadErrTooLarge, Message: fmt.Sprintf("File too large (max %dMB)", maxBytes>>20), Err: nil}
+	if obj.Size > maxBytes {
+		return nil, tooLarge
+	}
+
+	rest, err := io.ReadAll(io.LimitReader(body, maxBytes-int64(len(head))+1))
+	if err != nil {
+		return nil, &UploadError{Kind: UploadErrInternal, Message: "Failed to read upload", Err: err}
+	}
+	data := append(head, rest...)
+	if int64(len(data)) > maxBytes {
+		return nil, tooLarge
+	}
+	media, data, err := encodeMedia(detected, data)
+	if err != nil {
+		return nil, err
+	}
+	if err := putMedia(ctx, store, MediaPrefix, media, data); err != nil {
+		return nil, err
+	}
+	return media, nil
+}
@@ -0,0 +1,192 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/MKolega/AirsoftHubCroatia/internal/config"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsConfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// S3Storage stores objects in an S3-compatible bucket such as Cloudflare R2.
+type S3Storage struct {
+	client        *s3.Client
+	bucket        string
+	publicBaseURL string
+}
+
+type s3Settings struct {
+	endpoint      string
+	accessKeyID   string
+	secretKey     string
+	bucket        string
+	publicBaseURL string
+	region        string
+}
+
+func getS3Settings() (s3Settings, error) {
+	endpoint := strings.TrimSpace(config.GetEnv("R2_ENDPOINT", ""))
+	accessKeyID := strings.TrimSpace(config.GetEnv("R2_ACCESS_KEY_ID", ""))
+	secretKey := strings.TrimSpace(config.GetEnv("R2_SECRET_ACCESS_KEY", ""))
+	bucket := strings.TrimSpace(config.GetEnv("R2_BUCKET", ""))
+	publicBaseURL := strings.TrimSpace(config.GetEnv("R2_PUBLIC_BASE_URL", ""))
+	region := strings.TrimSpace(config.GetEnv("R2_REGION", "auto"))
+
+	missing := make([]string, 0, 5)
+	if endpoint == "" {
+		missing = append(missing, "R2_ENDPOINT")
+	}
+	if accessKeyID == "" {
+		missing = append(missing, "R2_ACCESS_KEY_ID")
+	}
+	if secretKey == "" {
+		missing = append(missing, "R2_SECRET_ACCESS_KEY")
+	}
+	if bucket == "" {
+		missing = append(missing, "R2_BUCKET")
+	}
+	if publicBaseURL == "" {
+		missing = append(missing, "R2_PUBLIC_BASE_URL")
+	}
+	if len(missing) > 0 {
+		return s3Settings{}, &UploadError{Kind: UploadErrNotConfigured, Message: "Thumbnail storage is not configured (missing: " + strings.Join(missing, ", ") + ")", Err: nil}
+	}
+
+	return s3Settings{
+		endpoint:      endpoint,
+		accessKeyID:   accessKeyID,
+		secretKey:     secretKey,
+		bucket:        bucket,
+		publicBaseURL: strings.TrimRight(publicBaseURL, "/"),
+		region:        region,
+	}, nil
+}
+
+// NewS3StorageFromEnv builds the bucket client from the R2_* variables.
+func NewS3StorageFromEnv(ctx context.Context) (*S3Storage, error) {
+	cfg, err := getS3Settings()
+	if err != nil {
+		return nil, err
+	}
+
+	awsCfg, err := awsConfig.LoadDefaultConfig(
+		ctx,
+		awsConfig.WithRegion(cfg.region),
+		awsConfig.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(cfg.accessKeyID, cfg.secretKey, "")),
+	)
+	if err != nil {
+		return nil, &UploadError{Kind: UploadErrInternal, Message: "Failed to initialize thumbnail storage", Err: err}
+	}
+
+	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		o.UsePathStyle = true
+		o.BaseEndpoint = aws.String(cfg.endpoint)
+	})
+	return &S3Storage{client: client, bucket: cfg.bucket, publicBaseURL: cfg.publicBaseURL}, nil
+}
+
+func (s *S3Storage) Put(ctx context.Context, key string, body io.Reader, contentType string) error {
+	// The SDK needs a seekable body to sign the payload.
+	rs, ok := body.(io.ReadSeeker)
+	if !ok {
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return err
+		}
+		rs = bytes.NewReader(data)
+	}
+
+	putCtx, cancel := context.WithTimeout(ctx, 20*time.Second)
+	defer cancel()
+
+	_, err := s.client.PutObject(putCtx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(key),
+		Body:        rs,
+		ContentType: aws.String(contentType),
+	})
+	return err
+}
+
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	delCtx, cancel := context.WithTimeout(ctx, 20*time.Second)
+	defer cancel()
+
+	_, err := s.client.DeleteObject(delCtx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	return err
+}
+
+func (s *S3Storage) URL(key string) string {
+	return s.publicBaseURL + "/" + key
+}
+
+func (s *S3Storage) Get(ctx context.Context, key string) (io.ReadCloser, Object, error) {
+	out, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var missing *s3types.NoSuchKey
+		if errors.As(err, &missing) {
+			return nil, Object{}, ErrObjectNotFound
+		}
+		return nil, Object{}, err
+	}
+	return out.Body, Object{
+		Key:         key,
+		URL:         s.URL(key),
+		ContentType: aws.ToString(out.ContentType),
+		Size:        aws.ToInt64(out.ContentLength),
+		ModifiedAt:  aws.ToTime(out.LastModified),
+	}, nil
+}
+
+func (s *S3Storage) PresignPut(ctx context.Context, key string, contentType string, size int64, ttl time.Duration) (string, error) {
+	req, err := s3.NewPresignClient(s.client).PresignPutObject(ctx, &s3.PutObjectInput{
+		Bucket:        aws.String(s.bucket),
+		Key:           aws.String(key),
+		ContentType:   aws.String(contentType),
+		ContentLength: aws.Int64(size),
+	}, s3.WithPresignExpires(ttl))
+	if err != nil {
+		return "", err
+	}
+	return req.URL, nil
+}
+
+func (s *S3Storage) List(ctx context.Context, prefix string, fn func(Object) error) error {
+	pages := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(prefix),
+	})
+	for pages.HasMorePages() {
+		page, err := pages.NextPage(ctx)
+		if err != nil {
+			return err
+		}
+		for _, o := range page.Contents {
+			key := aws.ToString(o.Key)
+			err := fn(Object{
+				Key:        key,
+				URL:        s.URL(key),
+				Size:       aws.ToInt64(o.Size),
+				ModifiedAt: aws.ToTime(o.LastModified),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
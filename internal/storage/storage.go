@@ -0,0 +1,125 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MKolega/AirsoftHubCroatia/internal/config"
+)
+
+// Storage stores uploaded objects under slash-separated keys such as
+// "thumbnails/ab12.jpg" and knows the public URL each key is served from.
+type Storage interface {
+	Put(ctx context.Context, key string, body io.Reader, contentType string) error
+	Delete(ctx context.Context, key string) error
+	URL(key string) string
+	// Get opens an object; it returns ErrObjectNotFound for unknown keys.
+	Get(ctx context.Context, key string) (io.ReadCloser, Object, error)
+	// List calls fn for every object whose key starts with prefix.
+	List(ctx context.Context, prefix string, fn func(Object) error) error
+}
+
+// Presigner is implemented by backends clients can upload to directly.
+type Presigner interface {
+	// PresignPut returns a URL that accepts one PUT of exactly size bytes
+	// with the given Content-Type until ttl passes.
+	PresignPut(ctx context.Context, key string, contentType string, size int64, ttl time.Duration) (string, error)
+}
+
+var ErrObjectNotFound = errors.New("object not found")
+
+// Object describes a stored file.
+type Object struct {
+	Key         string
+	URL         string
+	ContentType string
+	Size        int64
+	ModifiedAt  time.Time
+}
+
+type UploadErrorKind int
+
+const (
+	UploadErrInvalid UploadErrorKind = iota + 1
+	UploadErrTooLarge
+	UploadErrUnsupportedType
+	UploadErrNotConfigured
+	UploadErrInternal
+)
+
+type UploadError struct {
+	Kind    UploadErrorKind
+	Message string
+	Err     error
+}
+
+func (e *UploadError) Error() string { return e.Message }
+func (e *UploadError) Unwrap() error { return e.Err }
+
+func IsClientUploadError(err error) bool {
+	var ue *UploadError
+	if !errors.As(err, &ue) {
+		return false
+	}
+	switch ue.Kind {
+	case UploadErrInvalid, UploadErrTooLarge, UploadErrUnsupportedType:
+		return true
+	default:
+		return false
+	}
+}
+
+var (
+	defaultOnce    sync.Once
+	defaultStorage Storage
+	defaultErr     error
+)
+
+// Default returns the backend selected by STORAGE_BACKEND ("local", "r2" or
+// "s3"). When unset, R2 is used if R2_BUCKET is configured and the local
+// uploads directory otherwise.
+func Default() (Storage, error) {
+	defaultOnce.Do(func() {
+		backend := strings.ToLower(strings.TrimSpace(config.GetEnv("STORAGE_BACKEND", "")))
+		if backend == "" {
+			backend = "local"
+			if strings.TrimSpace(config.GetEnv("R2_BUCKET", "")) != "" {
+				backend = "r2"
+			}
+		}
+
+		switch backend {
+		case "local":
+			defaultStorage, defaultErr = NewLocalStorageFromEnv()
+		case "r2", "s3":
+			defaultStorage, defaultErr = NewS3StorageFromEnv(context.Background())
+		default:
+			defaultErr = &UploadError{Kind: UploadErrNotConfigured, Message: "Unknown STORAGE_BACKEND " + backend}
+		}
+	})
+	return defaultStorage, defaultErr
+}
+
+func randomHex(bytesLen int) (string, error) {
+	b := make([]byte, bytesLen)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// NewKey returns a random object key under prefix, e.g. "thumbnails/<hex>.jpg".
+func NewKey(prefix string, ext string) (string, error) {
+	name, err := randomHex(16)
+	if err != nil {
+		return "", err
+	}
+	return path.Join(prefix, name+ext), nil
+}
@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"path"
+)
+
+// Thumbnail is a processed upload: the public URL of each variant plus
+// placeholders the frontend can show while the image loads.
+type Thumbnail struct {
+	URL      string
+	Variants map[string]string
+	Blurhash string
+	Color    string
+	Objects  []Object
+}
+
+// ThumbnailPrefix is the key prefix all thumbnail variants are stored under.
+const ThumbnailPrefix = "thumbnails/"
+
+// UploadThumbnail resizes the provided multipart file into the thumbnail
+// variants and stores them in the configured backend.
+func UploadThumbnail(ctx context.Context, fileHeader *multipart.FileHeader, maxBytes int64) (*Thumbnail, error) {
+	store, err := Default()
+	if err != nil {
+		return nil, err
+	}
+
+	if maxBytes <= 0 {
+		maxBytes = 5 << 20
+	}
+	_, data, err := readUpload(fileHeader, "Thumbnail", func(detected string) (int64, bool) {
+		_, _, ok := allowedImageExtAndType(detected)
+		return maxBytes, ok
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	processed, err := processImage(data, thumbnailVariants)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := randomHex(16)
+	if err != nil {
+		return nil, &UploadError{Kind: UploadErrInternal, Message: "Failed to store thumbnail", Err: err}
+	}
+	thumb := &Thumbnail{
+		Variants: make(map[string]string, len(processed.Variants)),
+		Blurhash: processed.Blurhash,
+		Color:    processed.Color,
+	}
+	for _, v := range processed.Variants {
+		key := path.Join(ThumbnailPrefix, dir, v.Name+".jpg")
+		if err := store.Put(ctx, key, bytes.NewReader(v.Data), "image/jpeg"); err != nil {
+			for _, o := range thumb.Objects {
+				_ = store.Delete(context.WithoutCancel(ctx), o.Key)
+			}
+			return nil, &UploadError{Kind: UploadErrInternal, Message: "Failed to store thumbnail", Err: err}
+		}
+		obj := Object{Key: key, URL: store.URL(key), ContentType: "image/jpeg", Size: int64(len(v.Data))}
+		thumb.Objects = append(thumb.Objects, obj)
+		thumb.Variants[v.Name] = obj.URL
+	}
+	thumb.URL = thumb.Variants[ThumbnailVariantDetail]
+	return thumb, nil
+}
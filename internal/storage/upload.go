@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+)
+
+func allowedImageExtAndType(detected string) (ext string, contentType string, ok bool) {
+	switch strings.ToLower(strings.TrimSpace(detected)) {
+	case "image/jpeg":
+		return ".jpg", "image/jpeg", true
+	case "image/png":
+		return ".png", "image/png", true
+	case "image/webp":
+		return ".webp", "image/webp", true
+	case "image/gif":
+		return ".gif", "image/gif", true
+	default:
+		return "", "", false
+	}
+}
+
+func sniffContentType(r io.Reader) (string, []byte, error) {
+	buf := make([]byte, 512)
+	n, err := io.ReadFull(r, buf)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", nil, err
+	}
+	buf = buf[:n]
+	return http.DetectContentType(buf), buf, nil
+}
+
+// readUpload sniffs the multipart file and reads it into memory. limit
+// returns the size cap for the detected type, or false to reject the type;
+// label names the file in error messages.
+func readUpload(fileHeader *multipart.FileHeader, label string, limit func(detected string) (int64, bool)) (string, []byte, error) {
+	if fileHeader == nil {
+		return "", nil, &UploadError{Kind: UploadErrInvalid, Message: "Missing " + strings.ToLower(label) + " file", Err: nil}
+	}
+
+	f, err := fileHeader.Open()
+	if err != nil {
+		return "", nil, &UploadError{Kind: UploadErrInternal, Message: "Failed to read " + strings.ToLower(label), Err: err}
+	}
+	defer f.Close()
+
+	detected, head, err := sniffContentType(f)
+	if err != nil {
+		return "", nil, &UploadError{Kind: UploadErrInvalid, Message: "Failed to read " + strings.ToLower(label), Err: err}
+	}
+	maxBytes, ok := limit(detected)
+	if !ok {
+		return "", nil, &UploadError{Kind: UploadErrUnsupportedType, Message: fmt.Sprintf("Unsupported file type: %s", detected), Err: nil}
+	}
+	tooLarge := &UploadError{Kind: UploadErrTooLarge, Message: fmt.Sprintf("%s too large (max %dMB)", label, maxBytes>>20), Err: nil}
+	if fileHeader.Size > maxBytes {
+		return "", nil, tooLarge
+	}
+
+	// Read the full file into memory
+	remaining := max(maxBytes-int64(len(head)), 0)
+	rest, err := io.ReadAll(io.LimitReader(f, remaining+1))
+	if err != nil {
+		return "", nil, &UploadError{Kind: UploadErrInvalid, Message: "Failed to read " + strings.ToLower(label), Err: err}
+	}
+	data := append(head, rest...)
+	if int64(len(data)) > maxBytes {
+		return "", nil, tooLarge
+	}
+	return detected, data, nil
+}
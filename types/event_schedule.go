@@ -0,0 +1,55 @@
+package types
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultEventTimeZone is used for events that don't specify a time zone and
+// for interpreting date-only filters.
+const DefaultEventTimeZone = "Europe/Zagreb"
+
+var eventLocations sync.Map
+
+// LoadEventLocation is a cached time.LoadLocation.
+func LoadEventLocation(name string) (*time.Location, error) {
+	if name == "" {
+		name = DefaultEventTimeZone
+	}
+	if loc, ok := eventLocations.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	eventLocations.Store(name, loc)
+	return loc, nil
+}
+
+// ZoneLocation returns the event's time zone, falling back to the default.
+func (e *Event) ZoneLocation() *time.Location {
+	if loc, err := LoadEventLocation(e.TimeZone); err == nil {
+		return loc
+	}
+	if loc, err := LoadEventLocation(DefaultEventTimeZone); err == nil {
+		return loc
+	}
+	return time.UTC
+}
+
+// LocalDate is the start date in the event's time zone (YYYY-MM-DD), the
+// value older clients read from the "date" field.
+func (e *Event) LocalDate() string {
+	if e.StartsAt.IsZero() {
+		return ""
+	}
+	return e.StartsAt.In(e.ZoneLocation()).Format("2006-01-02")
+}
+
+// AfterScanRow fills the legacy Date field after every bun scan.
+func (e *Event) AfterScanRow(context.Context) error {
+	e.Date = e.LocalDate()
+	return nil
+}
@@ -0,0 +1,121 @@
+package types
+
+import "slices"
+
+// Role names stored in user_roles. Every account is implicitly a RoleUser;
+// that role is never stored.
+const (
+	RoleAdmin       = "admin"
+	RoleModerator   = "moderator"
+	RoleOrganizer   = "organizer"
+	RoleMaintenance = "maintenance"
+	RoleUser        = "user"
+)
+
+// GrantableRoles lists the roles admins can hand out.
+var GrantableRoles = []string{RoleAdmin, RoleModerator, RoleOrganizer, RoleMaintenance}
+
+type Permission string
+
+const (
+	// PermReviewEvents: see the moderation queue and approve/reject events.
+	PermReviewEvents Permission = "events:review"
+	// PermManageAnyEvent: edit, delete and organize events created by others.
+	PermManageAnyEvent Permission = "events:manage_any"
+	// PermPublishDirectly: new events and edits go live without review.
+	PermPublishDirectly Permission = "events:publish"
+	// PermViewEventHistory: read the revision history of any event.
+	PermViewEventHistory Permission = "events:history"
+	// PermRestoreEvents: roll an event back to an earlier revision.
+	PermRestoreEvents Permission = "events:restore"
+	// PermManageFields: review fields and edit or delete any field.
+	PermManageFields Permission = "fields:manage"
+	// PermManageClubs: act as an owner of any club, e.g. to hand a club
+	// to its new owner.
+	PermManageClubs Permission = "clubs:manage"
+	// PermMaintenanceAccess: use the site while maintenance mode is on.
+	PermMaintenanceAccess Permission = "maintenance:access"
+	// PermManageMaintenance: switch and schedule maintenance mode.
+	PermManageMaintenance Permission = "maintenance:manage"
+	// PermManageRoles: grant and revoke roles.
+	PermManageRoles Permission = "roles:manage"
+	// PermManageUsers: account support actions such as lifting a lockout.
+	PermManageUsers Permission = "users:manage"
+)
+
+var rolePermissions = map[string][]Permission{
+	RoleModerator: {
+		PermReviewEvents,
+		PermManageAnyEvent,
+		PermPublishDirectly,
+		PermViewEventHistory,
+		PermManageFields,
+		PermManageClubs,
+	},
+	RoleOrganizer: {
+		PermPublishDirectly,
+	},
+	RoleMaintenance: {
+		PermMaintenanceAccess,
+	},
+}
+
+// ValidGrantableRole reports whether role can be granted or revoked.
+func ValidGrantableRole(role string) bool {
+	return slices.Contains(GrantableRoles, role)
+}
+
+// RolePermissions returns the permissions a role carries. Admins hold all of
+// them.
+func RolePermissions(role string) []Permission {
+	if role == RoleAdmin {
+		return []Permission{
+			PermReviewEvents,
+			PermManageAnyEvent,
+			PermPublishDirectly,
+			PermViewEventHistory,
+			PermRestoreEvents,
+			PermManageFields,
+			PermManageClubs,
+			PermMaintenanceAccess,
+			PermManageMaintenance,
+			PermManageRoles,
+			PermManageUsers,
+		}
+	}
+	return rolePermissions[role]
+}
+
+func (u *User) HasRole(role string) bool {
+	if role == RoleUser {
+		return true
+	}
+	return slices.Contains(u.Roles, role)
+}
+
+func (u *User) Can(p Permission) bool {
+	for _, role := range u.Roles {
+		if slices.Contains(RolePermissions(role), p) {
+			return true
+		}
+	}
+	return false
+}
+
+// Permissions lists every permission the user's roles carry, once each.
+func (u *User) Permissions() []Permission {
+	perms := []Permission{}
+	for _, role := range u.Roles {
+		for _, p := range RolePermissions(role) {
+			if !slices.Contains(perms, p) {
+				perms = append(perms, p)
+			}
+		}
+	}
+	return perms
+}
+
+// IsAdmin is shorthand for HasRole(RoleAdmin).
+func (u *User) IsAdmin() bool {
+	return u.HasRole(RoleAdmin)
+}
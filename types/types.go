@@ -3,34 +3,354 @@ package types
 import "time"
 
 type Event struct {
-	ID                  int       `bun:"id,pk,autoincrement" json:"id"`
-	CreatedAt           time.Time `bun:"created_at,notnull" json:"created_at,omitempty"`
-	Status              string    `bun:"status,notnull" json:"status,omitempty"`
-	RejectionReason     string    `bun:"rejection_reason,nullzero" json:"rejection_reason,omitempty"`
-	ReviewedAt          time.Time `bun:"reviewed_at,nullzero" json:"reviewed_at,omitempty"`
-	ReviewedByEmail     string    `bun:"reviewed_by_email,nullzero" json:"reviewed_by_email,omitempty"`
-	Name                string    `bun:"name,notnull" json:"name"`
-	Date                string    `bun:"date" json:"date"`
-	Description         string    `bun:"description" json:"description"`
-	DetailedDescription string    `bun:"detailed_description" json:"detailed_description,omitempty"`
-	CreatorEmail        string    `bun:"creator_email" json:"creator_email,omitempty"`
-	Location            string    `bun:"location" json:"location"`
-	Lat                 float64   `bun:"lat" json:"lat"`
-	Lng                 float64   `bun:"lng" json:"lng"`
-	Category            string    `bun:"category" json:"category,omitempty"`
-	FacebookLink        string    `bun:"facebook_link" json:"facebook_link,omitempty"`
-	Thumbnail           string    `bun:"thumbnail" json:"thumbnail,omitempty"`
+	ID                  int               `bun:"id,pk,autoincrement" json:"id"`
+	CreatedAt           time.Time         `bun:"created_at,notnull" json:"created_at,omitempty"`
+	Status              string            `bun:"status,notnull" json:"status,omitempty"`
+	RejectionReason     string            `bun:"rejection_reason,nullzero" json:"rejection_reason,omitempty"`
+	ReviewedAt          time.Time         `bun:"reviewed_at,nullzero" json:"reviewed_at,omitempty"`
+	ReviewedByEmail     string            `bun:"reviewed_by_email,nullzero" json:"reviewed_by_email,omitempty"`
+	Name                string            `bun:"name,notnull" json:"name"`
+	StartsAt            time.Time         `bun:"starts_at,nullzero" json:"starts_at,omitempty"`
+	EndsAt              time.Time         `bun:"ends_at,nullzero" json:"ends_at,omitempty"`
+	TimeZone            string            `bun:"time_zone,notnull" json:"time_zone,omitempty"`
+	Date                string            `bun:"-" json:"date"`
+	Description         string            `bun:"description" json:"description"`
+	DetailedDescription string            `bun:"detailed_description" json:"detailed_description,omitempty"`
+	CreatorEmail        string            `bun:"creator_email" json:"creator_email,omitempty"`
+	Location            string            `bun:"location" json:"location"`
+	Lat                 float64           `bun:"lat" json:"lat"`
+	Lng                 float64           `bun:"lng" json:"lng"`
+	Category            string            `bun:"category" json:"category,omitempty"`
+	MaxPlayers          int               `bun:"max_players,nullzero" json:"max_players,omitempty"`
+	FacebookLink        string            `bun:"facebook_link" json:"facebook_link,omitempty"`
+	Thumbnail           string            `bun:"thumbnail" json:"thumbnail,omitempty"`
+	ThumbnailVariants   map[string]string `bun:"thumbnail_variants,type:jsonb,nullzero" json:"thumbnail_variants,omitempty"`
+	ThumbnailBlurhash   string            `bun:"thumbnail_blurhash,nullzero" json:"thumbnail_blurhash,omitempty"`
+	ThumbnailColor      string            `bun:"thumbnail_color,nullzero" json:"thumbnail_color,omitempty"`
+	UpdatedAt           time.Time         `bun:"updated_at,nullzero" json:"updated_at,omitempty"`
+	FieldID             int               `bun:"field_id,nullzero" json:"field_id,omitempty"`
+	ClubID              int               `bun:"club_id,nullzero" json:"club_id,omitempty"`
+	Sequence            int               `bun:"sequence,notnull" json:"-"`
+	PreviousVersion     *Event            `bun:"previous_version,type:jsonb" json:"-"`
+}
+
+// ThumbnailURLs lists the thumbnail and all of its variants.
+func (e *Event) ThumbnailURLs() []string {
+	if e.Thumbnail == "" {
+		return nil
+	}
+	urls := []string{e.Thumbnail}
+	for _, u := range e.ThumbnailVariants {
+		if u != e.Thumbnail {
+			urls = append(urls, u)
+		}
+	}
+	return urls
+}
+
+// Published reports whether the public can see the event: it is approved, or
+// an edit of it awaits review.
+func (e *Event) Published() bool {
+	return e.Status == "approved" || e.PreviousVersion != nil
+}
+
+// PublishedVersion is the event as the public sees it: while an edit of an
+// approved event awaits review, the approved snapshot. The snapshot keeps the
+// live row's ID and sequence so clients see the same entry, at least as new
+// as the one they have.
+func (e *Event) PublishedVersion() *Event {
+	if e.PreviousVersion == nil {
+		return e
+	}
+	v := *e.PreviousVersion
+	v.ID = e.ID
+	v.Sequence = e.Sequence
+	v.Date = v.LocalDate()
+	return &v
+}
+
+// EventFieldChange is one edited field of an event awaiting re-review.
+type EventFieldChange struct {
+	Field string `json:"field"`
+	From  any    `json:"from"`
+	To    any    `json:"to"`
+}
+
+// PendingEvent is an event in the admin review queue. Changes is set when
+// the event was approved before and its creator edited it.
+type PendingEvent struct {
+	Event
+	Changes []EventFieldChange `json:"changes,omitempty"`
+}
+
+// NearbyEvent is an Event annotated with its distance from a search point.
+type NearbyEvent struct {
+	Event      `bun:",extend"`
+	DistanceKm float64 `bun:"distance_km,scanonly" json:"distance_km"`
+}
+
+// EventSearchResult is an Event matching a full-text search. NameHighlight
+// and Snippet are HTML-escaped with the matched words wrapped in <mark>.
+type EventSearchResult struct {
+	Event         `bun:",extend"`
+	Rank          float64 `bun:"rank,scanonly" json:"rank"`
+	NameHighlight string  `bun:"name_highlight,scanonly" json:"name_highlight"`
+	Snippet       string  `bun:"snippet,scanonly" json:"snippet"`
+}
+
+// EventRevision is one entry of an event's audit trail.
+type EventRevision struct {
+	ID         int64     `bun:"id,pk,autoincrement" json:"id"`
+	EventID    int       `bun:"event_id,notnull" json:"event_id"`
+	Revision   int       `bun:"revision,notnull" json:"revision"`
+	Action     string    `bun:"action,notnull" json:"action"`
+	ActorEmail string    `bun:"actor_email,nullzero" json:"actor_email,omitempty"`
+	Snapshot   Event     `bun:"snapshot,type:jsonb,notnull" json:"snapshot"`
+	CreatedAt  time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp" json:"created_at"`
+}
+
+// EventMedia is a gallery image or attachment (briefing, map, rules PDF)
+// shown on the event page in Position order. Media added to a published
+// event is pending until a reviewer approves it.
+type EventMedia struct {
+	ID              int       `bun:"id,pk,autoincrement" json:"id"`
+	EventID         int       `bun:"event_id,notnull" json:"event_id"`
+	Status          string    `bun:"status,notnull" json:"status"`
+	Type            string    `bun:"type,notnull" json:"type"`
+	Caption         string    `bun:"caption,notnull" json:"caption"`
+	StorageKey      string    `bun:"storage_key,notnull" json:"-"`
+	URL             string    `bun:"url,notnull" json:"url"`
+	ContentType     string    `bun:"content_type,notnull" json:"content_type"`
+	SizeBytes       int64     `bun:"size_bytes,notnull" json:"size_bytes"`
+	Position        int       `bun:"position,notnull" json:"position"`
+	CreatedAt       time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp" json:"created_at"`
+	ReviewedAt      time.Time `bun:"reviewed_at,nullzero" json:"reviewed_at,omitempty"`
+	ReviewedByEmail string    `bun:"reviewed_by_email,nullzero" json:"reviewed_by_email,omitempty"`
+	EventName       string    `bun:"event_name,scanonly" json:"event_name,omitempty"`
+}
+
+// EventDetail is a single event with its media and the field it is held at.
+type EventDetail struct {
+	Event
+	Media []EventMedia `json:"media"`
+	Field *Field       `json:"field,omitempty"`
+	Club  *Club        `json:"club,omitempty"`
+}
+
+type MediaOrderRequest struct {
+	IDs []int `json:"ids"`
+}
+
+// Upload is a direct-to-storage upload of event media. The client PUTs the
+// file to the URL issued with it and then finalizes it, which checks the file
+// and adds it to the event as MediaID.
+type Upload struct {
+	ID          string    `bun:"id,pk" json:"upload_id"`
+	UserID      int       `bun:"user_id,notnull" json:"-"`
+	EventID     int       `bun:"event_id,notnull" json:"event_id"`
+	StorageKey  string    `bun:"storage_key,notnull" json:"-"`
+	ContentType string    `bun:"content_type,notnull" json:"content_type"`
+	SizeBytes   int64     `bun:"size_bytes,notnull" json:"size_bytes"`
+	Caption     string    `bun:"caption,notnull" json:"caption"`
+	ExpiresAt   time.Time `bun:"expires_at,notnull" json:"expires_at"`
+	CreatedAt   time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp" json:"created_at"`
+	FinalizedAt time.Time `bun:"finalized_at,nullzero" json:"finalized_at,omitempty"`
+	MediaID     int       `bun:"media_id,nullzero" json:"media_id,omitempty"`
+}
+
+type UploadRequest struct {
+	EventID     int    `json:"event_id"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	Caption     string `json:"caption"`
+}
+
+// Field is an airsoft venue. New fields wait for moderation; Photos are
+// image URLs in display order.
+type Field struct {
+	ID              int       `bun:"id,pk,autoincrement" json:"id"`
+	Status          string    `bun:"status,notnull" json:"status"`
+	RejectionReason string    `bun:"rejection_reason,nullzero" json:"rejection_reason,omitempty"`
+	ReviewedAt      time.Time `bun:"reviewed_at,nullzero" json:"reviewed_at,omitempty"`
+	ReviewedByEmail string    `bun:"reviewed_by_email,nullzero" json:"reviewed_by_email,omitempty"`
+	Name            string    `bun:"name,notnull" json:"name"`
+	Lat             float64   `bun:"lat,notnull" json:"lat"`
+	Lng             float64   `bun:"lng,notnull" json:"lng"`
+	Address         string    `bun:"address,notnull" json:"address"`
+	County          string    `bun:"county,notnull" json:"county"`
+	SurfaceType     string    `bun:"surface_type,notnull" json:"surface_type"`
+	OwnerClub       string    `bun:"owner_club,notnull" json:"owner_club"`
+	Photos          []string  `bun:"photos,type:jsonb,notnull" json:"photos"`
+	Rules           string    `bun:"rules,notnull" json:"rules"`
+	ParkingNotes    string    `bun:"parking_notes,notnull" json:"parking_notes"`
+	CreatorEmail    string    `bun:"creator_email,notnull" json:"creator_email,omitempty"`
+	CreatedAt       time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp" json:"created_at"`
+	UpdatedAt       time.Time `bun:"updated_at,nullzero" json:"updated_at,omitempty"`
+}
+
+type FieldRequest struct {
+	Name         string  `json:"name"`
+	Lat          float64 `json:"lat"`
+	Lng          float64 `json:"lng"`
+	Address      string  `json:"address"`
+	County       string  `json:"county"`
+	SurfaceType  string  `json:"surface_type"`
+	OwnerClub    string  `json:"owner_club"`
+	Rules        string  `json:"rules"`
+	ParkingNotes string  `json:"parking_notes"`
+}
+
+// FieldDetail is a field page: the field with its upcoming and recent past
+// approved events.
+type FieldDetail struct {
+	Field
+	UpcomingEvents []Event `json:"upcoming_events"`
+	PastEvents     []Event `json:"past_events"`
+}
+
+// FreelancerClub is the airsoft_club of players without a club.
+const FreelancerClub = "No Club/Freelancer"
+
+// Club is an airsoft club. Its members, with their roles, are in
+// club_members; a member's users.airsoft_club holds the club's name.
+type Club struct {
+	ID          int       `bun:"id,pk,autoincrement" json:"id"`
+	Slug        string    `bun:"slug,notnull" json:"slug"`
+	Name        string    `bun:"name,notnull" json:"name"`
+	Description string    `bun:"description,notnull" json:"description"`
+	Logo        string    `bun:"logo,nullzero" json:"logo,omitempty"`
+	HomeFieldID int       `bun:"home_field_id,nullzero" json:"home_field_id,omitempty"`
+	MemberCount int       `bun:"member_count,scanonly" json:"member_count"`
+	CreatedAt   time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp" json:"created_at"`
+	UpdatedAt   time.Time `bun:"updated_at,nullzero" json:"updated_at,omitempty"`
+}
+
+// ClubMember is a user's membership of a club. Invited and requested rows
+// are pending until the other side accepts.
+type ClubMember struct {
+	ClubID    int       `bun:"club_id,pk" json:"club_id"`
+	UserID    int       `bun:"user_id,pk" json:"user_id"`
+	Role      string    `bun:"role,notnull" json:"role"`
+	Status    string    `bun:"status,notnull" json:"status"`
+	InvitedBy int       `bun:"invited_by,nullzero" json:"invited_by,omitempty"`
+	CreatedAt time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp" json:"created_at"`
+	JoinedAt  time.Time `bun:"joined_at,nullzero" json:"joined_at,omitempty"`
+	Username  string    `bun:"username,scanonly" json:"username,omitempty"`
+	ClubSlug  string    `bun:"club_slug,scanonly" json:"club_slug,omitempty"`
+	ClubName  string    `bun:"club_name,scanonly" json:"club_name,omitempty"`
+}
+
+// ClubRequest creates or edits a club. Slug is only read on create and
+// defaults to one derived from Name.
+type ClubRequest struct {
+	Name        string `json:"name"`
+	Slug        string `json:"slug"`
+	Description string `json:"description"`
+	HomeFieldID int    `json:"home_field_id"`
+}
+
+type ClubInviteRequest struct {
+	Username string `json:"username"`
+}
+
+type ClubMemberRoleRequest struct {
+	Role string `json:"role"`
+}
+
+// ClubDetail is a club's public page. Pending lists invites and join
+// requests and is only filled for the club's owners and officers.
+type ClubDetail struct {
+	Club
+	HomeField      *Field       `json:"home_field,omitempty"`
+	Members        []ClubMember `json:"members"`
+	Pending        []ClubMember `json:"pending,omitempty"`
+	Membership     *ClubMember  `json:"membership,omitempty"`
+	UpcomingEvents []Event      `json:"upcoming_events"`
+	PastEvents     []Event      `json:"past_events"`
+}
+
+// RosterEntry is one registrant as shown to the event's organizer.
+type RosterEntry struct {
+	UserID       int       `json:"user_id"`
+	Username     string    `json:"username"`
+	AirsoftClub  string    `json:"airsoft_club"`
+	Status       string    `json:"status"`
+	FactionID    int       `json:"faction_id,omitempty"`
+	Faction      string    `json:"faction,omitempty"`
+	RegisteredAt time.Time `json:"registered_at"`
+}
+
+// EventFaction is a side players can pick when registering for an event.
+type EventFaction struct {
+	ID          int       `bun:"id,pk,autoincrement" json:"id"`
+	EventID     int       `bun:"event_id,notnull" json:"event_id"`
+	Name        string    `bun:"name,notnull" json:"name"`
+	MaxPlayers  int       `bun:"max_players,nullzero" json:"max_players,omitempty"`
+	Position    int       `bun:"position,notnull" json:"position"`
+	CreatedAt   time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp" json:"created_at"`
+	PlayerCount int       `bun:"player_count,scanonly" json:"player_count"`
+}
+
+type FactionRequest struct {
+	Name       string `json:"name"`
+	MaxPlayers int    `json:"max_players"`
+}
+
+type RegistrationRequest struct {
+	FactionID int `json:"faction_id"`
+}
+
+// FactionAssignment is one faction of a proposed balanced split.
+type FactionAssignment struct {
+	FactionID  int           `json:"faction_id"`
+	Name       string        `json:"name"`
+	MaxPlayers int           `json:"max_players,omitempty"`
+	Players    []RosterEntry `json:"players"`
+}
+
+type FactionBalance struct {
+	Factions   []FactionAssignment `json:"factions"`
+	Unassigned []RosterEntry       `json:"unassigned"`
+	Moves      int                 `json:"moves"`
+}
+
+// RegistrationStatus is the caller's registration for an event. Position is
+// the 1-based waitlist position and only set while waitlisted.
+type RegistrationStatus struct {
+	Status          string `json:"status,omitempty"`
+	Position        int    `json:"position,omitempty"`
+	FactionID       int    `json:"faction_id,omitempty"`
+	RegisteredCount int    `json:"registered_count"`
+	WaitlistCount   int    `json:"waitlist_count"`
+	MaxPlayers      int    `json:"max_players,omitempty"`
+}
+
+// StoredObject is one file written to upload storage. EventID (or FieldID
+// for field photos) stays null until the upload is attached; DeletedAt marks objects removed
+// from storage so restored revisions can tell their files are gone.
+type StoredObject struct {
+	Key         string    `bun:"key,pk" json:"key"`
+	URL         string    `bun:"url,notnull" json:"url"`
+	EventID     int       `bun:"event_id,nullzero" json:"event_id,omitempty"`
+	FieldID     int       `bun:"field_id,nullzero" json:"field_id,omitempty"`
+	ClubID      int       `bun:"club_id,nullzero" json:"club_id,omitempty"`
+	Kind        string    `bun:"kind,notnull" json:"kind"`
+	ContentType string    `bun:"content_type,notnull" json:"content_type"`
+	SizeBytes   int64     `bun:"size_bytes,notnull" json:"size_bytes"`
+	CreatedAt   time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp" json:"created_at"`
+	DeletedAt   time.Time `bun:"deleted_at,nullzero" json:"deleted_at,omitempty"`
 }
 
 type User struct {
-	ID                int       `bun:"id,pk,autoincrement" json:"id"`
-	Email             string    `bun:"email,unique,notnull" json:"email"`
-	Username          string    `bun:"username" json:"username"`
-	AirsoftClub       string    `bun:"airsoft_club" json:"airsoft_club"`
-	IsAdmin           bool      `bun:"is_admin,notnull" json:"is_admin"`
-	IsMaintenanceUser bool      `bun:"is_maintenance_user,notnull" json:"is_maintenance_user"`
-	PasswordHash      string    `bun:"password_hash,notnull" json:"-"`
-	CreatedAt         time.Time `bun:"created_at,notnull" json:"created_at"`
+	ID              int       `bun:"id,pk,autoincrement" json:"id"`
+	Email           string    `bun:"email,unique,notnull" json:"email"`
+	Username        string    `bun:"username" json:"username"`
+	AirsoftClub     string    `bun:"airsoft_club" json:"airsoft_club"`
+	Roles           []string  `bun:"roles,array,scanonly" json:"roles"`
+	PasswordHash    string    `bun:"password_hash,notnull" json:"-"`
+	CalendarToken   string    `bun:"calendar_token,nullzero" json:"-"`
+	EmailVerifiedAt time.Time `bun:"email_verified_at,nullzero" json:"-"`
+	CreatedAt       time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp" json:"created_at"`
 }
 
 // Auth / Profile API DTOs
@@ -40,23 +360,98 @@ type AuthRequest struct {
 }
 
 type RegisterRequest struct {
-	Email       string `json:"email"`
-	Password    string `json:"password"`
-	Username    string `json:"username"`
-	AirsoftClub string `json:"airsoftClub"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	Username string `json:"username"`
 }
 
-type AuthResponse struct {
+type VerifyEmailRequest struct {
 	Token string `json:"token"`
+}
+
+type ForgotPasswordRequest struct {
 	Email string `json:"email"`
 }
 
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+type AuthResponse struct {
+	Token        string `json:"token"`
+	Email        string `json:"email"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	ExpiresIn    int    `json:"expires_in,omitempty"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// Session is one signed-in device. Token hashes never leave the server.
+type Session struct {
+	ID                string    `bun:"id,pk" json:"id"`
+	UserID            int       `bun:"user_id,notnull" json:"-"`
+	RefreshTokenHash  string    `bun:"refresh_token_hash,notnull" json:"-"`
+	PreviousTokenHash string    `bun:"previous_token_hash,nullzero" json:"-"`
+	RotationKey       string    `bun:"rotation_key,nullzero" json:"-"`
+	UserAgent         string    `bun:"user_agent,notnull" json:"user_agent"`
+	IP                string    `bun:"ip,notnull" json:"ip"`
+	CreatedAt         time.Time `bun:"created_at,nullzero,notnull,default:current_timestamp" json:"created_at"`
+	LastUsedAt        time.Time `bun:"last_used_at,nullzero,notnull,default:current_timestamp" json:"last_used_at"`
+	ExpiresAt         time.Time `bun:"expires_at,notnull" json:"expires_at"`
+	RevokedAt         time.Time `bun:"revoked_at,nullzero" json:"-"`
+	Current           bool      `bun:"-" json:"current"`
+}
+
 type UpdateMeRequest struct {
-	Username    string `json:"username"`
-	AirsoftClub string `json:"airsoftClub"`
+	Username string `json:"username"`
+}
+
+// EventQuota is how many events a user may still submit in the current
+// Europe/Zagreb day. Limit and Remaining are omitted when Unlimited is set.
+type EventQuota struct {
+	Tier      string    `json:"tier"`
+	Limit     int       `json:"limit,omitempty"`
+	Used      int       `json:"used"`
+	Remaining int       `json:"remaining"`
+	Unlimited bool      `json:"unlimited"`
+	ResetsAt  time.Time `json:"resets_at"`
 }
 
 // Admin API DTOs
 type AdminRejectRequest struct {
 	Reason string `json:"reason"`
 }
+
+type RoleRequest struct {
+	Role string `json:"role"`
+}
+
+// MaintenanceSettings is the stored maintenance switch. Maintenance is active
+// while Enabled is set and now falls inside the optional StartsAt/EndsAt
+// window. AllowedRoutes entries look like "GET /api/events" or
+// "* /api/events/:id/*" and are matched against the route pattern.
+type MaintenanceSettings struct {
+	ID             int        `bun:"id,pk" json:"-"`
+	Enabled        bool       `bun:"enabled,notnull" json:"enabled"`
+	StartsAt       *time.Time `bun:"starts_at" json:"starts_at"`
+	EndsAt         *time.Time `bun:"ends_at" json:"ends_at"`
+	Message        string     `bun:"message,notnull" json:"message"`
+	ETA            *time.Time `bun:"eta" json:"eta"`
+	AllowReads     bool       `bun:"allow_reads,notnull" json:"allow_reads"`
+	AllowedRoutes  []string   `bun:"allowed_routes,array,notnull" json:"allowed_routes"`
+	UpdatedByEmail string     `bun:"updated_by_email,notnull" json:"updated_by_email"`
+	UpdatedAt      time.Time  `bun:"updated_at,nullzero,notnull,default:current_timestamp" json:"updated_at"`
+}
+
+type MaintenanceRequest struct {
+	Enabled       bool       `json:"enabled"`
+	StartsAt      *time.Time `json:"starts_at"`
+	EndsAt        *time.Time `json:"ends_at"`
+	Message       string     `json:"message"`
+	ETA           *time.Time `json:"eta"`
+	AllowReads    bool       `json:"allow_reads"`
+	AllowedRoutes []string   `json:"allowed_routes"`
+}